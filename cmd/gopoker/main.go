@@ -208,6 +208,27 @@ func runGame(opts options) (err error) {
 
 		server := net.NewServer("0.0.0.0:" + opts.serverPort)
 
+		if opts.handHistoryDir != "" {
+			if err := os.MkdirAll(opts.handHistoryDir, 0o755); err != nil {
+				return err
+			}
+
+			server.HandHistoryDir = opts.handHistoryDir
+		}
+
+		if opts.dataDir != "" {
+			store, err := net.NewFileStore(opts.dataDir)
+			if err != nil {
+				return err
+			}
+
+			server.Store = store
+
+			if err := server.RestoreRooms(); err != nil {
+				return err
+			}
+		}
+
 		if err := server.Run(); err != nil {
 			return err
 		}
@@ -253,6 +274,9 @@ type options struct {
 	GUI         bool
 	isSpectator bool
 	numSeats    uint8
+
+	handHistoryDir string
+	dataDir        string
 }
 
 /*
@@ -297,6 +321,8 @@ func main() {
 	flag.BoolVar(&opts.GUI, "g", false, "run with a GUI")
 	flag.BoolVar(&opts.isSpectator, "S", false, "join table as a spectator")
 	flag.UintVar(&numSeats, "ns", 7, "max number of players allowed at the table")
+	flag.StringVar(&opts.handHistoryDir, "hh", "", "write hand histories to <dir> (as server)")
+	flag.StringVar(&opts.dataDir, "data", "", "keep rooms in <dir> across restarts (as server)")
 	flag.Parse()
 
 	if numSeats > uint(^uint8(0)) {
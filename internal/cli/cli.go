@@ -69,6 +69,8 @@ type CLI struct {
 	actionsBox    *tview.Box
 	actionsForm   *tview.Form
 
+	handID uint64 // the hand that was last over. sent with show hand & rabbit hunt
+
 	chatFlex       *tview.Flex
 	chatTextView   *tview.TextView
 	chatInputField *tview.InputField
@@ -79,6 +81,7 @@ type CLI struct {
 	joinModal,
 	spectateModal,
 	exitModal,
+	runItModal,
 	errorModal *tview.Modal
 
 	focusList *CLIFocusList
@@ -149,17 +152,29 @@ func (cli *CLI) handleButton(btn string) {
 	case "quit":
 		cli.switchToPage("exit")
 		return
+	case "straddle":
+		msg = "yes"
+	case "no straddle":
+		msg = "no"
+	case "show hand", "rabbit hunt":
+		msg = strconv.FormatUint(cli.handID, 10)
 	}
 
 	buttonLabelRequestMap := map[string]net.NetAction{
-		"all-in":     net.NetDataAllIn,
-		"call":       net.NetDataCall,
-		"check":      net.NetDataCheck,
-		"fold":       net.NetDataFold,
-		"raise":      net.NetDataBet,
-		"msg":        net.NetDataChatMsg,
-		"start game": net.NetDataStartGame,
-		"settings":   net.NetDataClientSettings,
+		"all-in":      net.NetDataAllIn,
+		"call":        net.NetDataCall,
+		"check":       net.NetDataCheck,
+		"fold":        net.NetDataFold,
+		"raise":       net.NetDataBet,
+		"msg":         net.NetDataChatMsg,
+		"start game":  net.NetDataStartGame,
+		"settings":    net.NetDataClientSettings,
+		"sit out":     net.NetDataSitOut,
+		"sit in":      net.NetDataSitIn,
+		"show hand":   net.NetDataShowHand,
+		"rabbit hunt": net.NetDataRabbitHunt,
+		"straddle":    net.NetDataStraddle,
+		"no straddle": net.NetDataStraddle,
 	}
 
 	netData := &net.NetData{
@@ -203,11 +218,15 @@ func (cli *CLI) updateInfoList(item string, table *poker.Table) {
 		cli.tableInfoList.SetItemText(1, "# open seats",
 			strconv.FormatUint(uint64(table.GetNumOpenSeats()), 10))
 	case "status":
+		cli.tableInfoList.SetItemText(3, "buy in", table.StartingStackToString())
 		cli.tableInfoList.SetItemText(4, "pot", table.PotToString())
 		cli.tableInfoList.SetItemText(5, "dealer", table.DealerToString())
 		cli.tableInfoList.SetItemText(6, "small blind", table.SmallBlindToString())
 		cli.tableInfoList.SetItemText(7, "big blind", table.BigBlindToString())
-		cli.tableInfoList.SetItemText(8, "status", table.TableStateToString())
+		cli.tableInfoList.SetItemText(8, "ante", table.AnteToString())
+		cli.tableInfoList.SetItemText(9, "game", table.GameToString())
+		cli.tableInfoList.SetItemText(10, "status", table.TableStateToString())
+		cli.tableInfoList.SetItemText(11, "straddle", table.StraddleToString())
 	}
 }
 
@@ -248,6 +267,10 @@ func (cli *CLI) updatePlayer(client *net.Client, table *poker.Table) {
 		textViewSetLine(textView, 2, "current action: "+client.Player.ActionToString())
 		textViewSetLine(textView, 3, "chip count: "+client.Player.ChipCountToString())
 
+		if len(client.Player.UpCards) > 0 && client.Player.Hole != nil {
+			cli.holeView.SetText(cli.holeCards2String(client.Player))
+		}
+
 		textView.ScrollToBeginning() // XXX find out why extra empty lines are being added to yourInfoView
 	} else {
 		if textView == nil { // XXX
@@ -260,6 +283,8 @@ func (cli *CLI) updatePlayer(client *net.Client, table *poker.Table) {
 		if client.Player.Hole != nil {
 			textViewSetLine(textView, 3, "hand: "+client.Player.Hand.RankName()+"\n")
 			textViewSetLine(textView, 4, cli.cards2String(client.Player.Hole.Cards))
+		} else if len(client.Player.UpCards) > 0 {
+			textViewSetLine(textView, 3, "up cards: "+upCards2String(client.Player.UpCards))
 		}
 	}
 }
@@ -287,6 +312,12 @@ func (cli *CLI) makeSpectator(sendPlayerLeft bool) {
 			needRefocus = cli.actionsForm.GetButton(spectateBtnIdx) == cli.app.GetFocus()
 			cli.actionsForm.RemoveButton(spectateBtnIdx)
 		}
+		for _, label := range []string{"sit out", "sit in", "show hand", "straddle", "no straddle"} {
+			if sitOutBtnIdx := cli.actionsForm.GetButtonIndex(label); sitOutBtnIdx != -1 {
+				needRefocus = needRefocus || cli.actionsForm.GetButton(sitOutBtnIdx) == cli.app.GetFocus()
+				cli.actionsForm.RemoveButton(sitOutBtnIdx)
+			}
+		}
 		cli.actionsForm.AddButton("join", func() {
 			cli.handleButton("join")
 		})
@@ -319,6 +350,15 @@ func (cli *CLI) unmakeSpectator() {
 		cli.actionsForm.AddButton("spectate", func() {
 			cli.handleButton("spectate")
 		})
+		cli.actionsForm.AddButton("sit out", func() {
+			cli.handleButton("sit out")
+		})
+		cli.actionsForm.AddButton("show hand", func() {
+			cli.handleButton("show hand")
+		})
+		cli.actionsForm.AddButton("straddle", func() {
+			cli.handleButton("straddle")
+		})
 
 		// need to refocus if the removed button was focused prim
 		if needRefocus {
@@ -329,6 +369,39 @@ func (cli *CLI) unmakeSpectator() {
 	}
 }
 
+// swaps the sit out/sit in button
+func (cli *CLI) setSittingOut(sittingOut bool) {
+	if sittingOut {
+		cli.swapButton("sit out", "sit in")
+	} else {
+		cli.swapButton("sit in", "sit out")
+	}
+}
+
+// swaps the straddle/no straddle button
+func (cli *CLI) setStraddling(straddling bool) {
+	if straddling {
+		cli.swapButton("straddle", "no straddle")
+	} else {
+		cli.swapButton("no straddle", "straddle")
+	}
+}
+
+// replaces the button labeled oldLabel with one labeled newLabel
+func (cli *CLI) swapButton(oldLabel, newLabel string) {
+	if btnIdx := cli.actionsForm.GetButtonIndex(oldLabel); btnIdx != -1 {
+		needRefocus := cli.actionsForm.GetButton(btnIdx) == cli.app.GetFocus()
+		cli.actionsForm.RemoveButton(btnIdx)
+		cli.actionsForm.AddButton(newLabel, func() {
+			cli.handleButton(newLabel)
+		})
+
+		if needRefocus {
+			cli.app.SetFocus(cli.actionsForm)
+		}
+	}
+}
+
 func (cli *CLI) unmakeAdmin(lock bool) {
 	if cli.isTableAdmin {
 		needRefocus := false
@@ -407,6 +480,7 @@ func (cli *CLI) Init() error {
 	cli.joinModal = tview.NewModal()
 	cli.spectateModal = tview.NewModal()
 	cli.exitModal = tview.NewModal()
+	cli.runItModal = tview.NewModal()
 	cli.errorModal = tview.NewModal()
 
 	cli.inputChan = make(chan *net.NetData)
@@ -581,6 +655,9 @@ func (cli *CLI) Init() error {
 		AddButton("fold", func() {
 			cli.handleButton("fold")
 		}).
+		AddButton("rabbit hunt", func() {
+			cli.handleButton("rabbit hunt")
+		}).
 		AddButton("quit", func() {
 			cli.handleButton("quit")
 		}).
@@ -626,6 +703,9 @@ func (cli *CLI) Init() error {
 			cli.yourClient.Name = newName
 			cli.settings.Name = newName
 		}).
+		AddCheckbox("muck losing hands", cli.settings.MuckLosingHands, func(checked bool) {
+			cli.settings.MuckLosingHands = checked
+		}).
 		AddButton("request changes", func() {
 			cli.handleButton("settings")
 			cli.switchToPage("game")
@@ -687,7 +767,10 @@ func (cli *CLI) Init() error {
 		AddItem("dealer", "", '-', nil).
 		AddItem("small blind", "", '-', nil).
 		AddItem("big blind", "", '-', nil).
-		AddItem("status", "", '-', nil)
+		AddItem("ante", "", '-', nil).
+		AddItem("game", "", '-', nil).
+		AddItem("status", "", '-', nil).
+		AddItem("straddle", "", '-', nil)
 	cli.tableInfoList.SetBorder(true).SetTitle("Table Info")
 
 	cli.gameGrid.
@@ -808,6 +891,21 @@ func (cli *CLI) Init() error {
 			}
 		})
 
+	// NOTE: the question comes from the server, see NetDataRunItTwice
+	cli.runItModal.
+		AddButtons([]string{"yes", "no"}).
+		SetDoneFunc(func(_ int, btnLabel string) {
+			switch btnLabel {
+			case "yes", "no":
+				cli.outputChan <- &net.NetData{
+					Request: net.NetDataRunItTwice,
+					Client:  &cli.yourClient,
+					Msg:     btnLabel,
+				}
+				cli.switchToPage("game")
+			}
+		})
+
 	cli.errorModal.
 		AddButtons([]string{"close"}).
 		SetDoneFunc(func(_ int, btnLabel string) {
@@ -834,6 +932,7 @@ func (cli *CLI) Init() error {
 	cli.pages.AddPage("join", cli.joinModal, true, false)
 	cli.pages.AddPage("spectate", cli.spectateModal, true, false)
 	cli.pages.AddPage("exit", cli.exitModal, true, false)
+	cli.pages.AddPage("runIt", cli.runItModal, true, false)
 	cli.pages.AddPage("error", cli.errorModal, true, false)
 	cli.pages.AddPage("settings", cli.settingsFlex, true, false)
 
@@ -844,6 +943,7 @@ func (cli *CLI) Init() error {
 		"join":          cli.joinModal,
 		"spectate":      cli.spectateModal,
 		"exit":          cli.exitModal,
+		"runIt":         cli.runItModal,
 		"error":         cli.errorModal,
 		"errorMustQuit": cli.errorModal,
 		"settings":      cli.settingsFlex,
@@ -900,6 +1000,31 @@ func (cli *CLI) cards2String(cards poker.Cards) string {
 	return txt
 }
 
+// a player's own cards. in stud the up cards everyone can see go below the
+// hole cards.
+func (cli *CLI) holeCards2String(player *poker.Player) string {
+	txt := cli.cards2String(player.Hole.Cards)
+
+	if len(player.UpCards) > 0 {
+		txt += "up:" + cli.cards2String(player.UpCards)
+	}
+
+	return txt
+}
+
+// the up cards of the other players in stud, on one line.
+func upCards2String(cards poker.Cards) string {
+	txt := ""
+	for i, card := range cards {
+		if i > 0 {
+			txt += " "
+		}
+		txt += fmt.Sprintf("[%s]", card.Name)
+	}
+
+	return txt
+}
+
 func cliInputLoop(cli *CLI) {
 	defer cli.app.Stop()
 
@@ -1020,7 +1145,7 @@ func cliInputLoop(cli *CLI) {
 
 				cli.updatePlayer(netData.Client, netData.Table)
 
-				txt := cli.cards2String(netData.Client.Player.Hole.Cards)
+				txt := cli.holeCards2String(netData.Client.Player)
 
 				cli.holeView.SetText(txt)
 			case net.NetDataPlayerAction:
@@ -1066,18 +1191,60 @@ func cliInputLoop(cli *CLI) {
 					if page, _ := cli.pages.GetFrontPage(); page == "game" {
 						cli.app.SetFocus(cli.actionsForm)
 					}
+
+					if limits := netData.RaiseLimits; limits != nil {
+						if limits.CanRaise {
+							cli.updateChat(nil, printer.Sprintf("<server-msg> you can raise to %d - %d chips",
+								limits.MinBet, limits.MaxBet))
+						} else {
+							cli.updateChat(nil, "<server-msg> you can't raise this turn")
+						}
+					}
 				}
 			case net.NetDataUpdatePlayer:
 				cli.updatePlayer(netData.Client, netData.Table)
 			case net.NetDataUpdateTable:
 				cli.updateInfoList("status", netData.Table)
+			case net.NetDataBlindLevel:
+				cli.updateInfoList("status", netData.Table)
+				cli.updateChat(nil, "<server-msg> "+netData.Msg)
+			case net.NetDataSitOut, net.NetDataSitIn:
+				cli.updatePlayer(netData.Client, netData.Table)
+				cli.updateChat(nil, netData.Msg)
+
+				if netData.Client.ID == cli.yourClient.ID {
+					cli.setSittingOut(netData.Response == net.NetDataSitOut)
+				}
+			case net.NetDataStraddle:
+				cli.updateChat(nil, netData.Msg)
+
+				if netData.Client.ID == cli.yourClient.ID {
+					cli.setStraddling(netData.Client.Player.Straddles)
+				}
+			case net.NetDataActionTimer:
+				if netData.Client.ID == cli.yourClient.ID {
+					cli.updateChat(nil, "<server-msg> "+netData.Msg)
+				}
 			case net.NetDataCurHand:
+				hand := netData.Msg
+				if len(netData.Equities) == 1 { // hand strength
+					hand += fmt.Sprintf(" (%.0f%% equity)", 100*netData.Equities[0].Equity)
+				}
+
 				textView := cli.playersTextViewMap[netData.Client.ID]
-				textViewSetLine(textView, 4, "current hand: "+netData.Msg)
+				textViewSetLine(textView, 4, "current hand: "+hand)
 				cli.updatePlayer(netData.Client, netData.Table)
+			case net.NetDataEquity:
+				equities := make([]string, 0, len(netData.Equities))
+				for _, equity := range netData.Equities {
+					equities = append(equities, fmt.Sprintf("%s %.1f%%", equity.Name, 100*equity.Equity))
+				}
+
+				cli.updateChat(nil, "<server-msg> equity: "+strings.Join(equities, ", "))
 			case net.NetDataShowHand:
 				cli.updatePlayer(netData.Client, nil)
 			case net.NetDataRoundOver:
+				cli.handID = netData.Table.HandID
 				cli.updateInfoList("status", netData.Table)
 				cli.errorModal.SetText(netData.Msg)
 				cli.switchToPage("error")
@@ -1099,6 +1266,8 @@ func cliInputLoop(cli *CLI) {
 				}
 				cli.holeView.Clear()
 				cli.updateInfoList("all", netData.Table)
+				cli.setSittingOut(false)
+				cli.setStraddling(false)
 			case net.NetDataEliminated:
 				if netData.Client.ID == cli.yourClient.ID {
 					cli.unmakeAdmin(true)
@@ -1114,10 +1283,26 @@ func cliInputLoop(cli *CLI) {
 
 				cli.updateChat(netData.Client, netData.Msg)
 			case net.NetDataFlop, net.NetDataTurn, net.NetDataRiver:
-				txt := cli.cards2String(netData.Table.Community)
+				txt := ""
+				// NOTE: the boards that were already run when running it more than once
+				for i, board := range netData.Table.Boards {
+					txt += fmt.Sprintf("run #%d:", i+1) + cli.cards2String(board) + "\n"
+				}
+				if len(netData.Table.Boards) > 0 {
+					txt += fmt.Sprintf("run #%d:", len(netData.Table.Boards)+1)
+				}
+				txt += cli.cards2String(netData.Table.Community)
 
 				cli.commView.SetText(txt)
 				cli.updateInfoList("status", netData.Table)
+			case net.NetDataStudStreet:
+				// NOTE: the new cards come with the player updates
+				cli.updateInfoList("status", netData.Table)
+			case net.NetDataRabbitHunt:
+				cli.updateChat(nil, "<server-msg> "+netData.Msg)
+			case net.NetDataRunItTwice:
+				cli.runItModal.SetText(netData.Msg)
+				cli.switchToPage("runIt")
 			case net.NetDataBadRequest, net.NetDataServerMsg:
 				if netData.Msg == "" {
 					if netData.Response == net.NetDataBadRequest {
@@ -101,6 +101,16 @@ func (c *Clients) Register(client *Client, conn *websocket.Conn) {
 	c.byPrivID[client.privID] = client
 }
 
+// RegisterDisconnected adds a client without a connection, e.g. one restored
+// from a Store, to the ID and privID indexes. it joins the conn index when it
+// reconnects, see SetConn.
+func (c *Clients) RegisterDisconnected(client *Client) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.byID[client.ID] = client
+	c.byPrivID[client.privID] = client
+}
+
 func (c *Clients) SetName(client *Client, name string) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
@@ -74,13 +74,24 @@ const (
 	NetDataBadRequest
 
 	NetDataRoomSettings
-) // 43 flags, 21 left
-
-const NetActionNeedsTableBitMask = (NetDataNewConn | NetDataClientExited | NetDataUpdateTable | NetDataDeal)
+	NetDataBlindLevel
+	NetDataActionTimer
+	NetDataSitOut
+	NetDataSitIn
+	NetDataStudStreet
+	NetDataRunItTwice
+	NetDataRabbitHunt
+	NetDataStraddle
+	NetDataEquity
+) // 53 flags, 11 left
+
+const NetActionNeedsTableBitMask = (NetDataNewConn | NetDataClientExited | NetDataUpdateTable | NetDataDeal |
+	NetDataBlindLevel)
 
 const NetActionNeedsPlayerBitMask = (NetDataYourPlayer | NetDataNewPlayer | NetDataCurPlayers |
 	NetDataPlayerLeft | NetDataPlayerAction | NetDataPlayerTurn |
-	NetDataUpdatePlayer | NetDataCurHand | NetDataShowHand | NetDataDeal)
+	NetDataUpdatePlayer | NetDataCurHand | NetDataShowHand | NetDataDeal |
+	NetDataActionTimer | NetDataSitOut | NetDataSitIn | NetDataStraddle)
 
 const NetActionNeedsActionBitMask = (NetDataAllIn | NetDataBet | NetDataCall | NetDataCheck | NetDataFold | NetDataRaise)
 
@@ -96,6 +107,31 @@ type NetData struct {
 
 	room  *Room // used for roomname prefix in logs
 	Table *poker.Table
+
+	RaiseLimits *poker.RaiseLimits // legal bet sizes, sent with NetDataPlayerTurn
+
+	// the player's hand strength with NetDataCurHand, everyone's equity
+	// during an all in runout with NetDataEquity
+	Equities []PlayerEquity
+}
+
+// a player's share of the pot, see poker.Equity
+type PlayerEquity struct {
+	ID     string // client ID
+	Name   string
+	Win    float64
+	Tie    float64
+	Equity float64
+}
+
+func newPlayerEquity(client *Client, equity poker.Equity) PlayerEquity {
+	return PlayerEquity{
+		ID:     client.ID,
+		Name:   client.Player.Name,
+		Win:    equity.Win,
+		Tie:    equity.Tie,
+		Equity: equity.Equity,
+	}
 }
 
 /*func NewNewData() *NetData {
@@ -122,6 +158,8 @@ func (netData *NetData) ClearData(client *Client) {
 	netData.Msg = ""
 	netData.Table = nil
 	netData.RoomSettings = nil
+	netData.RaiseLimits = nil
+	netData.Equities = nil
 
 	// we pass a client with NetData structs that clients send
 	// to ensure that the client member is valid (not modified by the client)
@@ -139,6 +177,9 @@ func (netData *NetData) Clone() *NetData {
 		Msg:          netData.Msg,
 
 		Table: netData.Table,
+
+		RaiseLimits: netData.RaiseLimits,
+		Equities:    netData.Equities,
 	}
 }
 
@@ -224,6 +265,15 @@ func (netData *NetData) NetActionToString() string {
 		NetDataBadRequest: "NetDataBadRequest",
 
 		NetDataRoomSettings: "NetDataRoomSettings",
+		NetDataBlindLevel:   "NetDataBlindLevel",
+		NetDataActionTimer:  "NetDataActionTimer",
+		NetDataSitOut:       "NetDataSitOut",
+		NetDataSitIn:        "NetDataSitIn",
+		NetDataStudStreet:   "NetDataStudStreet",
+		NetDataRunItTwice:   "NetDataRunItTwice",
+		NetDataRabbitHunt:   "NetDataRabbitHunt",
+		NetDataStraddle:     "NetDataStraddle",
+		NetDataEquity:       "NetDataEquity",
 	}
 
 	// XXX remove me
@@ -3,10 +3,14 @@ package net
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/bkazemi/gopoker/internal/playerState"
 	"github.com/bkazemi/gopoker/internal/poker"
@@ -24,14 +28,74 @@ type Room struct {
 
 	creatorToken string
 
+	// the hash of the table password. a restored room only has this, see
+	// checkPassword(). NOTE: guarded by the table lock
+	passwordHash *StoredPassword
+
+	handHistoryDir string // see Server.HandHistoryDir
+	store          Store  // see Server.Store
+
+	// the saved room a restored game resumes from once the table admin
+	// starts it. nil unless the game is paused, see Server.RestoreRooms()
+	paused atomic.Pointer[StoredRoom]
+
+	// finished hands, oldest first. NOTE: guarded by handsMtx, not the room
+	// lock, so they can be served while a hand is played
+	hands    []*roomHand
+	handsMtx sync.Mutex
+
+	// advances timed blind levels. NOTE: guarded by the room lock
+	blindTimer    *time.Timer
+	blindTimerGen uint64
+
+	// the current player's decision clock. NOTE: guarded by the room lock
+	actionTimer       *time.Timer
+	actionTimerGen    uint64
+	actionTimerPlayer *poker.Player
+	timeBankStart     time.Time // zero unless the player is in their time bank
+
+	// deals the next hand once the players had time to show their hands and
+	// rabbit hunt. NOTE: guarded by the room lock
+	nextHandTimer    *time.Timer
+	nextHandTimerGen uint64
+
+	// bumped when the hand moves on, so hand strengths worked out for a street
+	// that's over aren't sent. NOTE: guarded by the room lock
+	handStrengthGen uint64
+
+	// the run it twice prompt the runout waits on. runItAnswered is nil
+	// unless the players are being asked. NOTE: guarded by the room lock
+	runItTimes    int
+	runItAnswered map[*poker.Player]bool
+	runItTimer    *time.Timer
+	runItTimerGen uint64
+
 	isLocked atomic.Bool
 	mtx      sync.Mutex
 }
 
+// the most finished hands a room keeps
+const maxRoomHands = 500
+
+// how long the players have to agree to run the board more than once, see
+// askRunItTimes()
+const runItTimeout = 10 * time.Second
+
+// how long the players have to show their hands and rabbit hunt before the
+// next hand is dealt, see startNextHandTimer()
+const showWindow = 5 * time.Second
+
+type roomHand struct {
+	history *poker.HandHistory
+	holders map[string]string // privID -> name of the player the client played in the hand
+}
+
 func NewRoom(name string, table *poker.Table, creatorToken string) *Room {
 	return &Room{
 		name: name,
 
+		passwordHash: hashPassword(table.Password),
+
 		clients: NewClients(),
 
 		creatorToken: creatorToken,
@@ -187,9 +251,17 @@ func (room *Room) removePlayer(client *Client, exitCause playerExitCause) {
 	room.table.Mtx().Lock()
 	defer func() {
 		log.Debug().Str("room", room.name).Msg("cleanup defer called")
+
+		// NOTE: a player leaving calls off the run it twice prompt
+		runItAsked := room.stopRunItPrompt()
+
 		if reset {
 			if noPlayersLeft {
 				log.Debug().Str("room", room.name).Msg("no players left, resetting")
+				room.stopBlindTimer()
+				room.stopActionTimer()
+				room.stopNextHandTimer()
+				room.paused.Store(nil)
 				room.table.Reset(nil)
 				room.sendReset(nil)
 			} else if exitCause == playerExitEliminated {
@@ -198,18 +270,29 @@ func (room *Room) removePlayer(client *Client, exitCause playerExitCause) {
 				return
 			} else if room.table.State == poker.TableStateNotStarted {
 				log.Debug().Str("room", room.name).Msg("state == TableStateNotStarted")
+			} else if room.isBetweenHands() {
+				// the hand is already settled, the last player left wins
+				log.Debug().Str("room", room.name).Msg("last player left between hands")
+
+				room.stopNextHandTimer()
+				room.table.Winners = []*poker.Player{room.table.ActivePlayers().Head.Player}
+				room.table.State = poker.TableStateGameOver
+				room.gameOver()
 			} else {
 				// XXX: if a player who hasn't bet preflop is
 				//      the last player left he receives the mainpot chips.
 				//      if he's a blind he should also get (only) his blind chips back.
 				log.Debug().Str("room", room.name).Msg("state != (rndovr || gameovr)")
 
-				room.table.FinishRound()
+				room.finishRound()
 				room.table.State = poker.TableStateGameOver
 				room.gameOver()
 			}
-		} else if room.table.State == poker.TableStateDoneBetting ||
-			room.table.State == poker.TableStateRoundOver {
+		} else if runItAsked {
+			room.sendRunItMsg("a player left, running it once")
+			room.runout(1)
+		} else if !room.isBetweenHands() && (room.table.State == poker.TableStateDoneBetting ||
+			room.table.State == poker.TableStateRoundOver) {
 			log.Debug().Str("room", room.name).Msg("defer postPlayerAction")
 			room.postPlayerAction(nil, &NetData{})
 		}
@@ -224,6 +307,11 @@ func (room *Room) removePlayer(client *Client, exitCause playerExitCause) {
 
 		log.Debug().Str("room", room.name).Str("player", playerName).Msg("removing player")
 
+		if player == room.actionTimerPlayer {
+			room.stopActionTimer()
+		}
+
+		table.PlayerLeft(player) // NOTE: before the seat is cleared
 		table.ActivePlayers().RemovePlayer(player)
 		table.CurPlayers().RemovePlayer(player)
 
@@ -241,6 +329,9 @@ func (room *Room) removePlayer(client *Client, exitCause playerExitCause) {
 		if table.BigBlind != nil && player == table.BigBlind.Player {
 			table.BigBlind = nil
 		}
+		if table.Straddler != nil && player == table.Straddler.Player {
+			table.Straddler = nil
+		}
 
 		// wipe cards before building the notification — publicClientInfo
 		// delegates to PublicPlayerInfo which skips redaction during
@@ -261,7 +352,7 @@ func (room *Room) removePlayer(client *Client, exitCause playerExitCause) {
 		// Sever client<->player link and clear the seat.
 		room.clients.ClearPlayer(client)
 		client.Player = nil
-		player.Clear()
+		player.Clear(table.StartingStack)
 
 		if client.ID == room.tableAdminID {
 			if table.ActivePlayers().Len == 0 {
@@ -312,13 +403,15 @@ func (room *Room) cleanupPlayerOnExit(client *Client, exitCause playerExitCause)
 		return
 	}
 
-	if room.table.ActivePlayers().Len > 1 && room.table.IsCurPlayer(player) {
+	// NOTE: nothing to fold once the hand is over
+	if !room.isBetweenHands() && room.table.ActivePlayers().Len > 1 && room.table.IsCurPlayer(player) {
 		player.Action.Action = playerState.Fold
 		room.table.SetNextPlayerTurn()
 		room.sendPlayerTurnToAll()
 	}
 
 	room.removePlayer(client, exitCause)
+	room.save()
 }
 
 // addPlayer binds player to client, inserts into CurPlayers/ActivePlayers,
@@ -346,12 +439,16 @@ func (room *Room) addPlayer(client *Client, player *poker.Player, netData *NetDa
 		room.table.SetCurPlayer(room.table.CurPlayers().Head)
 	}
 
-	if room.table.Dealer == nil {
-		room.table.Dealer = room.table.ActivePlayers().Head
-	} else if room.table.SmallBlind == nil {
-		room.table.SmallBlind = room.table.Dealer.Next()
-	} else if room.table.BigBlind == nil {
-		room.table.BigBlind = room.table.SmallBlind.Next()
+	// NOTE: once a game starts the dealer & blinds move by seat, see
+	//       Table.rotatePlayers()
+	if room.table.State == poker.TableStateNotStarted {
+		if room.table.Dealer == nil {
+			room.table.Dealer = room.table.ActivePlayers().Head
+		} else if room.table.SmallBlind == nil {
+			room.table.SmallBlind = room.table.Dealer.Next()
+		} else if room.table.BigBlind == nil {
+			room.table.BigBlind = room.table.SmallBlind.Next()
+		}
 	}
 
 	netData.Client = room.publicClientInfo(client)
@@ -362,6 +459,8 @@ func (room *Room) addPlayer(client *Client, player *poker.Player, netData *NetDa
 	netData.Client = client
 	netData.Response = NetDataYourPlayer
 	netData.Send()
+
+	room.save()
 }
 
 func (room *Room) sendPlayerTurn(client *Client) {
@@ -377,10 +476,13 @@ func (room *Room) sendPlayerTurn(client *Client) {
 			room.name, curPlayer.Name))
 	}
 
+	raiseLimits := room.table.RaiseLimits(curPlayer)
+
 	netData := &NetData{
-		room:     room,
-		Client:   curPlayerClient,
-		Response: NetDataPlayerTurn,
+		room:        room,
+		Client:      curPlayerClient,
+		Response:    NetDataPlayerTurn,
+		RaiseLimits: &raiseLimits,
 	}
 
 	//netData.Client.Player.Action.Action = NetDataPlayerTurn
@@ -407,9 +509,12 @@ func (room *Room) sendPlayerTurnToAll() {
 			room.name, curPlayer.Name))
 	}
 
+	raiseLimits := room.table.RaiseLimits(curPlayer)
+
 	netData := &NetData{
-		Client:   room.publicClientInfo(curPlayerClient),
-		Response: NetDataPlayerTurn,
+		Client:      room.publicClientInfo(curPlayerClient),
+		Response:    NetDataPlayerTurn,
+		RaiseLimits: &raiseLimits,
 	}
 
 	//netData.Client.Player.Action.Action = NetDataPlayerTurn
@@ -421,6 +526,189 @@ func (room *Room) sendPlayerTurnToAll() {
 	} else {
 		room.sendPlayerHead(nil, true)
 	}
+
+	room.startActionTimer()
+}
+
+// starts the decision clock for the current player, unless it's already
+// running for them. players that are sitting out act right away.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) startActionTimer() {
+	curPlayer := room.table.CurPlayer()
+	if curPlayer == nil || !room.table.InBettingState() {
+		room.stopActionTimer()
+		return
+	}
+
+	player := curPlayer.Player
+	if room.actionTimer != nil && room.actionTimerPlayer == player {
+		return
+	}
+
+	room.stopActionTimer()
+
+	if player.IsSittingOut {
+		room.armActionTimer(player, 0)
+		return
+	}
+
+	room.armActionTimer(player, room.table.TurnTime)
+	room.sendActionTimer(player, room.table.TurnTime, false)
+}
+
+// NOTE: caller must hold the room lock
+func (room *Room) armActionTimer(player *poker.Player, timeLeft time.Duration) {
+	gen := room.actionTimerGen
+
+	room.actionTimerPlayer = player
+	room.actionTimer = time.AfterFunc(timeLeft, func() {
+		room.Lock()
+		defer room.Unlock()
+
+		// timer was stopped or replaced while we waited for the lock
+		if gen != room.actionTimerGen {
+			return
+		}
+
+		room.actionTimeout(player)
+	})
+}
+
+// stops the decision clock. any time the player spent in their time bank
+// is taken out of it.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) stopActionTimer() {
+	room.actionTimerGen++
+
+	if room.actionTimer != nil {
+		room.actionTimer.Stop()
+		room.actionTimer = nil
+	}
+
+	if player := room.actionTimerPlayer; player != nil && !room.timeBankStart.IsZero() {
+		player.TimeBank -= min(time.Since(room.timeBankStart), player.TimeBank)
+	}
+
+	room.actionTimerPlayer, room.timeBankStart = nil, time.Time{}
+}
+
+// called when the player's decision clock runs out. the player moves on to
+// their time bank if they have one left, otherwise we check or fold for them.
+func (room *Room) actionTimeout(player *poker.Player) {
+	room.actionTimer = nil
+
+	if !room.table.IsCurPlayer(player) || !room.table.InBettingState() {
+		room.stopActionTimer()
+		return
+	}
+
+	if room.timeBankStart.IsZero() && player.TimeBank > 0 && !player.IsSittingOut {
+		room.timeBankStart = time.Now()
+		room.armActionTimer(player, player.TimeBank)
+		room.sendActionTimer(player, player.TimeBank, true)
+		return
+	}
+
+	room.stopActionTimer()
+
+	client := room.getPlayerClient(player)
+	if client == nil {
+		log.Warn().Str("room", room.name).Str("player", player.Name).Msg("timed out player not found in any maps")
+		return
+	}
+
+	wasSittingOut := player.IsSittingOut
+
+	action, err := room.table.PlayerTimeout(player)
+	if err != nil {
+		log.Error().Err(err).Str("room", room.name).Str("player", player.Name).Msg("couldn't act for timed out player")
+		return
+	}
+
+	if !wasSittingOut {
+		msg := fmt.Sprintf("<server-msg> %s ran out of time and ", player.Name)
+		if action == playerState.Check {
+			msg += "checked"
+		} else {
+			msg += "folded"
+		}
+
+		room.sendResponseToAll(&NetData{
+			Response: NetDataChatMsg,
+			Msg:      msg,
+		}, nil)
+
+		if player.IsSittingOut {
+			room.sendSitOut(client)
+		}
+	}
+
+	room.postPlayerAction(client, &NetData{})
+}
+
+// tell everyone that a player sat out or sat back in
+func (room *Room) sendSitOut(client *Client) {
+	netData := &NetData{
+		Client:   room.publicClientInfo(client),
+		Response: NetDataSitIn,
+		Table:    room.Table(),
+		Msg:      fmt.Sprintf("<server-msg> %s is back", client.Player.Name),
+	}
+
+	if client.Player.IsSittingOut {
+		netData.Response = NetDataSitOut
+		netData.Msg = fmt.Sprintf("<server-msg> %s is sitting out", client.Player.Name)
+	}
+
+	room.sendResponseToAll(netData, nil)
+}
+
+// moves players that sat out for too long to the spectators. at least two
+// players are always kept so that the game can go on.
+func (room *Room) removeSatOutPlayers() {
+	for _, player := range room.table.GetSatOutPlayers() {
+		if room.table.NumPlayers <= 2 {
+			return
+		}
+
+		client := room.getPlayerClient(player)
+		if client == nil {
+			log.Warn().Str("room", room.name).Str("player", player.Name).Msg("sat out player not found in any maps")
+			continue
+		}
+
+		log.Info().Str("room", room.name).Str("player", player.Name).Msg("sat out for too long, removing")
+
+		room.sendResponseToAll(&NetData{
+			Response: NetDataChatMsg,
+			Msg:      fmt.Sprintf("<server-msg> %s sat out for too long and is now spectating", player.Name),
+		}, nil)
+
+		room.removePlayer(client, playerExitToSpectator)
+	}
+}
+
+// tell everyone how long the current player has left to act
+func (room *Room) sendActionTimer(player *poker.Player, timeLeft time.Duration, timeBank bool) {
+	client := room.getPlayerClient(player)
+	if client == nil {
+		return
+	}
+
+	netData := &NetData{
+		Client:   room.publicClientInfo(client),
+		Response: NetDataActionTimer,
+		Msg:      fmt.Sprintf("%s has %s to act", player.Name, timeLeft.Round(time.Second)),
+	}
+
+	if timeBank {
+		netData.Msg = fmt.Sprintf("%s is using their time bank (%s left)", player.Name,
+			timeLeft.Round(time.Second))
+	}
+
+	room.sendResponseToAll(netData, nil)
 }
 
 // XXX: this response gets sent too often
@@ -508,22 +796,60 @@ func (room *Room) sendDeals() {
 	}
 }
 
-func (room *Room) sendHands() {
+// awards the pots. the table checks that no chips went missing during the
+// hand, if any did it's a bug so everyone at the table is told about it.
+func (room *Room) finishRound() {
+	if err := room.table.FinishRound(); err != nil {
+		log.Error().Err(err).Str("room", room.name).Msg("FinishRound")
+
+		room.sendResponseToAll(&NetData{
+			Response: NetDataServerMsg,
+			Msg:      err.Error(),
+		}, nil)
+	}
+}
+
+// sends the hands of players to everyone in the order they're given
+func (room *Room) sendHands(players []*poker.Player) {
 	netData := &NetData{
 		room:     room,
 		Response: NetDataShowHand,
 		Table:    room.Table(),
 	}
 
-	for _, player := range room.table.GetNonFoldedPlayers() {
+	for _, player := range players {
 		client := room.getPlayerClient(player)
-		//assert(client != nil, "Room.sendHands(): player not in playerMap")
+		if client == nil {
+			log.Warn().Str("room", room.name).Str("player", player.Name).Msg("player has no client")
+			continue
+		}
 		netData.Client = room.publicClientInfo(client)
 
 		room.sendResponseToAll(netData, client)
 	}
 }
 
+// whether player chose to muck their losing hands, see ClientSettings
+func (room *Room) mucksLosingHands(player *poker.Player) bool {
+	client := room.getPlayerClient(player)
+
+	return client != nil && client.Settings != nil && client.Settings.MuckLosingHands
+}
+
+// shows the hands at showdown. players that muck are announced in the chat
+func (room *Room) showdown() {
+	shown, mucked := room.table.Showdown(room.mucksLosingHands)
+
+	room.sendHands(shown)
+
+	for _, player := range mucked {
+		room.sendResponseToAll(&NetData{
+			Response: NetDataChatMsg,
+			Msg:      "<server-msg> " + player.Name + " mucks",
+		}, nil)
+	}
+}
+
 // NOTE: hand is currently computed on client side
 func (room *Room) sendCurHands() {
 	netData := &NetData{
@@ -532,15 +858,108 @@ func (room *Room) sendCurHands() {
 		Table:    room.Table(),
 	}
 
+	room.handStrengthGen++
+
+	strengths := make([]handStrength, 0, room.table.NumPlayers)
 	for _, client := range room.clients.Players() {
 		poker.AssembleBestHand(true, room.table, client.Player)
 
 		netData.Client = client
 		netData.Msg = client.Player.PreHand().RankName()
 		netData.Send()
+
+		if query, err := room.table.HandStrengthQuery(client.Player); err == nil {
+			strengths = append(strengths, handStrength{
+				client:   client,
+				player:   client.Player,
+				rankName: netData.Msg,
+				query:    query,
+			})
+		}
+	}
+
+	if len(strengths) != 0 {
+		go room.sendHandStrengths(netData.Table, strengths, room.handStrengthGen)
+	}
+}
+
+// a player's hand strength, worked out after sendCurHands() lets go of the
+// room lock
+type handStrength struct {
+	client   *Client
+	player   *poker.Player
+	rankName string
+	query    *poker.HandStrengthQuery
+	equity   poker.Equity
+}
+
+// works out each player's hand strength without the room lock and sends it
+// with their current hand, unless the hand has moved on since gen
+func (room *Room) sendHandStrengths(table *poker.Table, strengths []handStrength, gen uint64) {
+	ready := strengths[:0]
+	for _, strength := range strengths {
+		equity, err := strength.query.Calculate()
+		if err != nil {
+			log.Debug().Str("room", room.name).Err(err).Msg("no hand strength")
+			continue
+		}
+
+		strength.equity = equity
+		ready = append(ready, strength)
+	}
+
+	room.Lock()
+	defer room.Unlock()
+
+	if gen != room.handStrengthGen {
+		return
+	}
+
+	netData := &NetData{
+		room:     room,
+		Response: NetDataCurHand,
+		Table:    table,
+	}
+
+	for _, strength := range ready {
+		// NOTE: the client may have left their seat in the meantime
+		if strength.client.Player != strength.player {
+			continue
+		}
+
+		netData.Client = strength.client
+		netData.Msg = strength.rankName
+		netData.Equities = []PlayerEquity{newPlayerEquity(strength.client, strength.equity)}
+		netData.Send()
 	}
 }
 
+// sends everyone the equity of each player still in the hand. used during an
+// all in runout once the hands are tabled.
+func (room *Room) sendAllInEquity() {
+	equities, err := room.table.AllInEquity()
+	if err != nil {
+		log.Debug().Str("room", room.name).Err(err).Msg("no all in equity")
+		return
+	}
+
+	netData := &NetData{
+		room:     room,
+		Response: NetDataEquity,
+	}
+
+	for _, player := range room.table.GetNonFoldedPlayers() {
+		client := room.getPlayerClient(player)
+		if client == nil {
+			continue
+		}
+
+		netData.Equities = append(netData.Equities, newPlayerEquity(client, equities[player]))
+	}
+
+	room.sendResponseToAll(netData, nil)
+}
+
 func (room *Room) sendActivePlayers(client *Client) {
 	if client == nil {
 		log.Warn().Str("room", room.name).Msg("client is nil")
@@ -671,6 +1090,14 @@ func (room *Room) getRoomSettings() *RoomSettings {
 		NumSeats: room.table.NumSeats,
 		Lock:     room.table.Lock,
 		Password: room.table.Password,
+		Blinds:   room.table.BaseBlinds(),
+
+		StartingStack: room.table.StartingStack,
+
+		TurnTime: room.table.TurnTime,
+		TimeBank: room.table.TimeBank,
+
+		Betting: room.table.BettingToString(),
 	}
 }
 
@@ -696,7 +1123,21 @@ func (room *Room) makeAdmin(client *Client) {
 }
 
 func (room *Room) newRound() {
+	var prevLevel int
+	if room.table.BlindSchedule != nil {
+		prevLevel = room.table.BlindSchedule.Level
+	}
+
 	room.table.NewRound()
+	room.removeSatOutPlayers()
+	room.sendDeckCommitment()
+
+	// a hand-based level may have ended
+	if room.table.BlindSchedule != nil && room.table.BlindSchedule.Level != prevLevel {
+		room.sendBlindLevel()
+		room.startBlindTimer()
+	}
+
 	room.table.NextTableAction()
 	room.checkBlindsAutoAllIn()
 	room.sendDeals()
@@ -722,8 +1163,12 @@ func (room *Room) roundOver() {
 		return
 	}
 
-	room.table.FinishRound()
-	room.sendHands()
+	room.handStrengthGen++ // see sendHandStrengths()
+
+	room.finishRound()
+	room.showdown()
+	room.sendDeckSeed()
+	room.recordHand()
 
 	netData := &NetData{
 		Response: NetDataRoundOver,
@@ -739,22 +1184,234 @@ func (room *Room) roundOver() {
 	room.sendAllPlayerInfo(nil, false, true)
 
 	room.removeEliminatedPlayers()
+	room.save()
 
-	if room.table.State == poker.TableStateGameOver {
-		time.Sleep(5 * time.Second)
-		room.gameOver()
+	room.startNextHandTimer()
+}
+
+// gives the players showWindow to show their hands and rabbit hunt, then
+// deals the next hand or ends the game. the room lock isn't held meanwhile.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) startNextHandTimer() {
+	room.stopNextHandTimer()
+
+	gen := room.nextHandTimerGen
+	room.nextHandTimer = time.AfterFunc(showWindow, func() {
+		room.Lock()
+		defer room.Unlock()
+
+		// timer was stopped or replaced while we waited for the lock
+		if gen != room.nextHandTimerGen {
+			return
+		}
+		room.nextHandTimer = nil
+
+		if room.table.State == poker.TableStateGameOver {
+			room.gameOver()
+
+			return
+		}
 
+		room.newRound()
+	})
+}
+
+// NOTE: caller must hold the room lock
+func (room *Room) stopNextHandTimer() {
+	room.nextHandTimerGen++
+
+	if room.nextHandTimer != nil {
+		room.nextHandTimer.Stop()
+		room.nextHandTimer = nil
+	}
+}
+
+// reports whether the hand is over and the next one is waiting on
+// startNextHandTimer().
+//
+// NOTE: caller must hold the room lock
+func (room *Room) isBetweenHands() bool {
+	return room.nextHandTimer != nil
+}
+
+// keeps the history of the hand that just finished, see Room.Hands()
+func (room *Room) recordHand() {
+	history := room.table.HandHistory()
+	if history == nil {
 		return
 	}
 
-	time.Sleep(5 * time.Second)
-	room.newRound()
+	history.Table = room.name
+	if seed, err := room.table.DeckSeed(); err == nil {
+		history.Seed = seed
+	}
+
+	hand := &roomHand{
+		history: cloneHistory(history),
+		holders: make(map[string]string),
+	}
+	for _, client := range room.clients.All() {
+		if client.Player != nil {
+			hand.holders[client.privID] = client.Player.Name
+		}
+	}
+
+	room.handsMtx.Lock()
+	room.hands = append(room.hands, hand)
+	if len(room.hands) > maxRoomHands {
+		room.hands = slices.Delete(room.hands, 0, len(room.hands)-maxRoomHands)
+	}
+	room.handsMtx.Unlock()
+
+	room.saveHand(hand)
+	room.writeHandHistory(history)
+}
+
+// brings the kept copy of the hand with handID up to date once a hand was
+// shown after it was recorded, see poker.Table.ShowHand()
+//
+// NOTE: caller must hold the room lock
+func (room *Room) recordShow(handID uint64) {
+	history := room.table.HandHistory()
+	if history == nil || history.ID != handID {
+		return
+	}
+
+	room.handsMtx.Lock()
+	i := slices.IndexFunc(room.hands, func(hand *roomHand) bool {
+		return hand.history.ID == handID
+	})
+	if i == -1 {
+		room.handsMtx.Unlock()
+		return
+	}
+	hand := &roomHand{
+		history: cloneHistory(history),
+		holders: room.hands[i].holders,
+	}
+	room.hands[i] = hand
+	room.handsMtx.Unlock()
+
+	room.updateSavedHand(hand)
+}
+
+// a copy of history that later shows on the table don't change, so it can
+// be read under handsMtx alone
+func cloneHistory(history *poker.HandHistory) *poker.HandHistory {
+	clone := *history
+	clone.Shows = slices.Clone(history.Shows)
+
+	return &clone
+}
+
+// Hands returns the room's finished hands, oldest first, as seen by the
+// client with privID: their own hole cards are left in. hole cards nobody
+// showed are left out for anyone else.
+func (room *Room) Hands(privID string) []*poker.HandHistory {
+	room.handsMtx.Lock()
+	defer room.handsMtx.Unlock()
+
+	hands := make([]*poker.HandHistory, 0, len(room.hands))
+	for _, hand := range room.hands {
+		hands = append(hands, hand.visibleTo(privID))
+	}
+
+	return hands
+}
+
+// Hand returns the finished hand with id as seen by the client with privID,
+// see Hands(). returns nil if the room doesn't have the hand.
+func (room *Room) Hand(id uint64, privID string) *poker.HandHistory {
+	room.handsMtx.Lock()
+	defer room.handsMtx.Unlock()
+
+	for _, hand := range room.hands {
+		if hand.history.ID == id {
+			return hand.visibleTo(privID)
+		}
+	}
+
+	return nil
+}
+
+// NOTE: a client that wasn't in the hand sees it like a spectator
+func (hand *roomHand) visibleTo(privID string) *poker.HandHistory {
+	return hand.history.VisibleTo(hand.holders[privID])
+}
+
+// appends a finished hand to the room's hand history file. hole cards are
+// only written if they were shown.
+func (room *Room) writeHandHistory(history *poker.HandHistory) {
+	if room.handHistoryDir == "" {
+		return
+	}
+
+	path := filepath.Join(room.handHistoryDir, handHistoryFileName(room.name))
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Error().Err(err).Str("room", room.name).Msg("couldn't open hand history file")
+		return
+	}
+	defer file.Close()
+
+	if err := history.WritePokerStars(file, ""); err != nil {
+		log.Error().Err(err).Str("room", room.name).Msg("couldn't write hand history")
+	}
+}
+
+// room names can be anything, so anything but letters, digits, '-' and '_'
+// is replaced to keep the file inside the hand history directory
+func handHistoryFileName(roomName string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, roomName) + ".txt"
+}
+
+// in commit-reveal mode, publishes the hash of the deck order before the
+// cards are dealt
+func (room *Room) sendDeckCommitment() {
+	if !room.table.CommitReveal {
+		return
+	}
+
+	room.sendResponseToAll(&NetData{
+		Response: NetDataChatMsg,
+		Msg:      "<server-msg> deck commitment (sha256): " + room.table.DeckCommitment(),
+	}, nil)
+}
+
+// in commit-reveal mode, reveals the seed the deck was shuffled with once the
+// hand is over
+func (room *Room) sendDeckSeed() {
+	if !room.table.CommitReveal {
+		return
+	}
+
+	seed, err := room.table.DeckSeed()
+	if err != nil {
+		log.Error().Err(err).Str("room", room.name).Msg("DeckSeed")
+		return
+	}
+
+	room.sendResponseToAll(&NetData{
+		Response: NetDataChatMsg,
+		Msg: fmt.Sprintf("<server-msg> deck seed: %s (commitment %s)",
+			seed, room.table.DeckCommitment()),
+	}, nil)
 }
 
 func (room *Room) gameOver() {
 	log.Info().Str("room", room.name).Str("winner", room.table.Winners[0].Name).Msg("game over")
 	winner := room.table.Winners[0]
 
+	defer room.save()
+
 	netData := &NetData{
 		Response: NetDataServerMsg,
 		Msg:      "game over, " + winner.Name + " wins",
@@ -762,6 +1419,10 @@ func (room *Room) gameOver() {
 
 	room.sendResponseToAll(netData, nil)
 
+	room.stopBlindTimer()
+	room.stopActionTimer()
+	room.stopNextHandTimer()
+	room.stopRunItPrompt()
 	room.table.Reset(winner) // make a new game while keeping winner connected
 
 	winnerClient := room.getPlayerClient(winner)
@@ -779,39 +1440,90 @@ func (room *Room) gameOver() {
 	room.sendReset(winnerClient)
 }
 
-// XXX: need to add to sidepots
-func (room *Room) checkBlindsAutoAllIn() {
-	if room.table.SmallBlind.Player.Action.Action == playerState.AllIn {
-		log.Debug().
-			Str("room", room.name).
-			Str("player", room.table.SmallBlind.Player.Name).
-			Msg("smallblind forced all-in")
+// (re)arms the blind timer for the current blind level. does nothing if the
+// table has no blind schedule or the current level isn't timed.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) startBlindTimer() {
+	room.stopBlindTimer()
 
-		if room.table.CurPlayer().Player.Name == room.table.SmallBlind.Player.Name {
-			// because blind is curPlayer SetNextPlayerTurn() will remove the blind
-			// from the list for us
-			room.table.SetNextPlayerTurn()
-		} else {
-			room.table.CurPlayers().RemovePlayer(room.table.SmallBlind.Player)
+	if room.table.BlindSchedule == nil {
+		return
+	}
+
+	timeLeft, ok := room.table.BlindSchedule.TimeLeft(time.Now())
+	if !ok {
+		return
+	}
+
+	gen := room.blindTimerGen
+	room.blindTimer = time.AfterFunc(timeLeft, func() {
+		room.Lock()
+		defer room.Unlock()
+
+		// timer was stopped or replaced while we waited for the lock
+		if gen != room.blindTimerGen || room.table.State == poker.TableStateNotStarted {
+			return
 		}
 
-		room.sendPlayerActionToAll(room.table.SmallBlind.Player, nil)
+		if room.table.AdvanceBlindLevel(time.Now()) {
+			room.sendBlindLevel()
+		}
+
+		room.startBlindTimer()
+	})
+}
+
+// NOTE: caller must hold the room lock
+func (room *Room) stopBlindTimer() {
+	room.blindTimerGen++
+
+	if room.blindTimer != nil {
+		room.blindTimer.Stop()
+		room.blindTimer = nil
+	}
+}
+
+// tell everyone the current blind level. new blinds are used starting with
+// the next hand.
+func (room *Room) sendBlindLevel() {
+	if room.table.BlindSchedule == nil {
+		return
+	}
+
+	netData := &NetData{
+		Response: NetDataBlindLevel,
+		Table:    room.Table(),
+		Msg:      "blinds " + room.table.BlindSchedule.String(),
 	}
-	if room.table.BigBlind.Player.Action.Action == playerState.AllIn {
+
+	room.sendResponseToAll(netData, nil)
+}
+
+// takes the players that went all in posting their blinds or antes out of the
+// betting. their chips are already in the ante pots, see Table.postBlinds()
+func (room *Room) checkBlindsAutoAllIn() {
+	// NOTE: with antes any player in the hand can be forced all in, not just
+	//       the blinds
+	for _, player := range room.table.CurPlayers().ToPlayerArray() {
+		if player.Action.Action != playerState.AllIn {
+			continue
+		}
+
 		log.Debug().
 			Str("room", room.name).
-			Str("player", room.table.BigBlind.Player.Name).
-			Msg("bigblind forced all-in")
+			Str("player", player.Name).
+			Msg("forced all-in posting blinds")
 
-		if room.table.CurPlayer().Player.Name == room.table.BigBlind.Player.Name {
-			// because blind is curPlayer SetNextPlayerTurn() will remove the blind
+		if room.table.CurPlayer().Player.Name == player.Name {
+			// because player is curPlayer SetNextPlayerTurn() will remove them
 			// from the list for us
 			room.table.SetNextPlayerTurn()
 		} else {
-			room.table.CurPlayers().RemovePlayer(room.table.BigBlind.Player)
+			room.table.CurPlayers().RemovePlayer(player)
 		}
 
-		room.sendPlayerActionToAll(room.table.BigBlind.Player, nil)
+		room.sendPlayerActionToAll(player, nil)
 	}
 }
 
@@ -827,34 +1539,20 @@ func (room *Room) postBetting(player *poker.Player, netData *NetData, client *Cl
 	if room.table.BettingIsImpossible() {
 		log.Debug().Msg("no more betting possible this round")
 
-		tmpReq := netData.Request
-		tmpClient := netData.Client
-
-		netData.Request = 0
-		netData.Table = room.table
-		netData.Client = nil
-
-		tableState := room.table.State
-		room.table.State = poker.TableStateShowHands
-		room.sendHands()
-		room.table.State = tableState
+		room.table.TableHands()
+		room.sendHands(room.table.GetNonFoldedPlayers())
 
-		for room.table.State != poker.TableStateRoundOver {
-			room.table.NextCommunityAction()
-			netData.Response = commState2NetDataResponse(room)
-
-			room.sendResponseToAll(netData, nil)
-			room.sendCurHands()
-
-			time.Sleep(2500 * time.Millisecond)
+		// NOTE: the runout goes on from answerRunItTwice() if the players
+		//       are asked to run it more than once
+		if !room.askRunItTimes() {
+			room.runout(1)
 		}
 
-		netData.Request = tmpReq
-		netData.Client = tmpClient
-	} else {
-		room.table.NextCommunityAction()
+		return
 	}
 
+	room.table.NextCommunityAction()
+
 	if room.table.State == poker.TableStateRoundOver {
 		room.roundOver()
 
@@ -878,7 +1576,8 @@ func (room *Room) postBetting(player *poker.Player, netData *NetData, client *Cl
 			player.Action.Clear()
 		}
 
-		room.sendAllPlayerInfo(nil, true, true)
+		// NOTE: in stud the all in players get new up cards too
+		room.sendAllPlayerInfo(nil, netData.Response != NetDataStudStreet, true)
 		room.table.ReorderPlayers()
 		room.sendPlayerTurnToAll()
 		room.sendPlayerHead(nil, true)
@@ -886,6 +1585,156 @@ func (room *Room) postBetting(player *poker.Player, netData *NetData, client *Cl
 	}
 }
 
+// deals the rest of the board times times once no more betting is possible,
+// then ends the hand.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) runout(times int) {
+	netData := &NetData{Table: room.table}
+
+	room.table.StartRunout(times)
+	room.sendAllInEquity()
+
+	for {
+		for room.table.State != poker.TableStateRoundOver {
+			room.table.NextCommunityAction()
+			netData.Response = commState2NetDataResponse(room)
+
+			room.sendResponseToAll(netData, nil)
+			if netData.Response == NetDataStudStreet {
+				room.sendAllPlayerInfo(nil, false, true) // new up cards
+			}
+			room.sendCurHands()
+			if room.table.State != poker.TableStateRoundOver {
+				room.sendAllInEquity()
+			}
+
+			time.Sleep(2500 * time.Millisecond)
+		}
+
+		if !room.table.NextRun() {
+			break
+		}
+
+		// NOTE: the finished boards are in Table.Boards
+		room.sendTable(nil)
+	}
+
+	room.roundOver()
+}
+
+// asks the players left in the hand whether to run the rest of the board more
+// than once. returns false if there's no one to ask and the board is run once.
+// otherwise the runout waits on the answers, see answerRunItTwice(): everyone
+// has to agree within runItTimeout, or the board is run once.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) askRunItTimes() bool {
+	times := room.table.RunItTimesOffered()
+	if times < 2 {
+		return false
+	}
+
+	players := room.table.GetNonFoldedPlayers()
+	for _, player := range players {
+		if client := room.getPlayerClient(player); client == nil || client.isDisconnected ||
+			player.IsSittingOut {
+			log.Debug().Str("room", room.name).Str("player", player.Name).Msg("can't ask player to run it twice")
+			return false
+		}
+	}
+
+	room.runItTimes = times
+	room.runItAnswered = make(map[*poker.Player]bool)
+	for _, player := range players {
+		room.runItAnswered[player] = false
+
+		netData := &NetData{
+			Client:   room.getPlayerClient(player),
+			Response: NetDataRunItTwice,
+			Msg:      fmt.Sprintf("run it %d times?", times),
+		}
+		netData.Send()
+	}
+
+	gen := room.runItTimerGen
+	room.runItTimer = time.AfterFunc(runItTimeout, func() {
+		room.Lock()
+		defer room.Unlock()
+
+		// prompt was answered or called off while we waited for the lock
+		if gen != room.runItTimerGen {
+			return
+		}
+
+		room.sendRunItMsg("not everyone agreed to run it more than once")
+		room.stopRunItPrompt()
+		room.runout(1)
+	})
+
+	return true
+}
+
+// records a player's answer to the run it twice prompt. the board is run once
+// as soon as someone doesn't agree, and runItTimes times once everyone did.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) answerRunItTwice(player *poker.Player, agree bool) error {
+	answered, asked := room.runItAnswered[player]
+	if !asked {
+		return errors.New("you weren't asked to run it twice")
+	} else if answered {
+		return errors.New("you already answered")
+	}
+
+	room.runItAnswered[player] = true
+
+	if !agree {
+		room.sendRunItMsg(player.Name + " wants to run it once")
+		room.stopRunItPrompt()
+		room.runout(1)
+
+		return nil
+	}
+
+	for _, answered := range room.runItAnswered {
+		if !answered {
+			return nil
+		}
+	}
+
+	times := room.runItTimes
+	room.sendRunItMsg(fmt.Sprintf("running it %d times", times))
+	room.stopRunItPrompt()
+	room.runout(times)
+
+	return nil
+}
+
+// calls off the run it twice prompt. returns false if no one was being asked.
+//
+// NOTE: caller must hold the room lock
+func (room *Room) stopRunItPrompt() bool {
+	room.runItTimerGen++
+
+	if room.runItTimer != nil {
+		room.runItTimer.Stop()
+		room.runItTimer = nil
+	}
+
+	asked := room.runItAnswered != nil
+	room.runItTimes, room.runItAnswered = 0, nil
+
+	return asked
+}
+
+func (room *Room) sendRunItMsg(msg string) {
+	room.sendResponseToAll(&NetData{
+		Response: NetDataChatMsg,
+		Msg:      "<server-msg> " + msg,
+	}, nil)
+}
+
 func (room *Room) postPlayerAction(client *Client, netData *NetData) {
 	var player *poker.Player
 	if client != nil {
@@ -897,7 +1746,8 @@ func (room *Room) postPlayerAction(client *Client, netData *NetData) {
 	} else if room.table.State == poker.TableStateRoundOver {
 		// all other players folded before all comm cards were dealt
 		// TODO: check for this state in a better fashion
-		room.table.FinishRound()
+		room.finishRound()
+		room.recordHand()
 		log.Debug().
 			Int("numWinners", len(room.table.Winners)).
 			Str("winner", room.table.Winners[0].Name).
@@ -914,13 +1764,9 @@ func (room *Room) postPlayerAction(client *Client, netData *NetData) {
 
 		room.removeEliminatedPlayers()
 
-		if room.table.State == poker.TableStateGameOver {
-			room.gameOver()
-
-			return
-		}
-
-		room.newRound()
+		// NOTE: gives the winner time to show their hand and others time to
+		//       rabbit hunt, like roundOver()
+		room.startNextHandTimer()
 	} else {
 		room.sendPlayerActionToAll(player, client)
 		time.Sleep(2 * time.Second)
@@ -940,11 +1786,21 @@ type RoomSettings struct {
 	NumSeats uint8
 	Lock     poker.TableLock
 	Password string
+	Blinds   poker.Blinds // NOTE: zero value means unchanged
+
+	StartingStack poker.Chips // NOTE: 0 means unchanged
+
+	TurnTime time.Duration // NOTE: 0 means the turn clock is unchanged
+	TimeBank time.Duration
+
+	Betting string // no-limit, pot-limit or fixed-limit. NOTE: "" means unchanged
 }
 
 type ClientSettings struct {
 	IsSpectator bool
 
+	MuckLosingHands bool // don't show losing hands at showdown when allowed to
+
 	Name     string
 	Password string
 
@@ -1076,6 +1932,11 @@ func commState2NetDataResponse(room *Room) NetAction {
 		poker.TableStateFlop:  NetDataFlop,
 		poker.TableStateTurn:  NetDataTurn,
 		poker.TableStateRiver: NetDataRiver,
+
+		poker.TableStateFourthStreet:  NetDataStudStreet,
+		poker.TableStateFifthStreet:   NetDataStudStreet,
+		poker.TableStateSixthStreet:   NetDataStudStreet,
+		poker.TableStateSeventhStreet: NetDataStudStreet,
 	}
 
 	if netDataResponse, ok := commStateNetDataMap[room.table.CommState]; ok {
@@ -1108,7 +1969,12 @@ func (room *Room) applyRoomSettings(settings *RoomSettings) {
 
 	room.table.Mtx().Lock()
 	room.table.Lock = settings.Lock
-	room.table.Password = settings.Password
+	// NOTE: a restored room's password isn't known, so it's shown empty in
+	//       the room settings. leaving it empty keeps it
+	if settings.Password != room.table.Password {
+		room.table.Password = settings.Password
+		room.passwordHash = hashPassword(settings.Password)
+	}
 	room.table.Mtx().Unlock()
 }
 
@@ -1149,6 +2015,28 @@ func (room *Room) newClient(conn *websocket.Conn, connType string, clientSetting
 	return client
 }
 
+// reports whether password opens the room, true if the room has no password
+func (room *Room) checkPassword(password string) bool {
+	room.table.Mtx().Lock()
+	defer room.table.Mtx().Unlock()
+
+	switch {
+	case room.table.Password != "":
+		return password == room.table.Password
+	case room.passwordHash != nil:
+		return room.passwordHash.matches(password)
+	default:
+		return true
+	}
+}
+
+func (room *Room) hasPassword() bool {
+	room.table.Mtx().Lock()
+	defer room.table.Mtx().Unlock()
+
+	return room.table.Password != "" || room.passwordHash != nil
+}
+
 func (room *Room) isTableLocked() bool {
 	room.table.Mtx().Lock()
 	defer room.table.Mtx().Unlock()
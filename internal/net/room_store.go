@@ -0,0 +1,438 @@
+package net
+
+import (
+	"crypto/pbkdf2"
+	crypto_rand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+	"github.com/bkazemi/gopoker/internal/poker"
+	"github.com/rs/zerolog/log"
+)
+
+// how long the players of a restored room have to reclaim their seat
+const restoreReconnectWait = 10 * time.Minute
+
+// the settings the room would be created with today, see RoomOpts.newTable().
+// NOTE: the password is left out, see StoredRoom.Password
+func (room *Room) roomOpts() RoomOpts {
+	table := room.table
+	blinds := table.BaseBlinds()
+
+	roomOpts := RoomOpts{
+		RoomName:   room.name,
+		NumSeats:   table.NumSeats,
+		Lock:       table.Lock,
+		SmallBlind: blinds.SmallBlind,
+		BigBlind:   blinds.BigBlind,
+		Ante:       blinds.Ante,
+
+		StartingStack: table.StartingStack,
+
+		TurnTime: uint64(table.TurnTime / time.Second),
+		TimeBank: uint64(table.TimeBank / time.Second),
+
+		SitOutOrbits: table.SitOutOrbits,
+		RunItTimes:   table.RunItTimes,
+		Straddle:     table.AllowStraddle,
+		CommitReveal: table.CommitReveal,
+
+		Game:    table.Game,
+		Betting: table.BettingToString(),
+	}
+
+	if table.BlindSchedule != nil {
+		for _, level := range table.BlindSchedule.Levels {
+			roomOpts.BlindSchedule = append(roomOpts.BlindSchedule, BlindLevelOpts{
+				SmallBlind: level.SmallBlind,
+				BigBlind:   level.BigBlind,
+				Ante:       level.Ante,
+				Minutes:    level.Minutes,
+				Hands:      level.Hands,
+			})
+		}
+	}
+
+	return roomOpts
+}
+
+// a snapshot of the room for its Store. players that are in the hand being
+// played are saved with the chips they started it with.
+func (room *Room) stored() *StoredRoom {
+	table := room.table
+
+	stored := &StoredRoom{
+		Opts:         room.roomOpts(),
+		Password:     room.passwordHash,
+		CreatorToken: room.creatorToken,
+		AdminID:      room.tableAdminID,
+	}
+
+	history := table.HandHistory()
+
+	if paused := room.paused.Load(); paused != nil {
+		stored.Started = true
+		stored.Button = paused.Button
+		stored.BlindLevel = paused.BlindLevel
+		stored.BlindLevelHands = paused.BlindLevelHands
+		stored.BlindLevelTime = paused.BlindLevelTime
+	} else if table.State != poker.TableStateNotStarted {
+		stored.Started = true
+		if history != nil {
+			stored.Button = history.Button
+		}
+
+		if schedule := table.BlindSchedule; schedule != nil {
+			stored.BlindLevel = schedule.Level
+			stored.BlindLevelHands = schedule.LevelHands
+			stored.BlindLevelTime = time.Since(schedule.LevelStart)
+		}
+	}
+
+	// NOTE: a hand isn't over until it's recorded, see roundOver()
+	startChips := make(map[string]poker.Chips)
+	if history != nil && stored.Started && !room.isRecorded(history.ID) {
+		for _, seat := range history.Seats {
+			startChips[seat.Name] = seat.Chips
+		}
+	}
+
+	for _, client := range room.clients.Players() {
+		player := client.Player
+		if player == nil {
+			continue
+		}
+
+		chips, inHand := startChips[player.Name]
+		if !inHand {
+			chips = player.ChipCount
+		}
+
+		seat := StoredSeat{
+			Seat:      uint8(player.TablePos + 1),
+			Name:      player.Name,
+			Chips:     chips,
+			TimeBank:  player.TimeBank,
+			Straddles: player.Straddles,
+
+			ClientID: client.ID,
+			PrivID:   client.privID,
+		}
+		if client.Settings != nil {
+			seat.MuckLosingHands = client.Settings.MuckLosingHands
+		}
+
+		stored.Seats = append(stored.Seats, seat)
+	}
+
+	slices.SortFunc(stored.Seats, func(a, b StoredSeat) int {
+		return int(a.Seat) - int(b.Seat)
+	})
+
+	return stored
+}
+
+func (room *Room) isRecorded(handID uint64) bool {
+	room.handsMtx.Lock()
+	defer room.handsMtx.Unlock()
+
+	return len(room.hands) != 0 && room.hands[len(room.hands)-1].history.ID == handID
+}
+
+// PBKDF2 rounds a room password is hashed with
+const passwordHashIterations = 100_000
+
+// a new salted hash of password, nil if it's empty
+func hashPassword(password string) *StoredPassword {
+	if password == "" {
+		return nil
+	}
+
+	salt := make([]byte, 16)
+	crypto_rand.Read(salt)
+
+	return &StoredPassword{
+		Salt: salt,
+		Hash: passwordKey(password, salt),
+	}
+}
+
+func (stored *StoredPassword) matches(password string) bool {
+	return subtle.ConstantTimeCompare(passwordKey(password, stored.Salt), stored.Hash) == 1
+}
+
+func passwordKey(password string, salt []byte) []byte {
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordHashIterations, sha256.Size)
+	if err != nil {
+		panic(err) // NOTE: only fails in FIPS 140-only mode, for short salts and keys
+	}
+
+	return key
+}
+
+// saves the room to its Store, if it has one
+func (room *Room) save() {
+	if room.store == nil {
+		return
+	}
+
+	if err := room.store.SaveRoom(room.stored()); err != nil && !errors.Is(err, ErrStoreClosed) {
+		log.Error().Err(err).Str("room", room.name).Msg("couldn't save room")
+	}
+}
+
+func (room *Room) saveHand(hand *roomHand) {
+	if room.store == nil {
+		return
+	}
+
+	err := room.store.AddHand(room.name, &StoredHand{
+		History: hand.history,
+		Holders: hand.holders,
+	})
+	if err != nil && !errors.Is(err, ErrStoreClosed) {
+		log.Error().Err(err).Str("room", room.name).Msg("couldn't save hand")
+	}
+}
+
+// replaces the saved copy of a hand, see recordShow()
+func (room *Room) updateSavedHand(hand *roomHand) {
+	if room.store == nil {
+		return
+	}
+
+	err := room.store.UpdateHand(room.name, &StoredHand{
+		History: hand.history,
+		Holders: hand.holders,
+	})
+	if err != nil && !errors.Is(err, ErrStoreClosed) {
+		log.Error().Err(err).Str("room", room.name).Msg("couldn't update saved hand")
+	}
+}
+
+// builds a room from a snapshot. the seated players are given disconnected
+// clients that keep their IDs, so they can reclaim their seat with
+// Server.handleReconnect().
+func restoreRoom(stored *StoredRoom) (*Room, error) {
+	roomOpts := stored.Opts
+	if roomOpts.NumSeats < 2 || roomOpts.NumSeats > 7 {
+		return nil, fmt.Errorf("invalid number of seats %d", roomOpts.NumSeats)
+	}
+
+	table, err := roomOpts.newTable()
+	if err != nil {
+		return nil, err
+	}
+
+	room := NewRoom(roomOpts.RoomName, table, stored.CreatorToken)
+	if room.passwordHash == nil {
+		room.passwordHash = stored.Password
+	}
+
+	seats := slices.Clone(stored.Seats)
+	slices.SortFunc(seats, func(a, b StoredSeat) int {
+		return int(a.Seat) - int(b.Seat)
+	})
+
+	for _, seat := range seats {
+		if seat.Seat == 0 {
+			return nil, fmt.Errorf("%s has no seat", seat.Name)
+		}
+
+		player := table.GetSeat(seat.Seat)
+		if player == nil {
+			return nil, fmt.Errorf("seat %d is taken or doesn't exist", seat.Seat)
+		}
+
+		player.SetName(seat.Name)
+		player.ChipCount = seat.Chips
+		player.TimeBank = seat.TimeBank
+		player.Straddles = seat.Straddles && table.AllowStraddle
+
+		client := &Client{
+			ID:     seat.ClientID,
+			Name:   player.Name,
+			Player: player,
+			Settings: &ClientSettings{
+				MuckLosingHands: seat.MuckLosingHands,
+				Name:            player.Name,
+				SeatPos:         seat.Seat,
+			},
+
+			privID:         seat.PrivID,
+			mtx:            &sync.Mutex{},
+			isDisconnected: true,
+		}
+		room.clients.RegisterDisconnected(client)
+		room.clients.SetName(client, client.Name)
+		room.clients.SetPlayer(client, player)
+
+		player.Action.Action = playerState.FirstAction
+		table.CurPlayers().AddPlayer(player)
+		table.ActivePlayers().AddPlayer(player)
+
+		// NOTE: counts the client while it has a seat to reclaim, like a
+		//       client that dropped its connection
+		table.NumConnected++
+
+		if client.ID == stored.AdminID {
+			room.tableAdminID = client.ID
+		}
+	}
+
+	if table.ActivePlayers().Len == 0 {
+		return room, nil
+	}
+
+	table.SetCurPlayer(table.CurPlayers().Head)
+
+	if room.tableAdminID == "" && room.creatorToken == "" {
+		room.tableAdminID = room.clients.Players()[0].ID
+	}
+
+	// the button moves on to the next player like it would have for the next
+	// hand. the positions left unset are filled in as players join, see
+	// addPlayer()
+	dealer := table.ActivePlayers().Head
+	for i, node := 0, dealer; i < table.ActivePlayers().Len; i, node = i+1, node.Next() {
+		if node.Player.TablePos+1 > stored.Button {
+			dealer = node
+			break
+		}
+	}
+
+	table.Dealer = dealer
+	if table.ActivePlayers().Len > 1 {
+		table.SmallBlind = table.Dealer.Next()
+	}
+	if table.ActivePlayers().Len > 2 {
+		table.BigBlind = table.SmallBlind.Next()
+	}
+
+	if !stored.Started {
+		return room, nil
+	}
+
+	if schedule := table.BlindSchedule; schedule != nil {
+		schedule.Level = min(max(stored.BlindLevel, 0), len(schedule.Levels)-1)
+		schedule.LevelHands = stored.BlindLevelHands
+		table.Blinds = schedule.Current().Blinds
+	}
+
+	room.paused.Store(stored)
+
+	return room, nil
+}
+
+// starts a paused game again where it was left off, see RestoreRooms()
+//
+// NOTE: caller must hold the room lock
+func (room *Room) resume(now time.Time) {
+	paused := room.paused.Swap(nil)
+	if paused == nil {
+		return
+	}
+
+	if schedule := room.table.BlindSchedule; schedule != nil {
+		schedule.LevelStart = now.Add(-paused.BlindLevelTime)
+	}
+
+	log.Info().Str("room", room.name).Msg("resuming paused game")
+}
+
+// RestoreRooms brings back the rooms saved in the server's Store. the seated
+// players come back disconnected and have 10 minutes to reclaim their seat
+// with their private ID, like after a dropped connection. a game that was
+// going is paused until the table admin starts it again, and the hand that
+// was being played when the server stopped is called off: everyone in it
+// gets back the chips they started it with.
+func (server *Server) RestoreRooms() error {
+	if server.Store == nil {
+		return nil
+	}
+
+	storedRooms, err := server.Store.Rooms()
+	if err != nil {
+		return err
+	}
+
+	server.mtx.Lock()
+	defer server.mtx.Unlock()
+
+	for _, stored := range storedRooms {
+		name := stored.Opts.RoomName
+		if server.hasRoom(name) || invalidRoomNames[name] {
+			log.Warn().Str("room", name).Msg("can't restore room, skipping")
+			continue
+		}
+
+		room, err := restoreRoom(stored)
+		if err != nil {
+			log.Error().Err(err).Str("room", name).Msg("couldn't restore room")
+			continue
+		}
+
+		hands, err := server.Store.Hands(name)
+		if err != nil {
+			log.Error().Err(err).Str("room", name).Msg("couldn't restore hands")
+		}
+		for _, hand := range hands {
+			if hand.History != nil {
+				room.hands = append(room.hands, &roomHand{
+					history: hand.History,
+					holders: hand.Holders,
+				})
+			}
+		}
+
+		room.handHistoryDir = server.HandHistoryDir
+		room.store = server.Store
+		server.rooms[name] = room
+
+		for _, client := range room.clients.Players() {
+			client.mtx.Lock()
+			server.awaitReconnect(room, client, restoreReconnectWait)
+			client.mtx.Unlock()
+		}
+
+		log.Info().
+			Str("room", name).
+			Int("players", len(stored.Seats)).
+			Int("hands", len(room.hands)).
+			Bool("paused", room.paused.Load() != nil).
+			Msg("restored room")
+	}
+
+	return nil
+}
+
+// saves every room and closes the Store, so rooms that keep going while the
+// server shuts down don't overwrite their last save
+func (server *Server) closeStore() {
+	if server.Store == nil {
+		return
+	}
+
+	server.mtx.Lock()
+	rooms := slices.Collect(maps.Values(server.rooms))
+	server.mtx.Unlock()
+
+	// NOTE: a room in the middle of a hand is saved once it lets go of the
+	//       room lock
+	for _, room := range rooms {
+		room.Lock()
+		room.save()
+		room.Unlock()
+	}
+
+	if err := server.Store.Close(); err != nil {
+		log.Error().Err(err).Msg("couldn't close store")
+	}
+}
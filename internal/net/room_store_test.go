@@ -0,0 +1,179 @@
+package net
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/poker"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// a room is saved mid-hand and after the hand, then brought back by a new
+// server like after a restart
+func TestRestoreRooms(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zerolog.New(io.Discard)
+	t.Cleanup(func() { log.Logger = prev })
+
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	roomOpts := RoomOpts{
+		RoomName:      "test",
+		NumSeats:      4,
+		Password:      "secret",
+		StartingStack: 1000,
+		BlindSchedule: []BlindLevelOpts{
+			{SmallBlind: 5, BigBlind: 10, Hands: 10},
+			{SmallBlind: 10, BigBlind: 20},
+		},
+	}
+	table, err := roomOpts.newTable()
+	if err != nil {
+		t.Fatalf("newTable: %v", err)
+	}
+
+	room := NewRoom("test", table, "")
+	room.store = store
+
+	clients := make([]*Client, 0, 3)
+	for i, seat := range []uint8{1, 2, 4} {
+		client := NewClient(nil)
+		client.ID = "c" + string(rune('0'+i))
+		client.privID = client.ID + "-priv"
+		client.conn = new(websocket.Conn)
+		room.clients.Register(client, client.conn)
+		// see TestConcurrentCleanupPlayerOnExitDoesNotPanic
+		client.conn = nil
+		client.isDisconnected = true
+
+		player := table.GetSeat(seat)
+		player.SetName("p" + string(rune('0'+i)))
+		client.SetName(player.Name)
+
+		room.addPlayer(client, player, &NetData{}, true)
+		clients = append(clients, client)
+	}
+	room.makeAdmin(clients[1])
+
+	table.StartBlindSchedule(time.Now())
+	table.AdvanceBlindLevel(time.Now())
+	table.NextTableAction()
+
+	button := table.HandHistory().Button
+	smallBlind := table.SmallBlind.Player.Name
+
+	restore := func() *Room {
+		t.Helper()
+
+		store, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileStore: %v", err)
+		}
+
+		server := NewServer("127.0.0.1:0")
+		server.Store = store
+		if err := server.RestoreRooms(); err != nil {
+			t.Fatalf("RestoreRooms: %v", err)
+		}
+
+		restored := server.rooms["test"]
+		if restored == nil {
+			t.Fatalf("room wasn't restored")
+		}
+		t.Cleanup(func() {
+			for _, client := range restored.clients.Players() {
+				client.reconnectTimer.Stop()
+			}
+		})
+
+		return restored
+	}
+
+	chips := func(room *Room) map[string]poker.Chips {
+		chips := make(map[string]poker.Chips)
+		for _, client := range clients {
+			restored, ok := room.clients.ByPrivID(client.privID)
+			if !ok || restored.ID != client.ID || !restored.isDisconnected {
+				t.Fatalf("%s can't reconnect", client.ID)
+			}
+			chips[restored.Player.Name] = restored.Player.ChipCount
+		}
+
+		return chips
+	}
+
+	// the hand is called off
+	room.save()
+	restored := restore()
+
+	for name, chips := range chips(restored) {
+		if chips != 1000 {
+			t.Errorf("mid-hand: %s has %d chips, want 1000", name, chips)
+		}
+	}
+
+	rtable := restored.table
+	if restored.paused.Load() == nil || rtable.State != poker.TableStateNotStarted {
+		t.Errorf("restored game isn't paused")
+	}
+	// only the password's hash is saved
+	data, err := os.ReadFile(filepath.Join(store.roomDir("test"), "room.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Errorf("room.json has the password")
+	}
+	if !restored.hasPassword() || !restored.checkPassword("secret") || restored.checkPassword("") {
+		t.Errorf("restored room doesn't check its password")
+	}
+
+	if restored.tableAdminID != "c1" {
+		t.Errorf("table admin is %q, want c1", restored.tableAdminID)
+	}
+	if rtable.NumPlayers != 3 || rtable.NumConnected != 3 {
+		t.Errorf("%d players and %d clients, want 3", rtable.NumPlayers, rtable.NumConnected)
+	}
+	if rtable.Blinds.BigBlind != 20 || rtable.BlindSchedule.Level != 1 {
+		t.Errorf("blinds are %s at level %d, want 10/20 at level 2", rtable.Blinds, rtable.BlindSchedule.Level+1)
+	}
+	// the button moves on to the next seated player
+	nextButton := map[uint]uint{1: 2, 2: 4, 4: 1}[button]
+	if dealer := rtable.Dealer.Player.TablePos + 1; dealer != nextButton {
+		t.Errorf("the button is on seat %d, want %d", dealer, nextButton)
+	}
+
+	restored.resume(time.Now())
+	if restored.paused.Load() != nil {
+		t.Errorf("resumed game is paused")
+	}
+	rtable.NextTableAction()
+	if history := rtable.HandHistory(); history == nil || len(history.Seats) != 3 ||
+		history.Button != nextButton || history.Blinds.BigBlind != 20 {
+		t.Errorf("resumed game dealt %+v", history)
+	}
+
+	// once the hand is recorded the chips it moved are kept. NOTE: the new
+	// level's blinds start with the next hand
+	room.recordHand()
+	room.save()
+	restored = restore()
+
+	if got := chips(restored)[smallBlind]; got != 1000-5 {
+		t.Errorf("small blind has %d chips, want 995", got)
+	}
+	if hands := restored.Hands(""); len(hands) != 1 || hands[0].ID != table.HandHistory().ID {
+		t.Errorf("restored %d hands, want the recorded hand", len(hands))
+	}
+}
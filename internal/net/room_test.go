@@ -4,6 +4,7 @@ import (
 	"io"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/bkazemi/gopoker/internal/playerState"
 	"github.com/bkazemi/gopoker/internal/poker"
@@ -36,8 +37,8 @@ func TestConcurrentCleanupPlayerOnExitDoesNotPanic(t *testing.T) {
 	const iterations = 2000
 
 	for i := 0; i < iterations; i++ {
-		deck := poker.NewDeck()
-		table, err := poker.NewTable(deck, 2, poker.TableLockNone, "", []bool{false, false})
+		deck := poker.NewDeck(nil)
+		table, err := poker.NewTable(deck, poker.Holdem{}, 2, poker.TableLockNone, "", []bool{false, false})
 		if err != nil {
 			t.Fatalf("NewTable: %v", err)
 		}
@@ -89,3 +90,77 @@ func TestConcurrentCleanupPlayerOnExitDoesNotPanic(t *testing.T) {
 		wg.Wait()
 	}
 }
+
+// a player that runs out of time moves on to their time bank, and is only
+// acted for once the time bank is used up too
+func TestActionTimeout(t *testing.T) {
+	prev := log.Logger
+	log.Logger = zerolog.New(io.Discard)
+	t.Cleanup(func() { log.Logger = prev })
+
+	table, err := poker.NewTable(poker.NewDeck(nil), poker.Holdem{}, 3, poker.TableLockNone, "", make([]bool, 3))
+	if err != nil {
+		t.Fatalf("NewTable: %v", err)
+	}
+	if err := table.SetTurnClock(poker.MinTurnTime, time.Minute); err != nil {
+		t.Fatalf("SetTurnClock: %v", err)
+	}
+
+	room := NewRoom("test", table, "")
+
+	for i := range 3 {
+		client := NewClient(nil)
+		client.ID = "c" + string(rune('0'+i))
+		client.conn = new(websocket.Conn)
+		room.clients.Register(client, client.conn)
+		// see TestConcurrentCleanupPlayerOnExitDoesNotPanic
+		client.conn = nil
+		client.isDisconnected = true
+
+		player := table.GetOpenSeat()
+		player.SetName("p" + string(rune('0'+i)))
+		client.SetName(player.Name)
+
+		room.addPlayer(client, player, &NetData{}, true)
+	}
+
+	table.NextTableAction()
+
+	room.Lock()
+	defer room.Unlock()
+
+	// NOTE: the timers are fired by hand
+	expire := func(player *poker.Player) {
+		t.Helper()
+
+		if room.actionTimer == nil || room.actionTimerPlayer != player {
+			t.Fatalf("%s has no decision clock running", player.Name)
+		}
+		room.actionTimer.Stop()
+		room.actionTimeout(player)
+	}
+
+	player := table.CurPlayer().Player
+	room.startActionTimer()
+	expire(player)
+
+	if room.timeBankStart.IsZero() || !table.IsCurPlayer(player) {
+		t.Fatalf("%s didn't move on to their time bank", player.Name)
+	}
+
+	// the time spent in the time bank is taken out of it
+	room.timeBankStart = time.Now().Add(-10 * time.Second)
+	room.stopActionTimer()
+	if player.TimeBank > 50*time.Second || player.TimeBank < 49*time.Second {
+		t.Fatalf("%s has %s left in their time bank, want 50s", player.Name, player.TimeBank)
+	}
+
+	// NOTE: the player faces the big blind, so they fold
+	player.TimeBank = 0
+	room.startActionTimer()
+	expire(player)
+
+	if table.IsCurPlayer(player) || player.Action.Action != playerState.Fold {
+		t.Errorf("timed out player %s", player.ActionToString())
+	}
+}
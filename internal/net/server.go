@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -23,6 +24,14 @@ type Server struct {
 	MaxChatMsgLen  int32
 	MaxRoomNameLen int32
 
+	// rooms append each finished hand to <HandHistoryDir>/<room name>.txt in
+	// the PokerStars text format. no hand histories are written if empty.
+	HandHistoryDir string
+
+	// keeps rooms across restarts, see RestoreRooms(). rooms aren't kept if
+	// nil
+	Store Store
+
 	router *mux.Router
 
 	http     *http.Server
@@ -117,9 +126,12 @@ func NewServer(addr string) *Server {
 	router.HandleFunc("/roomCount", server.roomCount).Methods("GET")
 	router.HandleFunc("/rooms", server.listRooms).Methods("GET")
 	router.HandleFunc("/room/{roomName}", handleRoom)
+	router.HandleFunc("/room/{roomName}/hands", server.listHands).Methods("GET")
+	router.HandleFunc("/room/{roomName}/hands/{id}", server.getHand).Methods("GET")
 	router.HandleFunc("/room/{roomName}/{connType}", handleClient).Methods("GET")
 
-	signal.Notify(server.sigChan, os.Interrupt)
+	// NOTE: SIGTERM is what most process managers send on a redeploy
+	signal.Notify(server.sigChan, os.Interrupt, syscall.SIGTERM)
 
 	return server
 }
@@ -250,6 +262,8 @@ func (server *Server) Run() error {
 
 		log.Info().Str("signal", sig.String()).Msg("received signal")
 
+		server.closeStore()
+
 		// TODO: ignore irrelevant signals
 		for _, room := range server.rooms {
 			room.sendResponseToAll(&NetData{Response: NetDataServerClosed}, nil)
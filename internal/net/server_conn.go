@@ -169,12 +169,24 @@ func (server *Server) handleDisconnect(room *Room, conn *websocket.Conn, cleanEx
 	room.clients.RemoveConn(conn)
 	closeConn(conn)
 
+	// the 0 min gofunc is kinda dumb, but they're cheap and it eliminates
+	// some redundancy
+	server.awaitReconnect(room, client, minsToWait)
+
+	client.mtx.Unlock()
+}
+
+// gives a disconnected client until wait is up to reconnect. after that
+// their seat is given up, or the room is removed if they were the last
+// client.
+//
+// NOTE: caller must hold client.mtx
+func (server *Server) awaitReconnect(room *Room, client *Client, wait time.Duration) {
 	if client.reconnectTimer != nil {
 		client.reconnectTimer.Stop()
 	}
-	// the 0 min gofunc is kinda dumb, but they're cheap and it eliminates
-	// some redundancy
-	client.reconnectTimer = time.AfterFunc(minsToWait, func() {
+
+	client.reconnectTimer = time.AfterFunc(wait, func() {
 		client.mtx.Lock()
 		defer client.mtx.Unlock()
 
@@ -195,8 +207,6 @@ func (server *Server) handleDisconnect(room *Room, conn *websocket.Conn, cleanEx
 		room.cleanupPlayerOnExit(client, playerExitDisconnect)
 		room.removeClient(client)
 	})
-
-	client.mtx.Unlock()
 }
 
 func (server *Server) handleNewConn(
@@ -295,6 +305,7 @@ func (server *Server) handleNewConn(
 			Msg("used creatorToken, removing token")
 
 		room.creatorToken = ""
+		room.save()
 
 		return
 	}
@@ -305,8 +316,7 @@ func (server *Server) handleNewConn(
 		return
 	}
 
-	if room.table.Password != "" &&
-		netData.Client.Settings.Password != room.table.Password {
+	if !room.checkPassword(netData.Client.Settings.Password) {
 		room.sendBadAuth(conn, connType)
 
 		return
@@ -450,7 +460,8 @@ func (server *Server) handleReconnect(
 			client.reconnectTimer.Stop()
 		}
 
-		client.conn = conn
+		// NOTE: a restored client gets its connType here
+		client.conn, client.connType = conn, connType
 		room.clients.SetConn(conn, client)
 		client.isDisconnected = false
 		client.mtx.Unlock()
@@ -467,6 +478,11 @@ func (server *Server) handleReconnect(
 		room.sendTable(client)
 		if room.table.State != poker.TableStateNotStarted {
 			room.sendPlayerTurn(client)
+		} else if room.paused.Load() != nil {
+			netData.ClearData(client)
+			netData.Response = NetDataServerMsg
+			netData.Msg = "the server was restarted. the game is paused until the table admin starts it again"
+			netData.Send()
 		}
 	} else {
 		netData.ClearData(NewClient(nil).SetConn(conn).SetConnType(connType))
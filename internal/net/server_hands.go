@@ -0,0 +1,107 @@
+package net
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// a client's private ID, sent with hand history requests to see the hole
+// cards they were dealt. without it only the hands that were shown down are
+// visible.
+const privIDHeader = "X-Priv-ID"
+
+// the room's password, needed to see the hands of a room that has one if the
+// requester isn't in the room
+const roomPasswordHeader = "X-Room-Password"
+
+func (server *Server) room(name string) *Room {
+	server.mtx.Lock()
+	defer server.mtx.Unlock()
+
+	return server.rooms[name]
+}
+
+// finds the room of a hand history request. clients in the room can always
+// see its hands, anyone else is held to the room's lock and password like
+// they would be joining it, see Server.handleNewConn(). returns nil if the
+// request was answered.
+func (server *Server) handsRoom(w http.ResponseWriter, req *http.Request) *Room {
+	room := server.room(mux.Vars(req)["roomName"])
+	if room == nil {
+		http.NotFound(w, req)
+
+		return nil
+	}
+
+	if privID := req.Header.Get(privIDHeader); privID != "" {
+		if _, ok := room.clients.ByPrivID(privID); ok {
+			return room
+		}
+	}
+
+	if room.isTableLocked() {
+		w.WriteHeader(http.StatusForbidden)
+
+		return nil
+	}
+
+	if !room.checkPassword(req.Header.Get(roomPasswordHeader)) {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return nil
+	}
+
+	return room
+}
+
+// GET /room/{roomName}/hands: every finished hand the room kept, oldest first
+func (server *Server) listHands(w http.ResponseWriter, req *http.Request) {
+	room := server.handsRoom(w, req)
+	if room == nil {
+		return
+	}
+
+	writeJSON(w, room.Hands(req.Header.Get(privIDHeader)))
+}
+
+// GET /room/{roomName}/hands/{id}
+func (server *Server) getHand(w http.ResponseWriter, req *http.Request) {
+	room := server.handsRoom(w, req)
+	if room == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid hand id", http.StatusBadRequest)
+
+		return
+	}
+
+	hand := room.Hand(id, req.Header.Get(privIDHeader))
+	if hand == nil {
+		http.NotFound(w, req)
+
+		return
+	}
+
+	writeJSON(w, hand)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	jsonBody, err := json.Marshal(v)
+	if err != nil {
+		log.Error().Err(err).Msg("problem encoding JSON")
+		http.Error(w, "failed to encode JSON", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBody)
+}
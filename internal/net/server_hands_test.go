@@ -0,0 +1,126 @@
+package net
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/poker"
+	"github.com/gorilla/websocket"
+)
+
+func TestHandHistoryRoutes(t *testing.T) {
+	server := NewServer("127.0.0.1:0")
+
+	table, err := poker.NewTable(poker.NewDeck(nil), poker.Holdem{}, 2, poker.TableLockNone, "", []bool{false, false})
+	if err != nil {
+		t.Fatalf("NewTable: %v", err)
+	}
+
+	room := NewRoom("test", table, "")
+	server.rooms["test"] = room
+
+	mustCards := func(codes string) poker.Cards {
+		cards, err := poker.ParseCards(codes)
+		if err != nil {
+			t.Fatalf("ParseCards: %v", err)
+		}
+
+		return cards
+	}
+
+	room.hands = append(room.hands, &roomHand{
+		history: &poker.HandHistory{
+			ID: 42,
+			Events: []poker.HistoryEvent{
+				{Kind: poker.HistoryDeal, Player: "p0", Cards: mustCards("Ah Kd")},
+				{Kind: poker.HistoryDeal, Player: "p1", Cards: mustCards("2c 3c")},
+			},
+		},
+		holders: map[string]string{"c0-priv": "p0", "c1-priv": "p1"},
+	})
+
+	get := func(path, privID string, password ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if privID != "" {
+			req.Header.Set(privIDHeader, privID)
+		}
+		if len(password) != 0 {
+			req.Header.Set(roomPasswordHeader, password[0])
+		}
+
+		res := httptest.NewRecorder()
+		server.router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	// hole cards each requester sees, by player
+	holes := func(res *httptest.ResponseRecorder) map[string]int {
+		var hand poker.HandHistory
+		if err := json.Unmarshal(res.Body.Bytes(), &hand); err != nil {
+			t.Fatalf("Unmarshal: %v\n%s", err, res.Body)
+		}
+
+		holes := make(map[string]int)
+		for _, event := range hand.Events {
+			holes[event.Player] = len(event.Cards)
+		}
+
+		return holes
+	}
+
+	if got := holes(get("/room/test/hands/42", "c0-priv")); got["p0"] != 2 || got["p1"] != 0 {
+		t.Errorf("p0 sees hole cards %v", got)
+	}
+	if got := holes(get("/room/test/hands/42", "")); got["p0"] != 0 || got["p1"] != 0 {
+		t.Errorf("spectator sees hole cards %v", got)
+	}
+
+	var hands []poker.HandHistory
+	res := get("/room/test/hands", "c1-priv")
+	if err := json.Unmarshal(res.Body.Bytes(), &hands); err != nil || len(hands) != 1 {
+		t.Fatalf("hand list: %v\n%s", err, res.Body)
+	}
+
+	for path, code := range map[string]int{
+		"/room/test/hands/7":   http.StatusNotFound,
+		"/room/test/hands/abc": http.StatusBadRequest,
+		"/room/nope/hands":     http.StatusNotFound,
+	} {
+		if res := get(path, ""); res.Code != code {
+			t.Errorf("%s: status %d, want %d", path, res.Code, code)
+		}
+	}
+
+	// the room's clients get past its lock and password, anyone else doesn't
+	client := NewClient(nil)
+	client.privID = "c0-priv"
+	client.conn = new(websocket.Conn)
+	room.clients.Register(client, client.conn)
+	client.conn = nil
+
+	table.Password = "secret"
+	for _, tt := range []struct {
+		privID, password string
+		code             int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"c1-priv", "nope", http.StatusUnauthorized},
+		{"", "secret", http.StatusOK},
+		{"c0-priv", "", http.StatusOK},
+	} {
+		if res := get("/room/test/hands", tt.privID, tt.password); res.Code != tt.code {
+			t.Errorf("password %q with %q: status %d, want %d", tt.password, tt.privID, res.Code, tt.code)
+		}
+	}
+
+	table.Lock = poker.TableLockAll
+	if res := get("/room/test/hands/42", "", "secret"); res.Code != http.StatusForbidden {
+		t.Errorf("locked room: status %d, want %d", res.Code, http.StatusForbidden)
+	}
+	if res := get("/room/test/hands/42", "c0-priv"); res.Code != http.StatusOK {
+		t.Errorf("locked room: client got status %d", res.Code)
+	}
+}
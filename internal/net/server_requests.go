@@ -2,7 +2,10 @@ package net
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/bkazemi/gopoker/internal/poker"
 	"github.com/rs/zerolog/log"
@@ -43,6 +46,16 @@ func (s *wsSession) dispatch(client *Client, netData NetData) {
 		s.handleChatMsg(client, netData)
 	case NetDataAllIn, NetDataBet, NetDataCall, NetDataCheck, NetDataFold:
 		s.handlePlayerAction(client, netData)
+	case NetDataSitOut, NetDataSitIn:
+		s.handleSitOut(client, netData)
+	case NetDataRunItTwice:
+		s.handleRunItTwice(client, netData)
+	case NetDataShowHand:
+		s.handleShowHand(client, netData)
+	case NetDataRabbitHunt:
+		s.handleRabbitHunt(client, netData)
+	case NetDataStraddle:
+		s.handleStraddle(client, netData)
 	default:
 		netData.ClearData(client)
 		netData.Response = NetDataBadRequest
@@ -138,6 +151,7 @@ func (s *wsSession) handleClientSettings(client *Client, netData NetData) {
 		return
 	}
 	room.applyClientSettings(client, settings)
+	room.save()
 
 	netData.ClearData(client)
 	if client.Player != nil { // send updated player info to other clients
@@ -169,6 +183,7 @@ func (s *wsSession) handleAdminSettings(client *Client, netData NetData) {
 		return
 	}
 	defer room.Unlock()
+	defer room.save()
 
 	prevRoomSettings := room.getRoomSettings()
 
@@ -186,7 +201,12 @@ func (s *wsSession) handleAdminSettings(client *Client, netData NetData) {
 		roomSettingsChanged := prevRoomSettings.RoomName != roomSettings.RoomName ||
 			prevRoomSettings.NumSeats != roomSettings.NumSeats ||
 			prevRoomSettings.Lock != roomSettings.Lock ||
-			prevRoomSettings.Password != roomSettings.Password
+			prevRoomSettings.Password != roomSettings.Password ||
+			prevRoomSettings.Blinds != roomSettings.Blinds ||
+			prevRoomSettings.StartingStack != roomSettings.StartingStack ||
+			prevRoomSettings.TurnTime != roomSettings.TurnTime ||
+			prevRoomSettings.TimeBank != roomSettings.TimeBank ||
+			prevRoomSettings.Betting != roomSettings.Betting
 
 		if roomSettingsChanged {
 			netData.ClearData(nil)
@@ -254,6 +274,9 @@ func (s *wsSession) handleAdminSettings(client *Client, netData NetData) {
 
 func (s *wsSession) handleStartGame(client *Client, netData NetData) {
 	room := s.room
+	room.Lock()
+	defer room.Unlock()
+
 	netData.ClearData(client)
 	if client.ID != room.tableAdminID {
 		netData.Response = NetDataBadRequest
@@ -274,6 +297,12 @@ func (s *wsSession) handleStartGame(client *Client, netData NetData) {
 		return
 	}
 
+	if room.paused.Load() != nil {
+		room.resume(time.Now())
+	} else {
+		room.table.StartBlindSchedule(time.Now())
+	}
+	room.sendDeckCommitment()
 	room.table.NextTableAction()
 
 	room.sendDeals()
@@ -281,6 +310,194 @@ func (s *wsSession) handleStartGame(client *Client, netData NetData) {
 	room.sendAllPlayerInfo(nil, false, true)
 	room.sendPlayerTurnToAll()
 	room.sendTable(nil)
+
+	if room.table.BlindSchedule != nil {
+		room.sendBlindLevel()
+		room.startBlindTimer()
+	}
+
+	room.save()
+}
+
+func (s *wsSession) handleSitOut(client *Client, netData NetData) {
+	room := s.room
+	room.Lock()
+	defer room.Unlock()
+
+	request := netData.Request
+	netData.ClearData(client)
+
+	player := client.Player
+	if player == nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = "you are not a player"
+		netData.Send()
+		return
+	}
+
+	var err error
+	if request == NetDataSitOut {
+		err = room.table.SitOut(player)
+	} else {
+		err = room.table.SitIn(player)
+	}
+
+	if err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = err.Error()
+		netData.Send()
+		return
+	}
+
+	room.sendSitOut(client)
+
+	// don't make everyone wait on a player that just sat out
+	if request == NetDataSitOut && room.table.IsCurPlayer(player) {
+		room.stopActionTimer()
+		room.startActionTimer()
+	}
+}
+
+// a player's answer to the run it twice prompt. Msg is "yes" to agree. see
+// Room.askRunItTimes()
+func (s *wsSession) handleRunItTwice(client *Client, netData NetData) {
+	room := s.room
+	room.Lock()
+	defer room.Unlock()
+
+	agree := netData.Msg == "yes"
+
+	netData.ClearData(client)
+
+	player := client.Player
+	if player == nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = "you are not a player"
+		netData.Send()
+		return
+	}
+
+	if err := room.answerRunItTwice(player, agree); err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = err.Error()
+		netData.Send()
+	}
+}
+
+// a player showing their hand after the hand is over, e.g. after winning by
+// folds. Msg is the ID of the hand, see poker.Table.HandID
+//
+// NOTE: the hand ID keeps a late request from showing a hand of the next deal
+func (s *wsSession) handleShowHand(client *Client, netData NetData) {
+	room := s.room
+	room.Lock()
+	defer room.Unlock()
+
+	handID, err := strconv.ParseUint(netData.Msg, 10, 64)
+
+	netData.ClearData(client)
+
+	if err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = "invalid hand ID"
+		netData.Send()
+		return
+	}
+
+	player := client.Player
+	if player == nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = "you are not a player"
+		netData.Send()
+		return
+	}
+
+	if err := room.table.ShowHand(player, handID); err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = err.Error()
+		netData.Send()
+		return
+	}
+
+	log.Debug().Str("room", room.name).Str("player", player.Name).Msg("showed hand")
+
+	room.recordShow(handID)
+
+	netData.Response = NetDataShowHand
+	netData.Client = room.publicClientInfo(client)
+	netData.Table = room.Table()
+	room.sendResponseToAll(&netData, nil)
+}
+
+// shows everyone the community cards that would have come had the hand gone
+// on. spectators can ask too. Msg is the ID of the hand
+func (s *wsSession) handleRabbitHunt(client *Client, netData NetData) {
+	room := s.room
+	room.Lock()
+	defer room.Unlock()
+
+	handID, err := strconv.ParseUint(netData.Msg, 10, 64)
+
+	netData.ClearData(client)
+
+	if err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = "invalid hand ID"
+		netData.Send()
+		return
+	}
+
+	cards, err := room.table.RabbitHunt(handID)
+	if err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = err.Error()
+		netData.Send()
+		return
+	}
+
+	netData.Response = NetDataRabbitHunt
+	netData.Msg = client.Name + " rabbit hunted: "
+	for _, card := range cards {
+		netData.Msg += fmt.Sprintf("[%s] ", card.Name)
+	}
+	netData.Msg = strings.TrimSpace(netData.Msg)
+	room.sendResponseToAll(&netData, nil)
+}
+
+// a player turning their straddle on or off. Msg is "yes" to turn it on.
+func (s *wsSession) handleStraddle(client *Client, netData NetData) {
+	room := s.room
+	room.Lock()
+	defer room.Unlock()
+
+	straddles := netData.Msg == "yes"
+
+	netData.ClearData(client)
+
+	player := client.Player
+	if player == nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = "you are not a player"
+		netData.Send()
+		return
+	}
+
+	if err := room.table.SetStraddle(player, straddles); err != nil {
+		netData.Response = NetDataBadRequest
+		netData.Msg = err.Error()
+		netData.Send()
+		return
+	}
+
+	netData.Response = NetDataStraddle
+	netData.Client = room.publicClientInfo(client)
+	netData.Table = room.Table()
+	if straddles {
+		netData.Msg = fmt.Sprintf("<server-msg> %s will straddle under the gun", player.Name)
+	} else {
+		netData.Msg = fmt.Sprintf("<server-msg> %s stopped straddling", player.Name)
+	}
+	room.sendResponseToAll(&netData, nil)
 }
 
 func (s *wsSession) handleChatMsg(client *Client, netData NetData) {
@@ -365,6 +582,8 @@ func (s *wsSession) handlePlayerAction(client *Client, netData NetData) {
 		netData.Msg = err.Error()
 		netData.Send()
 	} else {
+		room.stopActionTimer()
+		player.ResetTimeouts()
 		room.postPlayerAction(client, &netData)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/bkazemi/gopoker/internal/poker"
 	"github.com/rs/zerolog/log"
@@ -22,10 +23,166 @@ func init() {
 }
 
 type RoomOpts struct {
-	RoomName string          `json:"roomName"`
-	NumSeats uint8           `json:"numSeats"`
-	Lock     poker.TableLock `json:"lock"`
-	Password string          `json:"password"`
+	RoomName   string          `json:"roomName"`
+	NumSeats   uint8           `json:"numSeats"`
+	Lock       poker.TableLock `json:"lock"`
+	Password   string          `json:"password"`
+	SmallBlind poker.Chips     `json:"smallBlind"`
+	BigBlind   poker.Chips     `json:"bigBlind"`
+	Ante       poker.Chips     `json:"ante"`
+
+	StartingStack poker.Chips `json:"startingStack"` // NOTE: 0 means default
+
+	// in seconds. NOTE: 0 TurnTime means default
+	TurnTime uint64 `json:"turnTime"`
+	TimeBank uint64 `json:"timeBank"`
+
+	SitOutOrbits uint8 `json:"sitOutOrbits"` // NOTE: 0 means never remove
+	RunItTimes   uint8 `json:"runItTimes"`   // NOTE: 0 and 1 mean the board is always run once
+	Straddle     bool  `json:"straddle"`     // players may post an under the gun straddle
+	CommitReveal bool  `json:"commitReveal"` // publish a hash of each shuffle before the deal
+
+	Game    string `json:"game"`    // "holdem" (default), "omaha", "omaha8", "shortdeck", "stud" or "stud8"
+	Betting string `json:"betting"` // "no-limit" (default), "pot-limit" or "fixed-limit"
+
+	// NOTE: overrides the blinds above when set
+	BlindSchedule []BlindLevelOpts `json:"blindSchedule"`
+}
+
+// a single level of a RoomOpts blind schedule. a level lasts either a
+// number of minutes or hands.
+type BlindLevelOpts struct {
+	SmallBlind poker.Chips `json:"smallBlind"`
+	BigBlind   poker.Chips `json:"bigBlind"`
+	Ante       poker.Chips `json:"ante"`
+	Minutes    uint64      `json:"minutes"`
+	Hands      uint64      `json:"hands"`
+}
+
+// fills in the blinds that were left out of a new room request
+func (roomOpts *RoomOpts) blinds() poker.Blinds {
+	if roomOpts.BigBlind == 0 {
+		return poker.Blinds{
+			SmallBlind: poker.DefaultBlinds.SmallBlind,
+			BigBlind:   poker.DefaultBlinds.BigBlind,
+			Ante:       roomOpts.Ante,
+		}
+	}
+
+	blinds := poker.Blinds{
+		SmallBlind: roomOpts.SmallBlind,
+		BigBlind:   roomOpts.BigBlind,
+		Ante:       roomOpts.Ante,
+	}
+
+	if blinds.SmallBlind == 0 {
+		blinds.SmallBlind = max(blinds.BigBlind/2, 1)
+	}
+
+	return blinds
+}
+
+// returns nil if the new room request has no blind schedule
+func (roomOpts *RoomOpts) blindSchedule() (*poker.BlindSchedule, error) {
+	if len(roomOpts.BlindSchedule) == 0 {
+		return nil, nil
+	}
+
+	levels := make([]poker.BlindLevel, 0, len(roomOpts.BlindSchedule))
+	for _, level := range roomOpts.BlindSchedule {
+		if level.SmallBlind == 0 {
+			level.SmallBlind = max(level.BigBlind/2, 1)
+		}
+
+		levels = append(levels, poker.BlindLevel{
+			Blinds: poker.Blinds{
+				SmallBlind: level.SmallBlind,
+				BigBlind:   level.BigBlind,
+				Ante:       level.Ante,
+			},
+			Minutes: level.Minutes,
+			Hands:   level.Hands,
+		})
+	}
+
+	return poker.NewBlindSchedule(levels)
+}
+
+// builds the table of a room with these settings. roomOpts.NumSeats must be
+// valid
+func (roomOpts *RoomOpts) newTable() (*poker.Table, error) {
+	blinds := roomOpts.blinds()
+	if err := blinds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid blinds: %w", err)
+	}
+
+	blindSchedule, err := roomOpts.blindSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("invalid blind schedule: %w", err)
+	}
+
+	variant, err := poker.ParseVariant(roomOpts.Game)
+	if err != nil {
+		return nil, fmt.Errorf("invalid game: %w", err)
+	}
+
+	deck := variant.NewDeck(nil)
+
+	deck.Shuffle()
+
+	table, err := poker.NewTable(deck, variant, roomOpts.NumSeats, roomOpts.Lock, roomOpts.Password,
+		make([]bool, roomOpts.NumSeats))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create a new table: %w", err)
+	}
+
+	// NOTE: the stack has to be set first, the blinds are checked against it
+	if roomOpts.StartingStack != 0 {
+		if err := table.SetStartingStack(roomOpts.StartingStack); err != nil {
+			return nil, fmt.Errorf("invalid starting stack: %w", err)
+		}
+	}
+
+	if err := table.SetBlinds(blinds); err != nil {
+		return nil, fmt.Errorf("couldn't create a new table: %w", err)
+	}
+
+	if err := table.SetBlindSchedule(blindSchedule); err != nil {
+		return nil, fmt.Errorf("couldn't create a new table: %w", err)
+	}
+
+	if roomOpts.TurnTime != 0 || roomOpts.TimeBank != 0 {
+		turnTime := time.Duration(roomOpts.TurnTime) * time.Second
+		if turnTime == 0 {
+			turnTime = poker.DefaultTurnTime
+		}
+
+		if err := table.SetTurnClock(turnTime, time.Duration(roomOpts.TimeBank)*time.Second); err != nil {
+			return nil, fmt.Errorf("invalid turn clock: %w", err)
+		}
+	}
+
+	table.SitOutOrbits = roomOpts.SitOutOrbits
+
+	if err := table.SetRunItTimes(roomOpts.RunItTimes); err != nil {
+		return nil, fmt.Errorf("invalid runItTimes: %w", err)
+	}
+
+	if err := table.SetAllowStraddle(roomOpts.Straddle); err != nil {
+		return nil, fmt.Errorf("couldn't create a new table: %w", err)
+	}
+
+	if err := table.SetCommitReveal(roomOpts.CommitReveal); err != nil {
+		return nil, fmt.Errorf("couldn't create a new table: %w", err)
+	}
+
+	if betting, err := poker.ParseBettingStructure(roomOpts.Betting); err != nil {
+		return nil, fmt.Errorf("invalid betting structure: %w", err)
+	} else if err := table.SetBetting(betting); err != nil {
+		return nil, fmt.Errorf("couldn't create a new table: %w", err)
+	}
+
+	return table, nil
 }
 
 type RoomList struct {
@@ -88,6 +245,77 @@ func (server *Server) handleRoomSettings(room *Room, client *Client, settings *R
 		msg += "room name: unchanged\n"
 	}
 
+	setBlinds := func() {
+		if settings.Blinds != (poker.Blinds{}) && settings.Blinds != room.table.BaseBlinds() {
+			if err := room.table.SetBlinds(settings.Blinds); err != nil {
+				if errs != "" {
+					errs += "\n"
+				}
+				errs += "blinds: " + err.Error()
+			} else {
+				msg += "blinds: changed\n"
+			}
+		} else {
+			msg += "blinds: unchanged\n"
+		}
+	}
+
+	setStartingStack := func() {
+		if settings.StartingStack != 0 && settings.StartingStack != room.table.StartingStack {
+			if err := room.table.SetStartingStack(settings.StartingStack); err != nil {
+				if errs != "" {
+					errs += "\n"
+				}
+				errs += "starting stack: " + err.Error()
+			} else {
+				msg += "starting stack: changed\n"
+			}
+		} else {
+			msg += "starting stack: unchanged\n"
+		}
+	}
+
+	// NOTE: the blinds and the starting stack are checked against each other,
+	//       so a larger stack goes in before the blinds and a smaller one after
+	if settings.StartingStack > room.table.StartingStack {
+		setStartingStack()
+		setBlinds()
+	} else {
+		setBlinds()
+		setStartingStack()
+	}
+
+	if settings.TurnTime != 0 &&
+		(settings.TurnTime != room.table.TurnTime || settings.TimeBank != room.table.TimeBank) {
+		if err := room.table.SetTurnClock(settings.TurnTime, settings.TimeBank); err != nil {
+			if errs != "" {
+				errs += "\n"
+			}
+			errs += "turn clock: " + err.Error()
+		} else {
+			msg += "turn clock: changed\n"
+		}
+	} else {
+		msg += "turn clock: unchanged\n"
+	}
+
+	if betting, err := poker.ParseBettingStructure(settings.Betting); settings.Betting != "" &&
+		(err != nil || betting != room.table.Betting) {
+		if err == nil {
+			err = room.table.SetBetting(betting)
+		}
+		if err != nil {
+			if errs != "" {
+				errs += "\n"
+			}
+			errs += "betting: " + err.Error()
+		} else {
+			msg += "betting: changed\n"
+		}
+	} else {
+		msg += "betting: unchanged\n"
+	}
+
 	if settings.NumSeats != room.table.NumSeats {
 		if err := room.table.SetNumSeats(settings.NumSeats); err != nil {
 			if errs != "" {
@@ -138,6 +366,12 @@ func (server *Server) validateRoomRename(room *Room, newName string) (validatedN
 }
 
 func (server *Server) applyRoomRename(room *Room, newName string) {
+	if room.store != nil {
+		if err := room.store.RenameRoom(room.name, newName); err != nil && !errors.Is(err, ErrStoreClosed) {
+			log.Error().Err(err).Str("room", room.name).Str("newName", newName).Msg("couldn't rename saved room")
+		}
+	}
+
 	delete(server.rooms, room.name)
 	room.name = newName
 	server.rooms[newName] = room
@@ -193,7 +427,7 @@ func (server *Server) listRooms(w http.ResponseWriter, req *http.Request) {
 			RoomList{
 				RoomName:     name,
 				TableLock:    table.Lock,
-				NeedPassword: table.Password != "",
+				NeedPassword: room.hasPassword(),
 				NumSeats:     table.NumSeats,
 				NumPlayers:   table.NumPlayers,
 				NumOpenSeats: table.NumSeats - table.NumPlayers,
@@ -269,27 +503,27 @@ func (server *Server) createNewRoom(w http.ResponseWriter, req *http.Request) {
 		roomOpts.NumSeats = 7
 	}
 
-	deck := poker.NewDeck()
-
-	deck.Shuffle()
-
-	table, tableErr := poker.NewTable(deck, roomOpts.NumSeats, roomOpts.Lock, roomOpts.Password,
-		make([]bool, roomOpts.NumSeats))
-	if tableErr != nil {
-		log.Error().Err(tableErr).Msg("problem creating new table")
-		http.Error(w, fmt.Sprintf("couldn't create a new table: %v", tableErr), http.StatusBadRequest)
+	table, err := roomOpts.newTable()
+	if err != nil {
+		log.Warn().Err(err).Msg("problem creating new table")
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
 
 	log.Debug().
-		Msgf("table.Lock: %v table.Password: %v table.NumSeats: %v", table.Lock, table.Password, table.NumSeats)
+		Msgf("table.Lock: %v table.Password: %v table.NumSeats: %v table.Blinds: %v",
+			table.Lock, table.Password, table.NumSeats, table.Blinds)
 
 	log.Info().Str("roomName", roomOpts.RoomName).Msg("creating new room")
 
 	room := NewRoom(roomOpts.RoomName, table, poker.RandString(17))
+	room.handHistoryDir = server.HandHistoryDir
+	room.store = server.Store
 	server.rooms[roomOpts.RoomName] = room
 
+	room.save()
+
 	res := struct {
 		URL          string `json:"URL"`
 		RoomName     string `json:"roomName"`
@@ -320,6 +554,19 @@ func (server *Server) removeRoom(room *Room) {
 		log.Info().Str("room", room.name).Msg("removing room")
 
 		delete(server.rooms, room.name)
+
+		if room.store != nil {
+			if err := room.store.DeleteRoom(room.name); err != nil && !errors.Is(err, ErrStoreClosed) {
+				log.Error().Err(err).Str("room", room.name).Msg("couldn't delete saved room")
+			}
+		}
+
+		room.Lock()
+		room.stopBlindTimer()
+		room.stopActionTimer()
+		room.stopNextHandTimer()
+		room.stopRunItPrompt()
+		room.Unlock()
 	} else {
 		log.Warn().Str("room", room.name).Msg("room not found")
 	}
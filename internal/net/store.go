@@ -0,0 +1,85 @@
+package net
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/poker"
+)
+
+// Store keeps rooms across server restarts: their settings, who sits where
+// with how many chips, and their finished hands. see FileStore and
+// Server.RestoreRooms()
+type Store interface {
+	// Rooms returns every saved room
+	Rooms() ([]*StoredRoom, error)
+	// SaveRoom replaces the saved room named room.Opts.RoomName
+	SaveRoom(room *StoredRoom) error
+	// RenameRoom moves a saved room and its hands to a new name
+	RenameRoom(oldName, newName string) error
+	// DeleteRoom forgets a room and its hands
+	DeleteRoom(name string) error
+
+	// AddHand appends a finished hand to the room's saved hands
+	AddHand(roomName string, hand *StoredHand) error
+	// UpdateHand replaces the saved hand with the same history ID, e.g. once
+	// a hand was shown after it was saved
+	UpdateHand(roomName string, hand *StoredHand) error
+	// Hands returns the room's saved hands, oldest first
+	Hands(roomName string) ([]*StoredHand, error)
+
+	// Close makes every later write fail with ErrStoreClosed
+	Close() error
+}
+
+// ErrStoreClosed is returned by writes to a closed Store. rooms that keep
+// going while the server shuts down can't overwrite what was saved last.
+var ErrStoreClosed = errors.New("store is closed")
+
+// StoredRoom is everything a room needs to come back after a restart. chip
+// counts are the ones the players had before the hand in progress, so a
+// hand cut off by a restart is called off.
+type StoredRoom struct {
+	Opts RoomOpts `json:"opts"` // the room settings, as if the room was created with them. NOTE: without the password
+
+	Password *StoredPassword `json:"password,omitempty"` // nil if the room has no password
+
+	CreatorToken string `json:"creatorToken,omitempty"` // NOTE: empty once the creator joined
+	AdminID      string `json:"adminID,omitempty"`      // client ID of the table admin
+
+	Started bool `json:"started"` // a game was going. NOTE: restored paused
+	Button  uint `json:"button"`  // seat of the button in the last hand. 0 if none
+
+	// the blind schedule's progress, see poker.BlindSchedule
+	BlindLevel      int           `json:"blindLevel"`
+	BlindLevelHands uint64        `json:"blindLevelHands"`
+	BlindLevelTime  time.Duration `json:"blindLevelTime"` // time played at the level
+
+	Seats []StoredSeat `json:"seats"`
+}
+
+// a room password as it's saved: a salted PBKDF2 hash, so a copy of the
+// store doesn't give away the password
+type StoredPassword struct {
+	Salt []byte `json:"salt"`
+	Hash []byte `json:"hash"`
+}
+
+// a seated player and the client that plays them
+type StoredSeat struct {
+	Seat      uint8         `json:"seat"` // NOTE: 1-based like Table.GetSeat()
+	Name      string        `json:"name"`
+	Chips     poker.Chips   `json:"chips"`
+	TimeBank  time.Duration `json:"timeBank"`
+	Straddles bool          `json:"straddles"`
+
+	ClientID        string `json:"clientID"`
+	PrivID          string `json:"privID"` // reclaims the seat, see Server.handleReconnect(). NOTE: kept as is, see FileStore
+	MuckLosingHands bool   `json:"muckLosingHands"`
+}
+
+// a finished hand, see Room.Hands()
+type StoredHand struct {
+	History *poker.HandHistory `json:"history"`
+	Holders map[string]string  `json:"holders"` // privID -> name of the player the client played in the hand
+}
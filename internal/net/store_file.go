@@ -0,0 +1,357 @@
+package net
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FileStore is a Store that keeps each room in its own directory:
+//
+//	<dir>/<hex room name>/room.json   the StoredRoom
+//	<dir>/<hex room name>/hands.jsonl one StoredHand per line, oldest first
+//
+// room.json is replaced atomically, so a crash leaves the last complete
+// save behind. it has the private IDs of the seated players, which are enough
+// to take over their seats, so the directories and files are only readable
+// by the user the server runs as.
+type FileStore struct {
+	dir string
+
+	// hands a room keeps. older hands are dropped from hands.jsonl once it
+	// has twice as many. 0 keeps every hand
+	MaxHands int
+
+	handCounts map[string]int // room dir -> lines in hands.jsonl. NOTE: filled in lazily
+	closed     bool
+
+	mtx sync.Mutex
+}
+
+// NewFileStore keeps rooms under dir. NOTE: dir is made readable by the
+// server's user only, even if it already existed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir:        dir,
+		MaxHands:   maxRoomHands,
+		handCounts: make(map[string]int),
+	}, nil
+}
+
+// room names can be anything, so the directory is named after the hex of
+// the name
+func (store *FileStore) roomDir(name string) string {
+	return filepath.Join(store.dir, hex.EncodeToString([]byte(name)))
+}
+
+func (store *FileStore) Rooms() ([]*StoredRoom, error) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]*StoredRoom, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(store.dir, entry.Name(), "room.json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Warn().Str("dir", entry.Name()).Msg("store: room directory without a room.json, skipping")
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var room StoredRoom
+		if err := json.Unmarshal(data, &room); err != nil {
+			log.Error().Err(err).Str("dir", entry.Name()).Msg("store: couldn't decode room.json, skipping")
+			continue
+		}
+
+		rooms = append(rooms, &room)
+	}
+
+	return rooms, nil
+}
+
+func (store *FileStore) SaveRoom(room *StoredRoom) error {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	if store.closed {
+		return ErrStoreClosed
+	}
+
+	data, err := json.Marshal(room)
+	if err != nil {
+		return err
+	}
+
+	dir := store.roomDir(room.Opts.RoomName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(dir, "room.json"), data)
+}
+
+func (store *FileStore) RenameRoom(oldName, newName string) error {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	if store.closed {
+		return ErrStoreClosed
+	}
+
+	oldDir, newDir := store.roomDir(oldName), store.roomDir(newName)
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return err
+	}
+
+	if count, ok := store.handCounts[oldDir]; ok {
+		delete(store.handCounts, oldDir)
+		store.handCounts[newDir] = count
+	}
+
+	return nil
+}
+
+func (store *FileStore) DeleteRoom(name string) error {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	if store.closed {
+		return ErrStoreClosed
+	}
+
+	dir := store.roomDir(name)
+	delete(store.handCounts, dir)
+
+	return os.RemoveAll(dir)
+}
+
+func (store *FileStore) AddHand(roomName string, hand *StoredHand) error {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	if store.closed {
+		return ErrStoreClosed
+	}
+
+	line, err := json.Marshal(hand)
+	if err != nil {
+		return err
+	}
+
+	dir := store.roomDir(roomName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	count, ok := store.handCounts[dir]
+	if !ok {
+		hands, err := store.readHands(dir)
+		if err != nil {
+			return err
+		}
+		count = len(hands)
+	}
+
+	path := filepath.Join(dir, "hands.jsonl")
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	count++
+
+	if store.MaxHands > 0 && count >= 2*store.MaxHands {
+		hands, err := store.readHands(dir)
+		if err != nil {
+			return err
+		}
+
+		if len(hands) > store.MaxHands {
+			hands = hands[len(hands)-store.MaxHands:]
+		}
+
+		if count, err = store.writeHands(dir, hands); err != nil {
+			return err
+		}
+	}
+
+	store.handCounts[dir] = count
+
+	return nil
+}
+
+func (store *FileStore) UpdateHand(roomName string, hand *StoredHand) error {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	if store.closed {
+		return ErrStoreClosed
+	}
+
+	dir := store.roomDir(roomName)
+
+	hands, err := store.readHands(dir)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(hands, func(saved *StoredHand) bool {
+		return saved.History != nil && saved.History.ID == hand.History.ID
+	})
+	if i == -1 {
+		return fmt.Errorf("hand #%d isn't saved", hand.History.ID)
+	}
+	hands[i] = hand
+
+	count, err := store.writeHands(dir, hands)
+	if err != nil {
+		return err
+	}
+	store.handCounts[dir] = count
+
+	return nil
+}
+
+func (store *FileStore) Hands(roomName string) ([]*StoredHand, error) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	hands, err := store.readHands(store.roomDir(roomName))
+	if err != nil {
+		return nil, err
+	}
+
+	if store.MaxHands > 0 && len(hands) > store.MaxHands {
+		hands = hands[len(hands)-store.MaxHands:]
+	}
+
+	return hands, nil
+}
+
+func (store *FileStore) Close() error {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	store.closed = true
+
+	return nil
+}
+
+// NOTE: caller must hold the store lock
+func (store *FileStore) readHands(dir string) ([]*StoredHand, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "hands.jsonl"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	hands := make([]*StoredHand, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var hand StoredHand
+		if err := json.Unmarshal(scanner.Bytes(), &hand); err != nil {
+			// NOTE: a crash while appending can leave a partial last line
+			log.Warn().Err(err).Str("dir", dir).Msg("store: skipping a bad hand")
+			continue
+		}
+
+		hands = append(hands, &hand)
+	}
+
+	return hands, scanner.Err()
+}
+
+// replaces hands.jsonl with hands. returns the number of hands written.
+//
+// NOTE: caller must hold the store lock
+func (store *FileStore) writeHands(dir string, hands []*StoredHand) (int, error) {
+	var buf bytes.Buffer
+	for _, hand := range hands {
+		line, err := json.Marshal(hand)
+		if err != nil {
+			return 0, err
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return len(hands), writeFileAtomic(filepath.Join(dir, "hands.jsonl"), buf.Bytes())
+}
+
+// writes data to a temporary file next to path and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package net
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/poker"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	store.MaxHands = 2
+
+	// room names can have anything in them
+	const name = "../a/b c"
+
+	room := &StoredRoom{
+		Opts:    RoomOpts{RoomName: name, NumSeats: 2},
+		Started: true,
+		Seats:   []StoredSeat{{Seat: 2, Name: "p0", Chips: 900, PrivID: "c0-priv"}},
+	}
+	if err := store.SaveRoom(room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	for id := range uint64(5) {
+		hand := &StoredHand{History: &poker.HandHistory{ID: id}, Holders: map[string]string{"c0-priv": "p0"}}
+		if err := store.AddHand(name, hand); err != nil {
+			t.Fatalf("AddHand: %v", err)
+		}
+	}
+
+	if err := store.RenameRoom(name, "new"); err != nil {
+		t.Fatalf("RenameRoom: %v", err)
+	}
+	room.Opts.RoomName = "new"
+	if err := store.SaveRoom(room); err != nil {
+		t.Fatalf("SaveRoom: %v", err)
+	}
+
+	// a new process reads what the last one wrote
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	store.MaxHands = 2
+
+	rooms, err := store.Rooms()
+	if err != nil || len(rooms) != 1 {
+		t.Fatalf("Rooms: %v %v", rooms, err)
+	}
+	if got := rooms[0]; got.Opts.RoomName != "new" || !got.Started ||
+		len(got.Seats) != 1 || got.Seats[0] != room.Seats[0] {
+		t.Errorf("got room %+v, want %+v", got, room)
+	}
+
+	hands, err := store.Hands("new")
+	if err != nil {
+		t.Fatalf("Hands: %v", err)
+	}
+	if len(hands) != 2 || hands[0].History.ID != 3 || hands[1].History.ID != 4 ||
+		hands[1].Holders["c0-priv"] != "p0" {
+		t.Errorf("got hands %+v, want hands 3 and 4", hands)
+	}
+
+	// a hand shown after it was saved
+	shown := &StoredHand{
+		History: &poker.HandHistory{ID: 3, Shows: []poker.HistoryShow{{Player: "p0"}}},
+		Holders: map[string]string{"c0-priv": "p0"},
+	}
+	if err := store.UpdateHand("new", shown); err != nil {
+		t.Fatalf("UpdateHand: %v", err)
+	}
+	if err := store.UpdateHand("new", &StoredHand{History: &poker.HandHistory{ID: 0}}); err == nil {
+		t.Errorf("UpdateHand of a dropped hand didn't fail")
+	}
+	if hands, err := store.Hands("new"); err != nil || len(hands) != 2 ||
+		len(hands[0].History.Shows) != 1 || len(hands[1].History.Shows) != 0 {
+		t.Errorf("Hands after UpdateHand: %+v %v", hands, err)
+	}
+
+	// the seats' private IDs are only readable by the server's user
+	for _, path := range []string{
+		dir,
+		filepath.Join(store.roomDir("new"), "room.json"),
+		filepath.Join(store.roomDir("new"), "hands.jsonl"),
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm&0o077 != 0 {
+			t.Errorf("%s has permissions %v", path, perm)
+		}
+	}
+
+	// the oldest hands were dropped from the file
+	data, err := os.ReadFile(filepath.Join(store.roomDir("new"), "hands.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines >= 4 {
+		t.Errorf("hands.jsonl has %d hands", lines)
+	}
+
+	if err := store.DeleteRoom("new"); err != nil {
+		t.Fatalf("DeleteRoom: %v", err)
+	}
+	if rooms, err := store.Rooms(); err != nil || len(rooms) != 0 {
+		t.Errorf("Rooms after DeleteRoom: %v %v", rooms, err)
+	}
+
+	store.Close()
+	if err := store.SaveRoom(room); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("SaveRoom on a closed store: got error %v", err)
+	}
+}
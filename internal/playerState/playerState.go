@@ -12,6 +12,7 @@ const (
 	VacantSeat
 	PlayerTurn
 	MidroundAddition
+	SittingOut
 )
 
 /*NetDataAllIn:
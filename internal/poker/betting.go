@@ -0,0 +1,248 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// limits on how much a player can bet or raise
+type BettingStructure uint8
+
+const (
+	BettingNoLimit BettingStructure = iota
+	BettingPotLimit
+	BettingFixedLimit
+)
+
+// number of bets allowed per street in fixed-limit games (a bet and 3 raises).
+// on the preflop the big blind counts as the first bet.
+const FixedLimitMaxBets = 4
+
+func (betting BettingStructure) String() string {
+	switch betting {
+	case BettingNoLimit:
+		return "no-limit"
+	case BettingPotLimit:
+		return "pot-limit"
+	case BettingFixedLimit:
+		return "fixed-limit"
+	default:
+		return "invalid betting structure"
+	}
+}
+
+func ParseBettingStructure(str string) (BettingStructure, error) {
+	switch str {
+	case "", "nl", "no-limit", "nolimit":
+		return BettingNoLimit, nil
+	case "pl", "pot-limit", "potlimit":
+		return BettingPotLimit, nil
+	case "fl", "fixed-limit", "fixedlimit", "limit":
+		return BettingFixedLimit, nil
+	default:
+		return 0, fmt.Errorf("unknown betting structure '%s'", str)
+	}
+}
+
+// SetBetting changes the betting structure. only allowed between games.
+func (table *Table) SetBetting(betting BettingStructure) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if betting > BettingFixedLimit {
+		return errors.New("invalid betting structure")
+	} else if table.State != TableStateNotStarted {
+		return errors.New("betting structure can't be changed while a game is in progress")
+	}
+
+	table.Betting = betting
+
+	return nil
+}
+
+// the legal bet sizes for a player on their turn. bets are the player's
+// total bet for the street, same as Action.Amount.
+type RaiseLimits struct {
+	MinBet   Chips
+	MaxBet   Chips
+	CanRaise bool // false if the player can only check, call, fold or go all in for less
+}
+
+// the small bet in fixed-limit games is used on the preflop & first street,
+// the big bet on the streets after (the turn & river in hold'em).
+func (table *Table) fixedBetSize() Chips {
+	if table.streetIndex() >= 1 {
+		return 2 * table.Blinds.BigBlind
+	}
+
+	return table.Blinds.BigBlind
+}
+
+// total of the mainpot and all open sidepots
+func (table *Table) potTotal() Chips {
+	total := table.MainPot.Total
+
+	for _, sidePot := range table.sidePots.AllInPots.GetOpenPots() {
+		total += sidePot.Total
+	}
+	if table.sidePots.BettingPot != nil {
+		total += table.sidePots.BettingPot.Total
+	}
+
+	return total
+}
+
+// MinRaise returns the smallest amount the current bet can be raised by: the
+// size of the last full bet or raise this street, and at least the big blind.
+func (table *Table) MinRaise() Chips {
+	return max(table.lastRaise, table.Blinds.BigBlind)
+}
+
+// the bet a raise is measured from. a stud bring-in isn't a bet, completing
+// it to the big blind is a full bet.
+func (table *Table) raiseBase() Chips {
+	if table.isBringIn() {
+		return 0
+	}
+
+	return table.Bet
+}
+
+// called before the table bet is raised to amount. a raise smaller than the
+// minimum raise (an allin for less) doesn't change the minimum raise or
+// reopen the betting for players that already acted. several allins for
+// less that add up to a full raise over the last full bet do reopen it, but
+// only for the players that face a full raise.
+func (table *Table) recordRaise(amount Chips) {
+	if raise := amount - table.raiseBase(); raise >= table.MinRaise() {
+		table.lastRaise = raise
+	} else if amount-table.fullBet < table.MinRaise() {
+		return
+	} else {
+		for _, player := range table.curPlayers.ToPlayerArray() {
+			if player.actedOnRaise == table.fullRaises && amount-player.Action.Amount < table.MinRaise() {
+				player.actedOnRaise++ // NOTE: stays closed, see raiseIsOpen()
+			}
+		}
+	}
+
+	table.fullBet = amount
+	table.fullRaises++
+	table.betCount++
+}
+
+// reports whether player may raise. a player that already acted this street
+// can only raise again after someone else makes a full raise.
+func (table *Table) raiseIsOpen(player *Player) bool {
+	return player.actedOnRaise != table.fullRaises
+}
+
+// RaiseLimits returns the smallest and largest bet player can make under the
+// table's betting structure.
+func (table *Table) RaiseLimits(player *Player) RaiseLimits {
+	stack := player.ChipCount + player.Action.Amount
+
+	limits := RaiseLimits{
+		MinBet: table.raiseBase() + table.MinRaise(),
+	}
+
+	switch table.Betting {
+	case BettingPotLimit:
+		// the max raise is the size of the pot after calling
+		var call Chips
+		if table.Bet > player.Action.Amount {
+			call = table.Bet - player.Action.Amount
+		}
+		limits.MaxBet = table.Bet + table.potTotal() + call
+	case BettingFixedLimit:
+		limits.MinBet = table.raiseBase() + table.fixedBetSize()
+		limits.MaxBet = limits.MinBet
+
+		if table.betCount >= FixedLimitMaxBets {
+			limits.MaxBet = table.Bet
+
+			return limits
+		}
+	default:
+		limits.MaxBet = stack
+	}
+
+	limits.MaxBet = min(limits.MaxBet, stack)
+	limits.CanRaise = limits.MinBet <= limits.MaxBet && table.curPlayers.Len > 1 &&
+		table.raiseIsOpen(player)
+
+	return limits
+}
+
+// checks a bet of amount chips against the betting structure
+func (table *Table) validateBet(player *Player, amount Chips) error {
+	limits := table.RaiseLimits(player)
+
+	stack := player.ChipCount + player.Action.Amount
+
+	if table.Betting == BettingFixedLimit {
+		if table.betCount >= FixedLimitMaxBets {
+			return errors.New(printer.Sprintf("betting is capped at %d bets this street", FixedLimitMaxBets))
+		} else if amount != limits.MinBet && amount != stack {
+			return errors.New(printer.Sprintf("fixed-limit: you can only raise to %d chips", limits.MinBet))
+		}
+	}
+
+	if !table.raiseIsOpen(player) {
+		return errors.New("the betting wasn't reopened by a full raise, you can only call or fold")
+	}
+
+	if amount < table.Blinds.BigBlind && amount != stack {
+		return errors.New(printer.Sprintf("bet must be at least the big blind (%d chips)", table.Blinds.BigBlind))
+	} else if amount <= table.Bet {
+		return errors.New(printer.Sprintf("bet must be greater than the current bet (%d chips)", table.Bet))
+	} else if amount > stack {
+		return errors.New("not enough chips")
+	} else if amount < limits.MinBet && amount != stack {
+		// NOTE: betting your whole stack is an allin, which can be less than a full raise
+		return errors.New(printer.Sprintf("raise must be to at least %d chips (minimum raise is %d chips)",
+			limits.MinBet, table.MinRaise()))
+	} else if amount > limits.MaxBet {
+		return errors.New(printer.Sprintf("%s: bet can be at most %d chips", table.Betting, limits.MaxBet))
+	}
+
+	return nil
+}
+
+// checks that an allin doesn't raise when the player isn't allowed to, or
+// past what the betting structure allows. an allin for less than a full
+// raise is otherwise always allowed.
+func (table *Table) validateAllIn(player *Player) error {
+	stack := player.ChipCount + player.Action.Amount
+
+	// NOTE: a chipleader can only bet what at least one other player can match.
+	if chipLeader, secondChipLeader := table.getChipLeaders(true); stack == chipLeader {
+		stack = secondChipLeader
+	}
+
+	if stack <= table.Bet || table.BettingIsImpossible() {
+		return nil // the allin is a call
+	}
+
+	if !table.raiseIsOpen(player) {
+		return errors.New("the betting wasn't reopened by a full raise, you can only call or fold")
+	}
+
+	if table.Betting == BettingNoLimit {
+		return nil
+	}
+
+	if limits := table.RaiseLimits(player); stack > limits.MaxBet {
+		if table.Betting == BettingFixedLimit && table.betCount >= FixedLimitMaxBets {
+			return errors.New(printer.Sprintf("betting is capped at %d bets this street", FixedLimitMaxBets))
+		}
+
+		return errors.New(printer.Sprintf("%s: you can't go all in for more than %d chips", table.Betting, limits.MaxBet))
+	}
+
+	return nil
+}
+
+func (table *Table) BettingToString() string {
+	return table.Betting.String()
+}
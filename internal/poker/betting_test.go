@@ -0,0 +1,408 @@
+package poker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+// newTestGame seats a player for each stack and deals the first hand with
+// the default blinds. p0 is the dealer, p1 the small blind and p2 the big
+// blind. heads up p0 is the dealer and small blind and p1 the big blind.
+func newTestGame(t *testing.T, stacks ...Chips) *Table {
+	t.Helper()
+
+	return newVariantTestGame(t, Holdem{}, NewDeck(nil), DefaultBlinds, stacks...)
+}
+
+func newVariantTestGame(t *testing.T, variant Variant, deck *Deck, blinds Blinds, stacks ...Chips) *Table {
+	t.Helper()
+
+	table := newUndealtTestGame(t, variant, deck, blinds, stacks...)
+	table.NextTableAction()
+
+	return table
+}
+
+// seats the players without dealing the first hand, like a game the server
+// hasn't started yet
+func newUndealtTestGame(t *testing.T, variant Variant, deck *Deck, blinds Blinds, stacks ...Chips) *Table {
+	t.Helper()
+
+	table, err := NewTable(deck, variant, uint8(len(stacks)), TableLockNone, "", make([]bool, len(stacks)))
+	if err != nil {
+		t.Fatalf("NewTable: %v", err)
+	}
+
+	if err := table.SetBlinds(blinds); err != nil {
+		t.Fatalf("SetBlinds: %v", err)
+	}
+
+	for _, stack := range stacks {
+		player := table.GetOpenSeat()
+		player.ChipCount = stack
+		player.Action.Action = playerState.FirstAction
+
+		table.curPlayers.AddPlayer(player)
+		table.activePlayers.AddPlayer(player)
+	}
+
+	table.curPlayer = table.curPlayers.Head
+	table.Dealer = table.activePlayers.Head
+	table.SmallBlind = table.Dealer.Next()
+	table.BigBlind = table.SmallBlind.Next()
+
+	return table
+}
+
+// deals the next street the same way the frontend does once betting is done
+func nextStreet(t *testing.T, table *Table) {
+	t.Helper()
+
+	if table.State != TableStateDoneBetting {
+		t.Fatalf("betting isn't done, table state is %s", table.TableStateToString())
+	}
+
+	table.NextCommunityAction()
+
+	table.Bet = 0
+	table.SetBetter(nil)
+	for _, player := range table.curPlayers.ToPlayerArray() {
+		player.Action.Clear()
+	}
+	table.ReorderPlayers()
+}
+
+type bettingStep struct {
+	player string
+	action playerState.PlayerState
+	amount Chips
+	err    string // expected error substring. empty means the action must succeed
+}
+
+func runBettingSteps(t *testing.T, table *Table, steps []bettingStep) {
+	t.Helper()
+
+	for i, step := range steps {
+		if step.player == "" {
+			nextStreet(t, table)
+			continue
+		}
+
+		if name := table.curPlayer.Player.Name; name != step.player {
+			t.Fatalf("step %d: expected it to be %s's turn, got %s", i, step.player, name)
+		}
+
+		err := table.PlayerAction(table.curPlayer.Player, Action{Action: step.action, Amount: step.amount})
+		switch {
+		case step.err == "" && err != nil:
+			t.Fatalf("step %d: %s: unexpected error: %v", i, step.player, err)
+		case step.err != "" && err == nil:
+			t.Fatalf("step %d: %s: expected error containing %q", i, step.player, step.err)
+		case step.err != "" && !strings.Contains(err.Error(), step.err):
+			t.Fatalf("step %d: %s: expected error containing %q, got %q", i, step.player, step.err, err)
+		}
+	}
+}
+
+func TestMinRaise(t *testing.T) {
+	const (
+		allIn = playerState.AllIn
+		bet   = playerState.Bet
+		call  = playerState.Call
+	)
+
+	tests := []struct {
+		name         string
+		stacks       []Chips
+		steps        []bettingStep
+		wantMinBet   Chips // MinBet of the player whose turn it is after the steps
+		wantCanRaise bool
+	}{
+		{
+			name:   "preflop raise must be at least the big blind",
+			stacks: []Chips{1000, 1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 11, err: "at least 20 chips"},
+				{player: "p3", action: bet, amount: 15, err: "at least 20 chips"},
+				{player: "p3", action: bet, amount: 20},
+			},
+			wantMinBet:   30,
+			wantCanRaise: true,
+		},
+		{
+			name:   "re-raise must be at least the last raise",
+			stacks: []Chips{1000, 1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 40},
+				{player: "p0", action: bet, amount: 60, err: "at least 70 chips"},
+				{player: "p0", action: bet, amount: 70},
+			},
+			wantMinBet:   100,
+			wantCanRaise: true,
+		},
+		{
+			name:   "postflop bet must be at least the big blind",
+			stacks: []Chips{1000, 1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 20},
+				{player: "p0", action: call},
+				{player: "p1", action: call},
+				{player: "p2", action: call},
+				{}, // flop
+				{player: "p1", action: bet, amount: 5, err: "at least the big blind"},
+				{player: "p1", action: bet, amount: 10},
+				{player: "p2", action: bet, amount: 15, err: "at least 20 chips"},
+				{player: "p2", action: bet, amount: 50},
+			},
+			wantMinBet:   90,
+			wantCanRaise: true,
+		},
+		{
+			name:   "allin for less is allowed below the min raise",
+			stacks: []Chips{25, 1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 20},
+				{player: "p0", action: bet, amount: 25},
+			},
+			wantMinBet:   35,
+			wantCanRaise: true,
+		},
+		{
+			name:   "short allin doesn't reopen betting for players that acted",
+			stacks: []Chips{1000, 45, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 30},
+				{player: "p0", action: call},
+				{player: "p1", action: allIn},
+				{player: "p2", action: call},
+				{player: "p3", action: bet, amount: 100, err: "wasn't reopened"},
+				{player: "p3", action: allIn, err: "wasn't reopened"},
+			},
+			wantMinBet:   65,
+			wantCanRaise: false,
+		},
+		{
+			name:   "short allin can still be called",
+			stacks: []Chips{1000, 45, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 30},
+				{player: "p0", action: call},
+				{player: "p1", action: allIn},
+				{player: "p2", action: call},
+				{player: "p3", action: call},
+				{player: "p0", action: bet, amount: 100, err: "wasn't reopened"},
+			},
+			wantMinBet:   65,
+			wantCanRaise: false,
+		},
+		{
+			name:   "players yet to act can raise after a short allin",
+			stacks: []Chips{1000, 45, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 30},
+				{player: "p0", action: call},
+				{player: "p1", action: allIn},
+				{player: "p2", action: bet, amount: 60, err: "at least 65 chips"},
+				{player: "p2", action: bet, amount: 65},
+			},
+			wantMinBet:   85,
+			wantCanRaise: true,
+		},
+		{
+			name:   "full allin raise reopens betting",
+			stacks: []Chips{1000, 60, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 30},
+				{player: "p0", action: call},
+				{player: "p1", action: allIn},
+				{player: "p2", action: call},
+				{player: "p3", action: bet, amount: 80, err: "at least 90 chips"},
+				{player: "p3", action: bet, amount: 90},
+			},
+			wantMinBet:   120,
+			wantCanRaise: true,
+		},
+		{
+			name:   "short allins that add up to a full raise reopen betting",
+			stacks: []Chips{1000, 55, 1000, 1000, 45},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 30},
+				{player: "p4", action: allIn},
+				{player: "p0", action: call},
+				{player: "p1", action: allIn},
+				{player: "p2", action: call},
+				{player: "p3", action: bet, amount: 70, err: "at least 75 chips"},
+				{player: "p3", action: bet, amount: 75},
+			},
+			wantMinBet:   95,
+			wantCanRaise: true,
+		},
+		{
+			name:   "short allins that add up to a full raise only reopen betting for players facing one",
+			stacks: []Chips{1000, 55, 1000, 1000, 45},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 30},
+				{player: "p4", action: allIn},
+				{player: "p0", action: call},
+				{player: "p1", action: allIn},
+				{player: "p2", action: call},
+				{player: "p3", action: call},
+				{player: "p0", action: bet, amount: 100, err: "wasn't reopened"},
+			},
+			wantMinBet:   75,
+			wantCanRaise: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := newTestGame(t, test.stacks...)
+
+			runBettingSteps(t, table, test.steps)
+
+			limits := table.RaiseLimits(table.curPlayer.Player)
+			if limits.MinBet != test.wantMinBet {
+				t.Errorf("MinBet = %d, want %d", limits.MinBet, test.wantMinBet)
+			}
+			if limits.CanRaise != test.wantCanRaise {
+				t.Errorf("CanRaise = %v, want %v", limits.CanRaise, test.wantCanRaise)
+			}
+		})
+	}
+}
+
+func TestRaiseLimits(t *testing.T) {
+	const (
+		bet   = playerState.Bet
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	// NOTE: p0 dealer, p1 small blind (5), p2 big blind (10), p3 first to act
+	callPreflop := []bettingStep{
+		{player: "p3", action: bet, amount: 20},
+		{player: "p0", action: call},
+		{player: "p1", action: call},
+		{player: "p2", action: call},
+		{}, // flop
+	}
+	checkStreet := []bettingStep{
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+		{player: "p3", action: check},
+		{player: "p0", action: check},
+		{},
+	}
+
+	tests := []struct {
+		name    string
+		betting BettingStructure
+		stacks  []Chips
+		steps   []bettingStep
+		want    RaiseLimits // of the player whose turn it is after the steps
+	}{
+		{
+			name:    "pot-limit preflop",
+			betting: BettingPotLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps:   []bettingStep{{player: "p3", action: bet, amount: 36, err: "at most 35 chips"}},
+			want:    RaiseLimits{MinBet: 20, MaxBet: 10 + 15 + 10, CanRaise: true},
+		},
+		{
+			name:    "pot-limit facing a raise",
+			betting: BettingPotLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps:   []bettingStep{{player: "p3", action: bet, amount: 35}},
+			want:    RaiseLimits{MinBet: 60, MaxBet: 35 + 50 + 35, CanRaise: true},
+		},
+		{
+			name:    "pot-limit short stack",
+			betting: BettingPotLimit,
+			stacks:  []Chips{1000, 1000, 1000, 30},
+			want:    RaiseLimits{MinBet: 20, MaxBet: 30, CanRaise: true},
+		},
+		{
+			name:    "pot-limit flop",
+			betting: BettingPotLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps:   callPreflop,
+			want:    RaiseLimits{MinBet: 10, MaxBet: 80, CanRaise: true},
+		},
+		{
+			name:    "fixed-limit preflop is a small bet",
+			betting: BettingFixedLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps:   []bettingStep{{player: "p3", action: bet, amount: 30, err: "only raise to 20 chips"}},
+			want:    RaiseLimits{MinBet: 20, MaxBet: 20, CanRaise: true},
+		},
+		{
+			name:    "fixed-limit flop is a small bet",
+			betting: BettingFixedLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps:   slices.Concat(callPreflop, []bettingStep{{player: "p1", action: bet, amount: 20, err: "only raise to 10 chips"}}),
+			want:    RaiseLimits{MinBet: 10, MaxBet: 10, CanRaise: true},
+		},
+		{
+			name:    "fixed-limit turn is a big bet",
+			betting: BettingFixedLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps:   slices.Concat(callPreflop, checkStreet, []bettingStep{{player: "p1", action: bet, amount: 10, err: "only raise to 20 chips"}}),
+			want:    RaiseLimits{MinBet: 20, MaxBet: 20, CanRaise: true},
+		},
+		{
+			name:    "fixed-limit river is a big bet",
+			betting: BettingFixedLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps: slices.Concat(callPreflop, checkStreet, checkStreet, []bettingStep{
+				{player: "p1", action: bet, amount: 20},
+			}),
+			want: RaiseLimits{MinBet: 40, MaxBet: 40, CanRaise: true},
+		},
+		{
+			name:    "fixed-limit caps the bets per street",
+			betting: BettingFixedLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 20},
+				{player: "p0", action: bet, amount: 30},
+				{player: "p1", action: bet, amount: 40},
+				{player: "p2", action: bet, amount: 50, err: "capped at 4 bets"},
+			},
+			want: RaiseLimits{MinBet: 50, MaxBet: 40, CanRaise: false},
+		},
+		{
+			name:    "fixed-limit cap is per street",
+			betting: BettingFixedLimit,
+			stacks:  []Chips{1000, 1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p3", action: bet, amount: 20},
+				{player: "p0", action: bet, amount: 30},
+				{player: "p1", action: bet, amount: 40},
+				{player: "p2", action: call},
+				{player: "p3", action: call},
+				{player: "p0", action: call},
+				{},
+				{player: "p1", action: bet, amount: 10},
+				{player: "p2", action: bet, amount: 20},
+				{player: "p3", action: bet, amount: 30},
+			},
+			want: RaiseLimits{MinBet: 40, MaxBet: 40, CanRaise: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestGame(t, tt.stacks...)
+			table.Betting = tt.betting
+
+			runBettingSteps(t, table, tt.steps)
+
+			if got := table.RaiseLimits(table.curPlayer.Player); got != tt.want {
+				t.Errorf("RaiseLimits = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+	"github.com/rs/zerolog/log"
+)
+
+// forced bets posted at the start of every hand
+type Blinds struct {
+	SmallBlind Chips `json:"smallBlind"`
+	BigBlind   Chips `json:"bigBlind"`
+	Ante       Chips `json:"ante"` // posted by every player in the hand
+}
+
+// NOTE: this matches the old hard-coded behavior (10 chip big blind, no ante)
+var DefaultBlinds = Blinds{
+	SmallBlind: 5,
+	BigBlind:   10,
+	Ante:       0,
+}
+
+func (blinds Blinds) Validate() error {
+	if blinds.BigBlind == 0 {
+		return errors.New("big blind must be greater than 0")
+	} else if blinds.SmallBlind == 0 {
+		return errors.New("small blind must be greater than 0")
+	} else if blinds.SmallBlind > blinds.BigBlind {
+		return errors.New("small blind can't be larger than the big blind")
+	} else if blinds.Ante > blinds.BigBlind {
+		return errors.New("ante can't be larger than the big blind")
+	}
+
+	return nil
+}
+
+func (blinds Blinds) String() string {
+	if blinds.Ante == 0 {
+		return printer.Sprintf("%d/%d", blinds.SmallBlind, blinds.BigBlind)
+	}
+
+	return printer.Sprintf("%d/%d ante %d", blinds.SmallBlind, blinds.BigBlind, blinds.Ante)
+}
+
+// BaseBlinds returns the blinds the table was configured with. Table.Blinds
+// holds the blinds for the current hand.
+func (table *Table) BaseBlinds() Blinds {
+	return table.baseBlinds
+}
+
+// SetBlinds changes the configured blinds. only allowed between games.
+func (table *Table) SetBlinds(blinds Blinds) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if err := blinds.Validate(); err != nil {
+		return err
+	} else if err := table.validateBigBlind(blinds.BigBlind); err != nil {
+		return err
+	}
+
+	if table.State != TableStateNotStarted {
+		return errors.New("blinds can't be changed while a game is in progress")
+	} else if table.BlindSchedule != nil {
+		return errors.New("blinds are set by the blind schedule")
+	}
+
+	table.baseBlinds = blinds
+	table.Blinds = blinds
+
+	return nil
+}
+
+// the starting stack has to cover the big blind, see SetStartingStack()
+func (table *Table) validateBigBlind(bigBlind Chips) error {
+	if bigBlind > table.StartingStack {
+		return errors.New(printer.Sprintf("big blind can't be larger than the starting stack (%d chips)",
+			table.StartingStack))
+	}
+
+	return nil
+}
+
+func (table *Table) AnteToString() string {
+	if table.Blinds.Ante == 0 {
+		return "none"
+	}
+
+	return printer.Sprintf("%d chips", table.Blinds.Ante)
+}
+
+// collects the antes from every player in the hand, then posts the small
+// and big blinds. there's no small blind when it's dead, see rotatePlayers().
+// antes are dead money: they go into the pots, see potAntes(), and don't
+// count towards a player's bet for the street. stud only has antes, see
+// postBringIn().
+func (table *Table) postBlinds() {
+	table.fullRaises++
+	table.MainPot.Total = 0
+	table.anteAllIns = nil
+
+	if table.isStud() {
+		table.Bet = 0
+		table.betCount = 0 // the bring-in isn't a bet
+		table.lastRaise = 0
+		table.fullBet = 0
+	} else {
+		table.Bet = table.Blinds.BigBlind
+		table.betCount = 1 // the big blind is the first bet
+		table.lastRaise = table.Blinds.BigBlind
+		table.fullBet = table.Blinds.BigBlind
+	}
+
+	if table.Blinds.Ante > 0 {
+		antes := make(map[*Player]Chips)
+
+		for _, player := range table.curPlayers.ToPlayerArray() {
+			ante := min(table.Blinds.Ante, player.ChipCount)
+
+			player.ChipCount -= ante
+			antes[player] = ante
+
+			table.history.post(HistoryPostAnte, player, ante)
+
+			if player.ChipCount == 0 {
+				log.Debug().Str("player", player.Name).Msg("went all in posting ante")
+				player.Action.Action = playerState.AllIn
+				table.anteAllIns = append(table.anteAllIns, player)
+			}
+		}
+
+		table.potAntes(antes)
+
+		log.Debug().
+			Uint64("ante", uint64(table.Blinds.Ante)).
+			Uint64("mainpot", uint64(table.MainPot.Total)).
+			Msg("antes posted")
+	}
+
+	if table.isStud() {
+		return
+	}
+
+	bigBlind := table.BigBlind.Player
+
+	bigBlind.Action.Amount = min(table.Blinds.BigBlind, bigBlind.ChipCount)
+	bigBlind.ChipCount -= bigBlind.Action.Amount
+	if bigBlind.ChipCount == 0 {
+		bigBlind.Action.Action = playerState.AllIn
+	}
+
+	table.MainPot.Total += bigBlind.Action.Amount
+
+	var smallBlind *Player
+	if table.SmallBlind != nil { // NOTE: nil when the small blind is dead
+		smallBlind = table.SmallBlind.Player
+
+		smallBlind.Action.Amount = min(table.Blinds.SmallBlind, smallBlind.ChipCount)
+		smallBlind.ChipCount -= smallBlind.Action.Amount
+		if smallBlind.ChipCount == 0 {
+			smallBlind.Action.Action = playerState.AllIn
+		}
+
+		table.MainPot.Total += smallBlind.Action.Amount
+
+		table.history.post(HistoryPostSmallBlind, smallBlind, smallBlind.Action.Amount)
+	}
+	table.history.post(HistoryPostBigBlind, bigBlind, bigBlind.Action.Amount)
+
+	// players back from sitting out post the big blind they missed. it's a
+	// live bet, just like the big blind's.
+	for _, player := range table.curPlayers.ToPlayerArray() {
+		if !player.owesBlind {
+			continue
+		}
+
+		player.owesBlind = false
+
+		if player == smallBlind || player == bigBlind {
+			continue
+		}
+
+		player.Action.Amount = min(table.Blinds.BigBlind, player.ChipCount)
+		player.ChipCount -= player.Action.Amount
+		if player.ChipCount == 0 {
+			player.Action.Action = playerState.AllIn
+		}
+
+		table.MainPot.Total += player.Action.Amount
+
+		table.history.post(HistoryPostBigBlind, player, player.Action.Amount)
+
+		log.Debug().Str("player", player.Name).Msg("posted missed blind")
+	}
+}
+
+// puts the antes into the pots. a player that went all in posting the ante
+// can only win the antes they matched: the antes are split into an ante pot
+// for each amount players went all in for, like sidepots, and only the rest
+// goes into the mainpot. those players are left out of the mainpot and every
+// other pot, see FinishRound().
+func (table *Table) potAntes(antes map[*Player]Chips) {
+	levels := make([]Chips, 0, len(table.anteAllIns))
+	for _, player := range table.anteAllIns {
+		levels = append(levels, antes[player])
+	}
+	slices.Sort(levels)
+	levels = slices.Compact(levels)
+
+	var prevLevel Chips
+	for _, level := range levels {
+		antePot := NewSidePot(level).WithName(printer.Sprintf("%d ante pot", level))
+
+		for player, ante := range antes {
+			if ante > prevLevel {
+				antePot.Total += min(ante, level) - prevLevel
+			}
+			if ante >= level {
+				antePot.AddPlayer(player)
+			}
+		}
+
+		log.Debug().
+			Str("pot", antePot.Name).
+			Uint64("total", uint64(antePot.Total)).
+			Str("playerInfo", antePot.PlayerInfo()).
+			Msg("ante pot")
+
+		table.sidePots.AntePots.Add(antePot)
+		prevLevel = level
+	}
+
+	for _, ante := range antes {
+		if ante > prevLevel {
+			table.MainPot.Total += ante - prevLevel
+		}
+	}
+}
+
+// a single level of a BlindSchedule. a level lasts either Minutes or Hands;
+// the last level of a schedule may leave both at 0 to last forever.
+type BlindLevel struct {
+	Blinds
+
+	Minutes uint64 // length of level in minutes
+	Hands   uint64 // length of level in hands played
+}
+
+func (level BlindLevel) IsTimed() bool {
+	return level.Minutes != 0
+}
+
+func (level BlindLevel) Duration() time.Duration {
+	return time.Duration(level.Minutes) * time.Minute
+}
+
+// increasing blinds for tournament-style games. the current level is
+// advanced by the number of hands played (see Table.NewRound) or by a timer
+// owned by the frontend (see Table.AdvanceBlindLevel).
+type BlindSchedule struct {
+	Levels []BlindLevel
+	Level  int // index of the current level
+
+	LevelStart  time.Time     // when the current level started
+	LevelHands  uint64        // hands played at the current level
+	NextLevelIn time.Duration // NOTE: only filled in by Table.PublicInfo()
+}
+
+func NewBlindSchedule(levels []BlindLevel) (*BlindSchedule, error) {
+	if len(levels) == 0 {
+		return nil, errors.New("blind schedule needs at least one level")
+	}
+
+	for i, level := range levels {
+		if err := level.Validate(); err != nil {
+			return nil, fmt.Errorf("level %d: %w", i+1, err)
+		}
+
+		if level.Minutes != 0 && level.Hands != 0 {
+			return nil, fmt.Errorf("level %d: a level can last a number of minutes or hands, not both", i+1)
+		} else if level.Minutes == 0 && level.Hands == 0 && i != len(levels)-1 {
+			return nil, fmt.Errorf("level %d: only the last level can have no length", i+1)
+		}
+	}
+
+	return &BlindSchedule{
+		Levels: append([]BlindLevel(nil), levels...),
+	}, nil
+}
+
+func (schedule *BlindSchedule) Current() BlindLevel {
+	return schedule.Levels[schedule.Level]
+}
+
+func (schedule *BlindSchedule) IsLastLevel() bool {
+	return schedule.Level == len(schedule.Levels)-1
+}
+
+// start the current level over at time now
+func (schedule *BlindSchedule) startLevel(now time.Time) {
+	schedule.LevelStart = now
+	schedule.LevelHands = 0
+}
+
+func (schedule *BlindSchedule) reset(now time.Time) {
+	schedule.Level = 0
+	schedule.startLevel(now)
+}
+
+// moves the schedule to the next level. returns false if the schedule
+// was already at the last level.
+func (schedule *BlindSchedule) advance(now time.Time) bool {
+	if schedule.IsLastLevel() {
+		return false
+	}
+
+	schedule.Level++
+	schedule.startLevel(now)
+
+	return true
+}
+
+// TimeLeft returns the time until the next level. ok is false when the current
+// level isn't timed or is the last level.
+func (schedule *BlindSchedule) TimeLeft(now time.Time) (timeLeft time.Duration, ok bool) {
+	level := schedule.Current()
+	if !level.IsTimed() || schedule.IsLastLevel() {
+		return 0, false
+	}
+
+	return max(schedule.LevelStart.Add(level.Duration()).Sub(now), 0), true
+}
+
+func (schedule *BlindSchedule) String() string {
+	level := schedule.Current()
+
+	str := printer.Sprintf("level %d: %s", schedule.Level+1, level.Blinds.String())
+
+	if schedule.IsLastLevel() {
+		return str + " (final level)"
+	}
+
+	if timeLeft, ok := schedule.TimeLeft(time.Now()); ok {
+		return str + fmt.Sprintf(" (next level in %s)", timeLeft.Round(time.Second))
+	}
+
+	return str + printer.Sprintf(" (next level in %d hands)", level.Hands-schedule.LevelHands)
+}
+
+// SetBlindSchedule sets the blinds schedule used in the next game. a nil
+// schedule means the blinds stay fixed. only allowed between games.
+func (table *Table) SetBlindSchedule(schedule *BlindSchedule) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if table.State != TableStateNotStarted {
+		return errors.New("the blind schedule can't be changed while a game is in progress")
+	} else if schedule != nil {
+		if err := table.validateBigBlind(schedule.Levels[0].BigBlind); err != nil {
+			return fmt.Errorf("level 1: %w", err)
+		}
+	}
+
+	table.BlindSchedule = schedule
+
+	if schedule != nil {
+		schedule.reset(time.Now())
+
+		table.baseBlinds = schedule.Current().Blinds
+		table.Blinds = table.baseBlinds
+	}
+
+	return nil
+}
+
+// StartBlindSchedule restarts the blind schedule at the first level.
+// should be called when a game starts.
+func (table *Table) StartBlindSchedule(now time.Time) {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if table.BlindSchedule == nil {
+		return
+	}
+
+	table.BlindSchedule.reset(now)
+	table.Blinds = table.BlindSchedule.Current().Blinds
+}
+
+// AdvanceBlindLevel moves the blind schedule to the next level. the new
+// blinds take effect at the start of the next hand. returns false if there
+// is no schedule or it is already at the last level.
+func (table *Table) AdvanceBlindLevel(now time.Time) bool {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if table.BlindSchedule == nil {
+		return false
+	}
+
+	if !table.BlindSchedule.advance(now) {
+		return false
+	}
+
+	log.Info().Str("blindLevel", table.BlindSchedule.String()).Msg("blind level increased")
+
+	return true
+}
+
+// called at the start of each new hand
+func (table *Table) updateBlindLevel() {
+	schedule := table.BlindSchedule
+	if schedule == nil {
+		return
+	}
+
+	schedule.LevelHands++
+
+	if level := schedule.Current(); level.Hands != 0 && schedule.LevelHands >= level.Hands {
+		if schedule.advance(time.Now()) {
+			log.Info().Str("blindLevel", schedule.String()).Msg("blind level increased")
+		}
+	}
+
+	table.Blinds = schedule.Current().Blinds
+}
@@ -0,0 +1,344 @@
+package poker
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+// takes the players that went all in posting the blinds or antes out of the
+// betting, the same way the frontend does after the deal
+func removeForcedAllIns(table *Table) {
+	for _, player := range table.curPlayers.ToPlayerArray() {
+		if player.Action.Action != playerState.AllIn {
+			continue
+		}
+
+		if table.curPlayer.Player == player {
+			table.SetNextPlayerTurn()
+		} else {
+			table.curPlayers.RemovePlayer(player)
+		}
+	}
+}
+
+func TestAntes(t *testing.T) {
+	blinds := Blinds{SmallBlind: 5, BigBlind: 10, Ante: 2}
+	table := newVariantTestGame(t, Holdem{}, NewDeck(nil), blinds, 1000, 1000, 1000)
+
+	players := testPlayersByName(table)
+
+	for name, want := range map[string]Chips{"p0": 998, "p1": 993, "p2": 988} {
+		if got := players[name].ChipCount; got != want {
+			t.Errorf("%s has %d chips after the antes and blinds, want %d", name, got, want)
+		}
+	}
+	if table.MainPot.Total != 3*2+5+10 {
+		t.Errorf("mainpot has %d chips, want 21", table.MainPot.Total)
+	}
+
+	// antes are dead money: they don't count towards the bet to call
+	if table.Bet != 10 || players["p2"].Action.Amount != 10 {
+		t.Errorf("bet is %d and the big blind put in %d, want 10", table.Bet, players["p2"].Action.Amount)
+	}
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Bet, amount: 20},
+		{player: "p1", action: playerState.Call},
+	})
+
+	if got := players["p1"].ChipCount; got != 1000-2-20 {
+		t.Errorf("small blind has %d chips after calling 20, want 978", got)
+	}
+	if !table.sidePots.AntePots.IsEmpty() {
+		t.Errorf("ante pots without a player all in on the ante")
+	}
+}
+
+func TestShortAntePots(t *testing.T) {
+	// NOTE: p0 dealer, p1 small blind, p2 big blind
+	tests := []struct {
+		name     string
+		stacks   []Chips
+		antePots []Chips    // totals, smallest first
+		players  [][]string // players in each ante pot
+		mainPot  Chips
+	}{
+		{
+			name:    "full antes",
+			stacks:  []Chips{1000, 1000, 1000},
+			mainPot: 3*10 + 15,
+		},
+		{
+			name:     "all in for the ante",
+			stacks:   []Chips{10, 1000, 1000},
+			antePots: []Chips{3 * 10},
+			players:  [][]string{{"p0", "p1", "p2"}},
+			mainPot:  15,
+		},
+		{
+			name:     "short antes",
+			stacks:   []Chips{3, 1000, 1000, 6},
+			antePots: []Chips{4 * 3, 3 * 3},
+			players:  [][]string{{"p0", "p1", "p2", "p3"}, {"p1", "p2", "p3"}},
+			mainPot:  2*(10-6) + 15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blinds := Blinds{SmallBlind: 5, BigBlind: 10, Ante: 10}
+			table := newVariantTestGame(t, Holdem{}, NewDeck(nil), blinds, tt.stacks...)
+
+			antePots := table.sidePots.AntePots.Pots
+			if len(antePots) != len(tt.antePots) {
+				t.Fatalf("%d ante pots, want %d", len(antePots), len(tt.antePots))
+			}
+
+			for i, antePot := range antePots {
+				players := slices.Sorted(maps.Keys(antePot.Players))
+				if antePot.Total != tt.antePots[i] || !slices.Equal(players, tt.players[i]) {
+					t.Errorf("ante pot #%d has %d chips for %v, want %d for %v", i+1,
+						antePot.Total, players, tt.antePots[i], tt.players[i])
+				}
+			}
+			if table.MainPot.Total != tt.mainPot {
+				t.Errorf("mainpot has %d chips, want %d", table.MainPot.Total, tt.mainPot)
+			}
+		})
+	}
+}
+
+// a player all in on a short ante only wins the antes they matched
+func TestShortAnteShowdown(t *testing.T) {
+	const (
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	// NOTE: p0 has aces, p2 has the best hand of the others
+	deck := stackedDeck(t, "Ac Ad 2c 7d 3h 8s 2s Kh Qs 9c 4s 5d Jh 4h")
+	blinds := Blinds{SmallBlind: 5, BigBlind: 10, Ante: 10}
+	table := newVariantTestGame(t, Holdem{}, deck, blinds, 5, 1000, 1000)
+
+	removeForcedAllIns(table)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p1", action: call},
+		{player: "p2", action: check},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+	})
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	players := testPlayersByName(table)
+	for name, want := range map[string]Chips{"p0": 3 * 5, "p1": 1000 - 20, "p2": 1000 + 10} {
+		if got := players[name].ChipCount; got != want {
+			t.Errorf("%s has %d chips, want %d", name, got, want)
+		}
+	}
+}
+
+func TestNewBlindSchedule(t *testing.T) {
+	blinds := Blinds{SmallBlind: 5, BigBlind: 10}
+
+	tests := []struct {
+		name   string
+		levels []BlindLevel
+		err    string // empty means the schedule is valid
+	}{
+		{
+			name: "no levels",
+			err:  "at least one level",
+		},
+		{
+			name:   "invalid blinds",
+			levels: []BlindLevel{{Blinds: blinds, Hands: 10}, {Blinds: Blinds{SmallBlind: 20, BigBlind: 10}}},
+			err:    "level 2: small blind can't be larger",
+		},
+		{
+			name:   "minutes and hands",
+			levels: []BlindLevel{{Blinds: blinds, Minutes: 10, Hands: 10}},
+			err:    "level 1: a level can last a number of minutes or hands",
+		},
+		{
+			name:   "level with no length before the last",
+			levels: []BlindLevel{{Blinds: blinds}, {Blinds: blinds, Hands: 10}},
+			err:    "level 1: only the last level",
+		},
+		{
+			name:   "single level",
+			levels: []BlindLevel{{Blinds: blinds}},
+		},
+		{
+			name:   "hands then minutes",
+			levels: []BlindLevel{{Blinds: blinds, Hands: 10}, {Blinds: blinds, Minutes: 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		schedule, err := NewBlindSchedule(tt.levels)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+		case err == nil && len(schedule.Levels) != len(tt.levels):
+			t.Errorf("%s: schedule has %d levels, want %d", tt.name, len(schedule.Levels), len(tt.levels))
+		}
+	}
+}
+
+// starts a blind schedule on a table that already dealt its first hand
+func startTestBlindSchedule(t *testing.T, table *Table, levels ...BlindLevel) *BlindSchedule {
+	t.Helper()
+
+	schedule, err := NewBlindSchedule(levels)
+	if err != nil {
+		t.Fatalf("NewBlindSchedule: %v", err)
+	}
+
+	table.BlindSchedule = schedule
+	table.StartBlindSchedule(time.Now())
+
+	return schedule
+}
+
+func TestBlindScheduleByHands(t *testing.T) {
+	table := newUndealtTestGame(t, Holdem{}, NewDeck(nil), DefaultBlinds, testStacks(3)...)
+	schedule := startTestBlindSchedule(t, table,
+		BlindLevel{Blinds: Blinds{SmallBlind: 5, BigBlind: 10}, Hands: 2},
+		BlindLevel{Blinds: Blinds{SmallBlind: 10, BigBlind: 20}, Hands: 3},
+		BlindLevel{Blinds: Blinds{SmallBlind: 20, BigBlind: 40, Ante: 5}},
+	)
+
+	// the level each hand is played at: every level lasts its hands and the
+	// last level lasts forever
+	want := []int{0, 0, 1, 1, 1, 2, 2, 2, 2, 2}
+
+	for hand, wantLevel := range want {
+		// NOTE: the first hand is dealt like the server starts a game
+		if hand == 0 {
+			table.NextTableAction()
+		} else {
+			nextHand(t, table)
+		}
+
+		wantBlinds := schedule.Levels[wantLevel].Blinds
+		if schedule.Level != wantLevel || table.Blinds != wantBlinds {
+			t.Fatalf("hand %d: level %d with blinds %s, want level %d", hand+1, schedule.Level+1, table.Blinds, wantLevel+1)
+		}
+		if got := table.BigBlind.Player.Action.Amount; got != wantBlinds.BigBlind {
+			t.Fatalf("hand %d: big blind posted %d chips, want %d", hand+1, got, wantBlinds.BigBlind)
+		}
+	}
+
+	if _, ok := schedule.TimeLeft(time.Now()); ok {
+		t.Errorf("the last level has a time left")
+	}
+}
+
+func TestBlindScheduleByMinutes(t *testing.T) {
+	table := newTestGame(t, testStacks(3)...)
+
+	if table.AdvanceBlindLevel(time.Now()) {
+		t.Errorf("advanced the blinds without a blind schedule")
+	}
+
+	schedule := startTestBlindSchedule(t, table,
+		BlindLevel{Blinds: Blinds{SmallBlind: 5, BigBlind: 10}, Minutes: 10},
+		BlindLevel{Blinds: Blinds{SmallBlind: 10, BigBlind: 20}, Minutes: 10},
+		BlindLevel{Blinds: Blinds{SmallBlind: 20, BigBlind: 40}},
+	)
+
+	// hands played don't end a timed level
+	for range 5 {
+		nextHand(t, table)
+	}
+	if schedule.Level != 0 {
+		t.Fatalf("timed level advanced after 5 hands")
+	}
+
+	start := schedule.LevelStart
+	if timeLeft, ok := schedule.TimeLeft(start.Add(4 * time.Minute)); !ok || timeLeft != 6*time.Minute {
+		t.Errorf("%s left after 4 minutes, want 6m", timeLeft)
+	}
+	if timeLeft, _ := schedule.TimeLeft(start.Add(time.Hour)); timeLeft != 0 {
+		t.Errorf("%s left after the level ended", timeLeft)
+	}
+
+	now := start.Add(10 * time.Minute)
+	if !table.AdvanceBlindLevel(now) {
+		t.Fatalf("AdvanceBlindLevel: didn't advance the first level")
+	}
+	if schedule.Level != 1 || !schedule.LevelStart.Equal(now) || schedule.LevelHands != 0 {
+		t.Errorf("level %d started at %s after %d hands", schedule.Level+1, schedule.LevelStart, schedule.LevelHands)
+	}
+
+	// the new blinds start with the next hand
+	if table.Blinds.BigBlind != 10 {
+		t.Errorf("blinds changed to %s mid-hand", table.Blinds)
+	}
+	nextHand(t, table)
+	if table.Blinds.BigBlind != 20 {
+		t.Errorf("blinds are %s on the next hand, want 10/20", table.Blinds)
+	}
+
+	if !table.AdvanceBlindLevel(now.Add(10 * time.Minute)) {
+		t.Fatalf("AdvanceBlindLevel: didn't advance the second level")
+	}
+	if table.AdvanceBlindLevel(now.Add(time.Hour)) || !schedule.IsLastLevel() {
+		t.Errorf("advanced past the last level")
+	}
+	if _, ok := schedule.TimeLeft(now.Add(time.Hour)); ok {
+		t.Errorf("the last level has a time left")
+	}
+}
+
+// the starting stack has to cover the big blind, whichever is set first
+func TestBlindsAndStartingStack(t *testing.T) {
+	table, err := NewTable(NewDeck(nil), Holdem{}, 2, TableLockNone, "", make([]bool, 2))
+	if err != nil {
+		t.Fatalf("NewTable: %v", err)
+	}
+
+	if err := table.SetStartingStack(100); err != nil {
+		t.Fatalf("SetStartingStack: %v", err)
+	}
+	if err := table.SetBlinds(Blinds{SmallBlind: 100, BigBlind: 200}); err == nil ||
+		!strings.Contains(err.Error(), "larger than the starting stack") {
+		t.Errorf("set a big blind larger than the starting stack: %v", err)
+	}
+	if err := table.SetBlinds(Blinds{SmallBlind: 50, BigBlind: 100}); err != nil {
+		t.Errorf("SetBlinds: %v", err)
+	}
+	if err := table.SetStartingStack(99); err == nil {
+		t.Errorf("set a starting stack smaller than the big blind")
+	}
+
+	schedule, err := NewBlindSchedule([]BlindLevel{{Blinds: Blinds{SmallBlind: 100, BigBlind: 200}}})
+	if err != nil {
+		t.Fatalf("NewBlindSchedule: %v", err)
+	}
+	if err := table.SetBlindSchedule(schedule); err == nil {
+		t.Errorf("set a blind schedule starting above the starting stack")
+	}
+
+	if table.StartingStack != 100 || table.BaseBlinds().BigBlind != 100 || table.BlindSchedule != nil {
+		t.Errorf("starting stack %d with blinds %s after the errors", table.StartingStack, table.BaseBlinds())
+	}
+}
@@ -1,8 +1,12 @@
 package poker
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
@@ -40,6 +44,7 @@ const (
 	CardQueen
 	CardKing
 	CardAce
+	CardJoker // wild, see JokerCards()
 )
 
 // suits
@@ -70,28 +75,68 @@ type Hole struct {
 	Cards            Cards
 }
 
+// NOTE: with more than two hole cards (omaha) IsPair and IsSuited are set
+// if any two of the cards match.
 func (hole *Hole) FillHoleInfo() {
-	var (
-		cardOne = hole.Cards[0]
-		cardTwo = hole.Cards[1]
-	)
+	hole.IsPair, hole.IsSuited = false, false
+	hole.Suit, hole.CombinedNumValue = 0, 0
 
-	if cardOne.NumValue == cardTwo.NumValue {
-		hole.IsPair = true
+	for i, card := range hole.Cards {
+		for _, otherCard := range hole.Cards[i+1:] {
+			if card.NumValue == otherCard.NumValue {
+				hole.IsPair = true
+			}
+
+			if card.Suit == otherCard.Suit && !hole.IsSuited {
+				hole.IsSuited = true
+				hole.Suit = card.Suit
+			}
+		}
+
+		hole.CombinedNumValue += uint16(card.NumValue)
 	}
+}
+
+type Hand struct {
+	Rank   Rank  `json:"rank"`
+	Kicker uint8 `json:"kicker"`
+	Cards  Cards `json:"cards"`
+}
+
+// Compare returns a positive number if hand beats otherHand, a negative number
+// if it loses and 0 on a tie. hands are compared by rank, then card by card
+// from the highest ranked card down.
+func (hand *Hand) Compare(otherHand *Hand) int {
+	return standardHandRules.compare(hand, otherHand)
+}
+
+// differences from the standard hand rankings
+type handRules struct {
+	wheelLowCard        CardVal // lowest card of the ace-low straight
+	flushBeatsFullHouse bool
+}
 
-	if cardOne.Suit == cardTwo.Suit {
-		hole.IsSuited = true
-		hole.Suit = cardOne.Suit
+var (
+	standardHandRules  = handRules{wheelLowCard: CardTwo}
+	shortDeckHandRules = handRules{wheelLowCard: CardSix, flushBeatsFullHouse: true}
+)
+
+// the value of rank when comparing hands
+func (rules handRules) rankValue(rank Rank) int {
+	if rules.flushBeatsFullHouse {
+		switch rank {
+		case RankFlush:
+			return int(RankFullHouse)
+		case RankFullHouse:
+			return int(RankFlush)
+		}
 	}
 
-	hole.CombinedNumValue = uint16(cardOne.NumValue + cardTwo.NumValue)
+	return int(rank)
 }
 
-type Hand struct {
-	Rank   Rank
-	Kicker uint8
-	Cards  Cards
+func (rules handRules) compare(hand, otherHand *Hand) int {
+	return cmp.Compare(rules.assembledValue(hand), rules.assembledValue(otherHand))
 }
 
 func (hand *Hand) RankName() string {
@@ -134,7 +179,110 @@ func reverseCards(cards Cards) Cards {
 	return reversed
 }
 
+var (
+	cardValueCodes = "A23456789TJQKA" // indexed by CardVal - 1
+	cardSuitCodes  = "cdhs"           // indexed by Suit - 1
+)
+
+// Code returns the card's short code: its value and the first letter of its
+// suit, e.g. "Ah" or "Td". a joker is "X".
+func (card *Card) Code() string {
+	if card.NumValue == CardJoker {
+		return "X"
+	}
+
+	code := cardValueCode(card.NumValue)
+	if card.Suit >= SuitClub && card.Suit <= SuitSpade {
+		code += string(cardSuitCodes[card.Suit-1])
+	}
+
+	return code
+}
+
+func cardValueCode(value CardVal) string {
+	if value == CardJoker {
+		return "X"
+	} else if value < CardAceLow || value > CardAce {
+		return "?"
+	}
+
+	return string(cardValueCodes[value-1])
+}
+
+// ParseCard parses a card's short code, see Card.Code()
+func ParseCard(code string) (*Card, error) {
+	if code == "X" {
+		card := &Card{NumValue: CardJoker}
+
+		return card, cardNumToString(card)
+	}
+
+	if len(code) != 2 {
+		return nil, fmt.Errorf("invalid card '%s'", code)
+	}
+
+	// NOTE: skips the low ace at the start of cardValueCodes
+	value := strings.IndexByte(cardValueCodes[1:], code[0])
+	suit := strings.IndexByte(cardSuitCodes, code[1])
+	if value == -1 || suit == -1 {
+		return nil, fmt.Errorf("invalid card '%s'", code)
+	}
+
+	card := &Card{NumValue: CardVal(value + CardTwo), Suit: Suit(suit + 1)}
+
+	return card, cardNumToString(card)
+}
+
+// ParseCards parses space separated card codes, e.g. "Ah Td 9c"
+func ParseCards(codes string) (Cards, error) {
+	var cards Cards
+	for _, code := range strings.Fields(codes) {
+		card, err := ParseCard(code)
+		if err != nil {
+			return nil, err
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// MarshalJSON writes the cards as a list of their codes, e.g. ["Ah","Td"]
+func (cards Cards) MarshalJSON() ([]byte, error) {
+	codes := make([]string, len(cards))
+	for i, card := range cards {
+		codes[i] = card.Code()
+	}
+
+	return json.Marshal(codes)
+}
+
+func (cards *Cards) UnmarshalJSON(data []byte) error {
+	var codes []string
+	if err := json.Unmarshal(data, &codes); err != nil {
+		return err
+	}
+
+	*cards = nil
+	for _, code := range codes {
+		card, err := ParseCard(code)
+		if err != nil {
+			return err
+		}
+
+		*cards = append(*cards, card)
+	}
+
+	return nil
+}
+
 func cardNumToString(card *Card) error {
+	if card.NumValue == CardJoker {
+		card.Name, card.FullName = "Joker", "joker"
+		return nil
+	}
+
 	cardNumStringMap := map[CardVal]string{
 		CardTwo:   "2",
 		CardThree: "3",
@@ -0,0 +1,74 @@
+package poker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultTurnTime = 30 * time.Second
+	MinTurnTime     = 5 * time.Second
+	MaxTurnTime     = 10 * time.Minute
+	MaxTimeBank     = 10 * time.Minute
+
+	// number of turns in a row a player can run out of time on before they
+	// are marked as sitting out
+	MaxTimeouts = 3
+)
+
+// SetTurnClock changes how long players have to act on their turn. timeBank
+// is extra time each player can dip into over the course of a game. only
+// allowed between games.
+func (table *Table) SetTurnClock(turnTime, timeBank time.Duration) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if turnTime < MinTurnTime || turnTime > MaxTurnTime {
+		return errors.New("turn time must be between " + MinTurnTime.String() + " and " + MaxTurnTime.String())
+	} else if timeBank > MaxTimeBank {
+		return errors.New("time bank can't be longer than " + MaxTimeBank.String())
+	} else if table.State != TableStateNotStarted {
+		return errors.New("the turn clock can't be changed while a game is in progress")
+	}
+
+	table.TurnTime, table.TimeBank = turnTime, timeBank
+
+	for _, player := range table.players {
+		player.TimeBank = timeBank
+	}
+
+	return nil
+}
+
+// PlayerTimeout is called when player has run out of time on their turn.
+// the player checks if they owe nothing, otherwise they fold. a player that
+// times out MaxTimeouts turns in a row is marked as sitting out.
+func (table *Table) PlayerTimeout(player *Player) (playerState.PlayerState, error) {
+	action := playerState.Check
+
+	// NOTE: PlayerAction() lets some players check what they owe, e.g. the
+	//       big blind preflop, so we can't just try a check first
+	if player.Action.Amount < table.Bet {
+		action = playerState.Fold
+	}
+
+	if err := table.PlayerAction(player, Action{Action: action}); err != nil {
+		return 0, err
+	}
+
+	player.timeouts++
+	if player.timeouts >= MaxTimeouts && !player.IsSittingOut {
+		log.Info().Str("player", player.Name).Msg("timed out too many times, sitting out")
+		player.IsSittingOut = true
+	}
+
+	return action, nil
+}
+
+// called when a player acts on their own
+func (player *Player) ResetTimeouts() {
+	player.timeouts = 0
+}
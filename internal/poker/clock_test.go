@@ -0,0 +1,187 @@
+package poker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func TestPlayerTimeout(t *testing.T) {
+	const (
+		bet   = playerState.Bet
+		call  = playerState.Call
+		check = playerState.Check
+		fold  = playerState.Fold
+	)
+
+	// NOTE: 3 seats: p0 dealer, p1 small blind, p2 big blind. heads up: p0
+	// dealer and small blind, p1 big blind
+	tests := []struct {
+		name       string
+		stacks     []Chips
+		steps      []bettingStep
+		timeout    string // player that runs out of time
+		wantAction playerState.PlayerState
+	}{
+		{
+			name:       "facing the big blind",
+			stacks:     []Chips{1000, 1000, 1000},
+			timeout:    "p0",
+			wantAction: fold,
+		},
+		{
+			name:       "small blind facing the big blind",
+			stacks:     []Chips{1000, 1000, 1000},
+			steps:      []bettingStep{{player: "p0", action: fold}},
+			timeout:    "p1",
+			wantAction: fold,
+		},
+		{
+			name:       "big blind option",
+			stacks:     []Chips{1000, 1000},
+			steps:      []bettingStep{{player: "p0", action: call}},
+			timeout:    "p1",
+			wantAction: check,
+		},
+		{
+			name:   "facing a raise",
+			stacks: []Chips{1000, 1000, 1000},
+			steps: []bettingStep{
+				{player: "p0", action: bet, amount: 40},
+				{player: "p1", action: fold},
+			},
+			timeout:    "p2",
+			wantAction: fold,
+		},
+		{
+			name:   "first to act on the flop",
+			stacks: []Chips{1000, 1000},
+			steps: []bettingStep{
+				{player: "p0", action: call},
+				{player: "p1", action: check},
+				{},
+			},
+			timeout:    "p1",
+			wantAction: check,
+		},
+		{
+			name:   "facing a bet on the flop",
+			stacks: []Chips{1000, 1000},
+			steps: []bettingStep{
+				{player: "p0", action: call},
+				{player: "p1", action: check},
+				{},
+				{player: "p1", action: bet, amount: 20},
+			},
+			timeout:    "p0",
+			wantAction: fold,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestGame(t, tt.stacks...)
+			runBettingSteps(t, table, tt.steps)
+
+			player := table.curPlayer.Player
+			if player.Name != tt.timeout {
+				t.Fatalf("expected it to be %s's turn, got %s", tt.timeout, player.Name)
+			}
+
+			chips := player.ChipCount
+
+			action, err := table.PlayerTimeout(player)
+			if err != nil {
+				t.Fatalf("PlayerTimeout: %v", err)
+			}
+			if action != tt.wantAction || player.Action.Action != tt.wantAction {
+				t.Errorf("timed out player %s, want %v", player.ActionToString(), tt.wantAction)
+			}
+			if player.ChipCount != chips {
+				t.Errorf("timed out player paid %d chips", chips-player.ChipCount)
+			}
+		})
+	}
+}
+
+func TestPlayerTimeoutStraddleOption(t *testing.T) {
+	table := newStraddleTestGame(t, 1000, 1000, 1000, 1000)
+
+	// NOTE: p0 straddles, p1 is on the button
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p1", action: playerState.Call},
+		{player: "p2", action: playerState.Call},
+		{player: "p3", action: playerState.Call},
+	})
+
+	player := table.curPlayer.Player
+
+	action, err := table.PlayerTimeout(player)
+	if err != nil {
+		t.Fatalf("PlayerTimeout: %v", err)
+	}
+	if action != playerState.Check {
+		t.Errorf("straddler with the option %s", player.ActionToString())
+	}
+}
+
+func TestPlayerTimeoutSitsOut(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+	player := table.curPlayer.Player
+
+	player.timeouts = 1
+	player.ResetTimeouts()
+	if player.timeouts != 0 {
+		t.Fatalf("ResetTimeouts: %d timeouts left", player.timeouts)
+	}
+
+	player.timeouts = MaxTimeouts - 1
+	if _, err := table.PlayerTimeout(player); err != nil {
+		t.Fatalf("PlayerTimeout: %v", err)
+	}
+	if !player.IsSittingOut {
+		t.Errorf("player isn't sitting out after %d timeouts", MaxTimeouts)
+	}
+}
+
+func TestSetTurnClock(t *testing.T) {
+	table := newVariantTestGame(t, Holdem{}, NewDeck(nil), DefaultBlinds, 1000, 1000)
+
+	if err := table.SetTurnClock(time.Minute, time.Minute); err == nil {
+		t.Errorf("changed the turn clock during a game")
+	}
+
+	table.State = TableStateNotStarted
+
+	tests := []struct {
+		name               string
+		turnTime, timeBank time.Duration
+		wantErr            bool
+	}{
+		{"turn time too short", MinTurnTime - time.Second, 0, true},
+		{"turn time too long", MaxTurnTime + time.Second, 0, true},
+		{"time bank too long", DefaultTurnTime, MaxTimeBank + time.Second, true},
+		{"no time bank", MinTurnTime, 0, false},
+		{"time bank", MaxTurnTime, MaxTimeBank, false},
+	}
+
+	for _, tt := range tests {
+		err := table.SetTurnClock(tt.turnTime, tt.timeBank)
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("%s: got error %v", tt.name, err)
+			continue
+		} else if tt.wantErr {
+			continue
+		}
+
+		if table.TurnTime != tt.turnTime || table.TimeBank != tt.timeBank {
+			t.Errorf("%s: turn clock is %s + %s", tt.name, table.TurnTime, table.TimeBank)
+		}
+		for _, player := range table.curPlayers.ToPlayerArray() {
+			if player.TimeBank != tt.timeBank {
+				t.Errorf("%s: %s has a %s time bank", tt.name, player.Name, player.TimeBank)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package poker
+
+import "errors"
+
+// SetCommitReveal turns commit-reveal mode on or off and reshuffles the deck
+// for the first hand. in commit-reveal mode the order of every shuffle is
+// committed to with DeckCommitment() before the cards are dealt and the seed
+// behind it is revealed with DeckSeed() once the hand is over, so players
+// can check with VerifyShuffle() that the deck was set before the deal. only
+// allowed between games.
+func (table *Table) SetCommitReveal(on bool) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if table.State != TableStateNotStarted {
+		return errors.New("commit-reveal can't be changed while a game is in progress")
+	}
+
+	table.CommitReveal = on
+	table.deck.SetCommitReveal(on)
+	table.deck.Shuffle()
+
+	return nil
+}
+
+// DeckCommitment returns the hex SHA-256 of the deck order for the current
+// hand. empty when the table isn't in commit-reveal mode.
+func (table *Table) DeckCommitment() string {
+	return table.deck.Commitment()
+}
+
+// DeckSeed returns the hex seed the current hand was shuffled with. the seed
+// is only revealed after the hand is over.
+func (table *Table) DeckSeed() (string, error) {
+	if !table.CommitReveal {
+		return "", errors.New("the table isn't in commit-reveal mode")
+	} else if table.State != TableStateShowHands && table.State != TableStateSplitPot &&
+		table.State != TableStateRoundOver && table.State != TableStateGameOver {
+		return "", errors.New("the seed can't be revealed before the hand is over")
+	}
+
+	return table.deck.Seed(), nil
+}
@@ -3,7 +3,6 @@ package poker
 import (
 	"fmt"
 
-	"github.com/bkazemi/gopoker/internal/playerState"
 	"github.com/rs/zerolog/log"
 )
 
@@ -23,14 +22,56 @@ func (table *Table) CommunityToString() string {
 }
 
 func (table *Table) Deal() {
-	for _, player := range table.curPlayers.ToPlayerArray() {
-		player.Hole.Cards = append(player.Hole.Cards, table.deck.Pop())
-		player.Hole.Cards = append(player.Hole.Cards, table.deck.Pop())
+	table.dealPlayerCards(table.curPlayers.ToPlayerArray(), table.variant.NumHoleCards(), table.numUpCards())
+
+	table.State = TableStatePreFlop
+}
+
+// deals each player down cards into their hole and up cards face up. when
+// the deck is too short to give every player a card on a one card street
+// (seven players in stud), a single community card that everyone plays is
+// dealt instead.
+func (table *Table) dealPlayerCards(players []*Player, down, up int) {
+	if down+up == 1 && table.deck.CardsLeft() < len(players) {
+		log.Info().
+			Int("cardsLeft", table.deck.CardsLeft()).
+			Int("players", len(players)).
+			Msg("not enough cards for every player, dealing a community card")
+
+		table.dealCommunity(1)
+
+		return
+	}
+
+	for _, player := range players {
+		holeLen, upLen := len(player.Hole.Cards), len(player.UpCards)
+
+		for i := 0; i < down; i++ {
+			if card := table.popCard(); card != nil {
+				player.Hole.Cards = append(player.Hole.Cards, card)
+			}
+		}
+		for i := 0; i < up; i++ {
+			if card := table.popCard(); card != nil {
+				player.UpCards = append(player.UpCards, card)
+			}
+		}
 
 		player.Hole.FillHoleInfo()
+
+		table.history.deal(player, player.Hole.Cards[holeLen:], player.UpCards[upLen:])
 	}
+}
 
-	table.State = TableStatePreFlop
+// the top card of the deck, or nil if the deck ran out
+func (table *Table) popCard() *Card {
+	card, err := table.deck.Pop()
+	if err != nil {
+		log.Error().Err(err).Msg("BUG: dealt more cards than the deck has")
+		return nil
+	}
+
+	return card
 }
 
 func (table *Table) AddToCommunity(card *Card) {
@@ -60,33 +101,37 @@ func (table *Table) SortCommunity() {
 	cardsSort(&table._comsorted)
 }
 
+// deals the next street of the variant's street schedule, or ends the round
+// after the last street.
 func (table *Table) NextCommunityAction() {
-	switch table.CommState {
-	case TableStatePreFlop:
-		table.DoFlop()
+	streets := table.variant.Streets()
 
-		table.CommState = TableStateFlop
-		if !table.BettingIsImpossible() { // else all players went all in preflop
-			// and we are in the all-in loop
-			table.ReorderPlayers()
-		}
-	case TableStateFlop:
-		table.DoTurn()
-
-		table.CommState = TableStateTurn
-	case TableStateTurn:
-		table.DoRiver()
+	streetIdx := table.streetIndex()
+	if streetIdx == -1 && table.CommState != TableStatePreFlop {
+		panic("BUG: Table.NextCommunityAction(): invalid community state")
+	}
 
-		table.CommState = TableStateRiver
-	case TableStateRiver:
+	if streetIdx == len(streets)-1 {
 		table.State = TableStateRoundOver // XXX shouldn't mix these states
 
 		return
-	default:
-		panic("BUG: Table.NextCommunityAction(): invalid community state")
 	}
 
+	street := streets[streetIdx+1]
+	table.dealStreet(street)
+
+	table.CommState = street.State
 	table.State = TableStateRounds
+
+	if streetIdx == -1 && !table.BettingIsImpossible() { // else all players went all in preflop
+		// and we are in the all-in loop
+		table.ReorderPlayers()
+	}
+
+	table.betCount = 0
+	table.lastRaise = 0
+	table.fullBet = 0
+	table.fullRaises++ // every player can bet on a new street
 }
 
 func (table *Table) NextTableAction() {
@@ -95,51 +140,44 @@ func (table *Table) NextTableAction() {
 		if table.Dealer == nil || table.SmallBlind == nil || table.BigBlind == nil {
 			table.handleOrphanedSeats()
 		}
+		if table.isHeadsUp() {
+			table.setHeadsUpBlinds()
+		}
+		table.setPositions()
 
-		table.Bet = table.Ante
-
-		table.SmallBlind.Player.Action.Amount = min(table.Ante/2,
-			table.SmallBlind.Player.ChipCount)
-		table.BigBlind.Player.Action.Amount = min(table.Ante,
-			table.BigBlind.Player.ChipCount)
-
-		table.SmallBlind.Player.ChipCount -= table.SmallBlind.Player.Action.Amount
-		table.BigBlind.Player.ChipCount -= table.BigBlind.Player.Action.Amount
-
-		table.MainPot.Total = table.SmallBlind.Player.Action.Amount + table.BigBlind.Player.Action.Amount
+		table.startHandChips()
+		table.startHandHistory()
+		table.postBlinds()
 
 		table.Deal()
 
 		table.CommState = TableStatePreFlop
 
+		if table.isStud() {
+			table.postBringIn()
+		} else {
+			table.postStraddle()
+		}
+
 		table.ReorderPlayers() // NOTE: need to call this to properly set curPlayer
 	case TableStateNewRound:
 		table.rotatePlayers()
 
-		table.Bet = table.Ante
-
-		table.SmallBlind.Player.Action.Amount = min(table.Ante/2,
-			table.SmallBlind.Player.ChipCount)
-		table.BigBlind.Player.Action.Amount = min(table.Ante,
-			table.BigBlind.Player.ChipCount)
-
-		table.SmallBlind.Player.ChipCount -= table.SmallBlind.Player.Action.Amount
-		table.BigBlind.Player.ChipCount -= table.BigBlind.Player.Action.Amount
-
-		table.MainPot.Total = table.SmallBlind.Player.Action.Amount + table.BigBlind.Player.Action.Amount
-
-		if table.SmallBlind.Player.ChipCount == 0 {
-			table.SmallBlind.Player.Action.Action = playerState.AllIn
-		}
-		if table.BigBlind.Player.ChipCount == 0 {
-			table.BigBlind.Player.Action.Action = playerState.AllIn
-		}
-
-		table.MainPot.Total = table.SmallBlind.Player.Action.Amount + table.BigBlind.Player.Action.Amount
+		table.startHandChips()
+		table.startHandHistory()
+		table.postBlinds()
 
 		table.Deal()
 
 		table.CommState = TableStatePreFlop
+
+		if table.isStud() {
+			// the bring-in decides who acts first
+			table.postBringIn()
+			table.ReorderPlayers()
+		} else if table.postStraddle() {
+			table.ReorderPlayers() // the straddler acts last
+		}
 	case TableStateGameOver:
 		log.Info().Msg("game over!")
 
@@ -148,24 +186,30 @@ func (table *Table) NextTableAction() {
 	}
 }
 
-func (table *Table) DoFlop() {
-	for i := 0; i < 3; i++ {
-		table.AddToCommunity(table.deck.Pop())
+// burns a card and deals street's cards like a dealer does
+func (table *Table) dealStreet(street Street) {
+	table.history.newStreet(street.State, len(table.Boards))
+
+	if err := table.deck.Burn(); err != nil {
+		log.Warn().Err(err).Msg("dealing without a burn card")
 	}
-	table.PrintCommunity()
-	table.SortCommunity()
 
-	table.State = TableStateRounds
+	table.dealCommunity(street.Community)
+	if street.DownCards > 0 || street.UpCards > 0 {
+		table.dealPlayerCards(table.GetNonFoldedPlayers(), street.DownCards, street.UpCards)
+	}
 }
 
-func (table *Table) DoTurn() {
-	table.AddToCommunity(table.deck.Pop())
-	table.PrintCommunity()
-	table.SortCommunity()
-}
+// deals numCards community cards
+func (table *Table) dealCommunity(numCards int) {
+	dealt := len(table.Community)
 
-func (table *Table) DoRiver() {
-	table.AddToCommunity(table.deck.Pop())
+	for i := 0; i < numCards; i++ {
+		if card := table.popCard(); card != nil {
+			table.AddToCommunity(card)
+		}
+	}
+	table.history.community(table.Community[dealt:], table.Community)
 	table.PrintCommunity()
 	table.SortCommunity()
 }
@@ -1,40 +1,196 @@
 package poker
 
-import math_rand "math/rand/v2"
+import (
+	crypto_rand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	math_rand "math/rand/v2"
+	"strings"
+)
 
 type Deck struct {
-	pos   uint
-	cards Cards
-	size  int
+	pos    uint
+	cards  Cards
+	order  Cards // the unshuffled deck. every shuffle starts from it
+	burned Cards // cards burned since the last shuffle
+	size   int
+
+	src math_rand.Source // where shuffles get their randomness from
+
+	stacked bool // shuffles keep the cards in order, see NewStackedDeck()
+
+	// in commit-reveal mode every shuffle draws a seed from src and shuffles
+	// with it, see Shuffle()
+	commitReveal bool
+	seed         [32]byte
+	commitment   string
+}
+
+// a standard 52 card deck. a nil src uses NewCryptoSource().
+func NewDeck(src math_rand.Source) *Deck {
+	return NewStrippedDeck(CardTwo, src)
+}
+
+// a 36 card deck without the twos through fives. a nil src uses
+// NewCryptoSource().
+func NewShortDeck(src math_rand.Source) *Deck {
+	return NewStrippedDeck(CardSix, src)
+}
+
+// NewStrippedDeck returns a deck with every card below lowCard taken out.
+func NewStrippedDeck(lowCard CardVal, src math_rand.Source) *Deck {
+	deck, err := NewCustomDeck(DeckCards(lowCard), src)
+	if err != nil {
+		panic(err)
+	}
+
+	return deck
+}
+
+// NewMultiDeck returns numDecks standard decks shuffled together.
+//
+// NOTE: five or more cards of the same rank play as quads
+func NewMultiDeck(numDecks int, src math_rand.Source) (*Deck, error) {
+	if numDecks < 1 {
+		return nil, errors.New("a multi-deck needs at least one deck")
+	}
+
+	var cards Cards
+	for i := 0; i < numDecks; i++ {
+		cards = append(cards, DeckCards(CardTwo)...)
+	}
+
+	return NewCustomDeck(cards, src)
 }
 
-func NewDeck() *Deck {
-	deck := &Deck{
-		size: 52, // 52 cards in a poker deck
+// NewCustomDeck returns a deck of the given cards, e.g. a stripped deck with
+// JokerCards() added. the deck keeps the order of cards until it's shuffled.
+// a nil src uses NewCryptoSource().
+func NewCustomDeck(cards Cards, src math_rand.Source) (*Deck, error) {
+	if len(cards) == 0 {
+		return nil, errors.New("a deck needs at least one card")
+	}
+
+	if src == nil {
+		src = NewCryptoSource()
 	}
-	deck.cards = make(Cards, deck.size)
+
+	for _, card := range cards {
+		if card == nil {
+			return nil, errors.New("a deck can't have a nil card")
+		}
+	}
+
+	return &Deck{
+		cards: append(Cards{}, cards...),
+		order: append(Cards{}, cards...),
+		size:  len(cards),
+		src:   src,
+	}, nil
+}
+
+// NewStackedDeck returns a deck that deals cards in the order they're given
+// after every shuffle, e.g. to deal a recorded hand again.
+func NewStackedDeck(cards Cards) (*Deck, error) {
+	deck, err := NewCustomDeck(cards, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deck.stacked = true
+
+	return deck, nil
+}
+
+// DeckCards returns every card from lowCard through the ace in each suit,
+// in order.
+func DeckCards(lowCard CardVal) Cards {
+	cards := make(Cards, 0, 4*int(CardAce-lowCard+1))
 
 	for suit := SuitClub; suit <= SuitSpade; suit++ {
-		for c_num := CardTwo; c_num <= CardAce; c_num++ {
+		for c_num := lowCard; c_num <= CardAce; c_num++ {
 			curCard := &Card{Suit: suit, NumValue: CardVal(c_num)}
 			if err := cardNumToString(curCard); err != nil {
 				panic(err)
 			}
 
-			deck.cards[deck.pos] = curCard
-			deck.pos++
+			cards = append(cards, curCard)
 		}
 	}
 
-	deck.pos = 0
+	return cards
+}
 
-	return deck
+// JokerCards returns n jokers. a joker is wild: it plays as whichever card
+// makes the best high hand, see assembleHand().
+func JokerCards(n int) Cards {
+	cards := make(Cards, n)
+	for i := range cards {
+		cards[i] = &Card{NumValue: CardJoker}
+		if err := cardNumToString(cards[i]); err != nil {
+			panic(err)
+		}
+	}
+
+	return cards
+}
+
+// NewSeededSource returns a PCG source seeded with seed. the same seed
+// always shuffles the same way, for tests and replays.
+func NewSeededSource(seed uint64) math_rand.Source {
+	return math_rand.NewPCG(seed, seed)
 }
 
+// NewCryptoSource returns a source that reads from crypto/rand. decks use it
+// unless they're given another source.
+func NewCryptoSource() math_rand.Source {
+	return cryptoSource{}
+}
+
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+
+	if _, err := crypto_rand.Read(b[:]); err != nil {
+		panic(err) // NOTE: crypto/rand doesn't fail on supported platforms
+	}
+
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Shuffle puts the deck back in order and shuffles it. in commit-reveal mode
+// the shuffle uses a new seed drawn from the deck's source, and the
+// commitment to the new order is kept until the next shuffle.
 func (deck *Deck) Shuffle() {
+	copy(deck.cards, deck.order)
+	deck.burned = nil
+
+	if deck.stacked {
+		deck.pos = 0
+
+		return
+	} else if !deck.commitReveal {
+		deck.shuffle(math_rand.New(deck.src))
+
+		return
+	}
+
+	for i := 0; i < len(deck.seed); i += 8 {
+		binary.LittleEndian.PutUint64(deck.seed[i:], deck.src.Uint64())
+	}
+
+	deck.shuffle(math_rand.New(math_rand.NewChaCha8(deck.seed)))
+	deck.commitment = deckCommitment(deck.cards)
+}
+
+func (deck *Deck) shuffle(rng *math_rand.Rand) {
 	// Fisher-Yates shuffle
 	for i := 0; i < deck.size; i++ {
-		randIdx := i + math_rand.IntN(deck.size-i)
+		randIdx := i + rng.IntN(deck.size-i)
 		// swap
 		deck.cards[randIdx], deck.cards[i] = deck.cards[i], deck.cards[randIdx]
 	}
@@ -42,8 +198,127 @@ func (deck *Deck) Shuffle() {
 	deck.pos = 0
 }
 
-// "remove" card from deck (functionally)
-func (deck *Deck) Pop() *Card {
+// SetCommitReveal turns commit-reveal mode on or off, starting with the next
+// shuffle.
+func (deck *Deck) SetCommitReveal(on bool) {
+	deck.commitReveal = on
+	deck.seed, deck.commitment = [32]byte{}, ""
+}
+
+// Commitment returns the hex SHA-256 of the deck order from the last shuffle.
+// it's empty unless the deck is in commit-reveal mode.
+func (deck *Deck) Commitment() string {
+	return deck.commitment
+}
+
+// Seed returns the hex seed of the last shuffle. it's empty unless the deck
+// is in commit-reveal mode.
+//
+// NOTE: the seed gives away every card in the deck, only reveal it after the hand
+func (deck *Deck) Seed() string {
+	if deck.commitment == "" {
+		return ""
+	}
+
+	return hex.EncodeToString(deck.seed[:])
+}
+
+// the hex SHA-256 of the card names in deck order separated by commas,
+// e.g. "Q ♠,3 ♥,..."
+func deckCommitment(cards Cards) string {
+	names := make([]string, len(cards))
+	for i, card := range cards {
+		names[i] = card.Name
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(names, ",")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyShuffle shuffles a new deck for variant with the revealed seed and
+// checks it against the commitment published before the deal. returns the
+// deck order so the dealt cards can be checked against it.
+func VerifyShuffle(variant Variant, seed, commitment string) (Cards, error) {
+	cards, err := ShuffleOrder(variant, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(deckCommitment(cards), commitment) {
+		return nil, errors.New("the seed doesn't match the commitment")
+	}
+
+	return cards, nil
+}
+
+// ShuffleOrder returns the order a new deck for variant is in after a
+// commit-reveal shuffle with seed, see Table.DeckSeed()
+func ShuffleOrder(variant Variant, seed string) (Cards, error) {
+	seedBytes, err := hex.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("bad seed: %w", err)
+	}
+
+	deck := variant.NewDeck(nil)
+	if len(seedBytes) != len(deck.seed) {
+		return nil, fmt.Errorf("seed has to be %d bytes, got %d", len(deck.seed), len(seedBytes))
+	}
+	copy(deck.seed[:], seedBytes)
+
+	deck.shuffle(math_rand.New(math_rand.NewChaCha8(deck.seed)))
+
+	return append(Cards{}, deck.cards...), nil
+}
+
+// Size returns the number of cards in the deck, dealt or not.
+func (deck *Deck) Size() int {
+	return deck.size
+}
+
+// CardsLeft returns the number of cards that haven't been dealt or burned.
+func (deck *Deck) CardsLeft() int {
+	return deck.size - int(deck.pos)
+}
+
+// Remaining returns the cards that haven't been dealt or burned, in the
+// order they'll come off the deck.
+func (deck *Deck) Remaining() Cards {
+	return deck.peek(deck.CardsLeft())
+}
+
+// Burned returns the cards burned since the last shuffle.
+func (deck *Deck) Burned() Cards {
+	return append(Cards{}, deck.burned...)
+}
+
+// the next n cards without removing them from the deck
+func (deck *Deck) peek(n int) Cards {
+	n = min(n, deck.CardsLeft())
+
+	return append(Cards{}, deck.cards[deck.pos:deck.pos+uint(n)]...)
+}
+
+// Pop takes the top card off the deck.
+func (deck *Deck) Pop() (*Card, error) {
+	if deck.CardsLeft() == 0 {
+		return nil, errors.New("the deck is out of cards")
+	}
+
 	deck.pos++
-	return deck.cards[deck.pos-1]
+
+	return deck.cards[deck.pos-1], nil
+}
+
+// Burn takes the top card off the deck face down, like a dealer does before
+// each street.
+func (deck *Deck) Burn() error {
+	card, err := deck.Pop()
+	if err != nil {
+		return fmt.Errorf("can't burn a card: %w", err)
+	}
+
+	deck.burned = append(deck.burned, card)
+
+	return nil
 }
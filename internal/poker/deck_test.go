@@ -0,0 +1,285 @@
+package poker
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func cardNames(cards Cards) []string {
+	names := make([]string, len(cards))
+	for i, card := range cards {
+		names[i] = card.Name
+	}
+
+	return names
+}
+
+func TestSeededShuffle(t *testing.T) {
+	shuffled := func(seed uint64) []string {
+		deck := NewDeck(NewSeededSource(seed))
+		deck.Shuffle()
+
+		return cardNames(deck.cards)
+	}
+
+	if got, want := shuffled(1), shuffled(1); !slices.Equal(got, want) {
+		t.Fatalf("the same seed shuffled differently:\n%v\n%v", got, want)
+	}
+	if slices.Equal(shuffled(1), shuffled(2)) {
+		t.Fatalf("different seeds shuffled the same way")
+	}
+
+	// NOTE: every shuffle starts from a new deck, so the order only depends
+	//       on the source
+	deck := NewDeck(NewSeededSource(1))
+	deck.Shuffle()
+	deck.Pop()
+	deck.Shuffle()
+	if got := cardNames(deck.cards); slices.Equal(got, shuffled(1)) {
+		t.Fatalf("second shuffle repeated the first")
+	}
+}
+
+func TestVerifyShuffle(t *testing.T) {
+	deck := NewDeck(NewSeededSource(1))
+	deck.SetCommitReveal(true)
+	deck.Shuffle()
+
+	seed, commitment := deck.Seed(), deck.Commitment()
+	if seed == "" || commitment == "" {
+		t.Fatalf("no seed or commitment in commit-reveal mode")
+	}
+
+	order, err := VerifyShuffle(Holdem{}, seed, commitment)
+	if err != nil {
+		t.Fatalf("VerifyShuffle: %v", err)
+	}
+	if got, want := cardNames(order), cardNames(deck.cards); !slices.Equal(got, want) {
+		t.Fatalf("verified order:\n%v\nwant\n%v", got, want)
+	}
+
+	deck.Shuffle()
+	if deck.Commitment() == commitment {
+		t.Fatalf("two shuffles had the same commitment")
+	}
+	if _, err := VerifyShuffle(Holdem{}, deck.Seed(), commitment); err == nil {
+		t.Fatalf("verified the next shuffle's seed against the old commitment")
+	}
+	if _, err := VerifyShuffle(ShortDeck{}, seed, commitment); err == nil {
+		t.Fatalf("verified a hold'em shuffle as a short deck")
+	}
+	if _, err := VerifyShuffle(Holdem{}, "abcd", commitment); err == nil {
+		t.Fatalf("verified a short seed")
+	}
+}
+
+func TestStackedDeck(t *testing.T) {
+	order := NewDeck(nil).Remaining()
+	slices.Reverse(order)
+
+	deck, err := NewStackedDeck(order)
+	if err != nil {
+		t.Fatalf("NewStackedDeck: %v", err)
+	}
+
+	for range 2 {
+		deck.Shuffle()
+
+		if got, want := cardNames(deck.Remaining()), cardNames(order); !slices.Equal(got, want) {
+			t.Fatalf("stacked deck after a shuffle:\n%v\nwant\n%v", got, want)
+		}
+		if _, err := deck.Pop(); err != nil {
+			t.Fatalf("Pop: %v", err)
+		}
+	}
+}
+
+func TestDeckSeed(t *testing.T) {
+	deck := NewDeck(NewSeededSource(1))
+	deck.SetCommitReveal(true)
+	deck.Shuffle()
+
+	commitment := deck.Commitment()
+
+	table := newVariantTestGame(t, Holdem{}, deck, DefaultBlinds, 1000, 1000, 1000)
+	table.CommitReveal = true
+
+	if _, err := table.DeckSeed(); err == nil {
+		t.Fatalf("revealed the seed during the hand")
+	}
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Fold},
+		{player: "p1", action: playerState.Fold},
+	})
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	seed, err := table.DeckSeed()
+	if err != nil {
+		t.Fatalf("DeckSeed: %v", err)
+	}
+
+	order, err := VerifyShuffle(Holdem{}, seed, commitment)
+	if err != nil {
+		t.Fatalf("VerifyShuffle: %v", err)
+	}
+
+	// the hole cards came off the top of the committed deck
+	var dealt []string
+	for _, player := range table.activePlayers.ToPlayerArray() {
+		dealt = append(dealt, cardNames(player.Hole.Cards)...)
+	}
+	if got, want := dealt, cardNames(order[:6]); !slices.Equal(got, want) {
+		t.Fatalf("dealt %v, want %v", got, want)
+	}
+}
+
+func TestDeckRunsOut(t *testing.T) {
+	deck, err := NewCustomDeck(mustCards(t, "Ah Kh"), NewSeededSource(1))
+	if err != nil {
+		t.Fatalf("NewCustomDeck: %v", err)
+	}
+
+	if err := deck.Burn(); err != nil {
+		t.Fatalf("Burn: %v", err)
+	}
+	if _, err := deck.Pop(); err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if deck.CardsLeft() != 0 || len(deck.Burned()) != 1 {
+		t.Fatalf("%d cards left and %d burned, want 0 and 1", deck.CardsLeft(), len(deck.Burned()))
+	}
+
+	if _, err := deck.Pop(); err == nil {
+		t.Fatalf("popped a card off an empty deck")
+	}
+	if err := deck.Burn(); err == nil {
+		t.Fatalf("burned a card off an empty deck")
+	}
+
+	deck.Shuffle()
+	if deck.CardsLeft() != 2 || len(deck.Burned()) != 0 {
+		t.Fatalf("shuffle didn't put every card back")
+	}
+
+	if _, err := NewCustomDeck(nil, nil); err == nil {
+		t.Fatalf("made a deck without cards")
+	}
+	if _, err := NewMultiDeck(0, nil); err == nil {
+		t.Fatalf("made a multi-deck without decks")
+	}
+	if deck, _ := NewMultiDeck(2, nil); deck.Size() != 104 {
+		t.Fatalf("two decks have %d cards", deck.Size())
+	}
+}
+
+func TestBurnBeforeEachStreet(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	remaining := table.deck.Remaining()
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Bet, amount: 20},
+		{player: "p1", action: playerState.Call},
+		{player: "p2", action: playerState.Call},
+		{},
+	})
+
+	// burn, then the flop
+	if got, want := cardNames(table.Community), cardNames(remaining[1:4]); !slices.Equal(got, want) {
+		t.Fatalf("flop is %v, want %v", got, want)
+	}
+	if got, want := cardNames(table.deck.Burned()), cardNames(remaining[:1]); !slices.Equal(got, want) {
+		t.Fatalf("burned %v, want %v", got, want)
+	}
+	if table.deck.CardsLeft() != 52-6-4 {
+		t.Fatalf("%d cards left after the flop", table.deck.CardsLeft())
+	}
+}
+
+// seven stud players need 49 cards plus the burn cards, so seventh street is
+// a single community card
+func TestStudCommunityCard(t *testing.T) {
+	table := newVariantTestGame(t, SevenCardStud{}, NewDeck(nil), studTestBlinds, testStacks(7)...)
+
+	for i := 0; table.State != TableStateDoneBetting; i++ {
+		if i > 7 {
+			t.Fatalf("third street didn't finish after everyone called")
+		}
+
+		if err := table.PlayerAction(table.curPlayer.Player, Action{Action: playerState.Call}); err != nil {
+			t.Fatalf("%s: call: %v", table.curPlayer.Player.Name, err)
+		}
+	}
+
+	for street := 0; street < 4; street++ {
+		nextStreet(t, table)
+		checkAround(t, table)
+	}
+
+	if len(table.Community) != 1 {
+		t.Fatalf("dealt %d community cards on seventh street, want 1", len(table.Community))
+	}
+	for _, player := range table.curPlayers.ToPlayerArray() {
+		if n := len(player.allCards()); n != 6 {
+			t.Fatalf("%s has %d cards, want 6", player.Name, n)
+		}
+	}
+
+	table.FinishRound()
+
+	if len(table.Winners) == 0 {
+		t.Fatalf("no winners")
+	}
+}
+
+func TestJokersAreWild(t *testing.T) {
+	tests := []struct {
+		name      string
+		hole      string
+		jokers    int
+		community string
+		wantRank  Rank
+	}{
+		{
+			name:      "joker fills a royal flush",
+			hole:      "Ah",
+			jokers:    1,
+			community: "Kh Qh Jh 2c 3d",
+			wantRank:  RankRoyalFlush,
+		},
+		{
+			name:      "two jokers make quads",
+			jokers:    2,
+			community: "Ac Ad 2c 7d 9h",
+			wantRank:  RankQuads,
+		},
+		{
+			name:      "no five of a kind",
+			hole:      "Ah",
+			jokers:    1,
+			community: "Ac Ad As 2c 7d",
+			wantRank:  RankQuads,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := mustPlayerWithHole(t, "p0", tt.hole)
+			player.Hole.Cards = append(player.Hole.Cards, JokerCards(tt.jokers)...)
+
+			player.Hand = &Hand{Rank: RankMuck, Cards: make(Cards, 0, 5)}
+			assembleHand(player, append(player.Hole.Cards, mustCards(t, tt.community)...), standardHandRules)
+
+			if player.Hand.Rank != tt.wantRank {
+				t.Fatalf("rank %d, want %d", player.Hand.Rank, tt.wantRank)
+			}
+			if slices.ContainsFunc(player.Hand.Cards, isJoker) {
+				t.Fatalf("hand still has a joker in it")
+			}
+		})
+	}
+}
@@ -0,0 +1,553 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	math_rand "math/rand/v2"
+	"slices"
+)
+
+// Equity is how a player's hand does over every way the rest of the hand can
+// be dealt.
+type Equity struct {
+	Win    float64 // chance of winning the whole pot
+	Tie    float64 // chance of winning part of the pot
+	Equity float64 // share of the pot the player wins on average
+}
+
+const (
+	// Monte Carlo runouts tried when there are too many to enumerate
+	DefaultEquityTrials = 10000
+
+	// the most runouts enumerated exactly before switching to Monte Carlo
+	DefaultMaxExactRunouts = 50000
+
+	// runouts tried for a player's hand strength. it's sent every street, so
+	// it's kept lower than DefaultEquityTrials
+	handStrengthTrials = 2000
+)
+
+// EquityCalculator works out each player's equity from the hole cards that are
+// known, a partial board and dead cards. when there are few enough ways left
+// to deal the missing board and hole cards every one of them is dealt out,
+// otherwise runouts are sampled at random.
+type EquityCalculator struct {
+	Variant Variant // nil means hold'em
+	Deck    *Deck   // the cards the runouts are dealt from. nil means a new deck for Variant
+
+	Trials          int // Monte Carlo runouts. 0 means DefaultEquityTrials
+	MaxExactRunouts int // 0 means DefaultMaxExactRunouts
+
+	Src math_rand.Source // where Monte Carlo runouts come from. nil seeds a PCG from NewCryptoSource()
+}
+
+// variants whose high hands can be valued straight from the cards. equity
+// can only be calculated for these.
+type handValuer interface {
+	// the value of the best high hand hole can make with a full board
+	handValue(hole, board Cards) HandValue
+}
+
+func (Holdem) handValue(hole, board Cards) HandValue {
+	return standardHandRules.holdemValue(hole, board)
+}
+
+func (ShortDeck) handValue(hole, board Cards) HandValue {
+	return shortDeckHandRules.holdemValue(hole, board)
+}
+
+// exactly two hole cards and three community cards, see Omaha.RankHand()
+func (Omaha) handValue(hole, board Cards) HandValue {
+	var (
+		best  HandValue
+		cards [5]*Card
+	)
+
+	for i := 0; i < len(hole)-1; i++ {
+		for j := i + 1; j < len(hole); j++ {
+			for a := 0; a < len(board)-2; a++ {
+				for b := a + 1; b < len(board)-1; b++ {
+					for c := b + 1; c < len(board); c++ {
+						cards = [5]*Card{hole[i], hole[j], board[a], board[b], board[c]}
+						best = max(best, standardHandRules.evaluate(cards[:]))
+					}
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+// the best five of the hole & community cards
+func (rules handRules) holdemValue(hole, board Cards) HandValue {
+	var buf [9]*Card
+
+	return rules.evaluate(append(append(buf[:0], hole...), board...))
+}
+
+// CalculateEquity returns the equity of each hand in holes with the default
+// calculator settings, see EquityCalculator.Calculate().
+func CalculateEquity(variant Variant, holes []Cards, board, dead Cards) ([]Equity, error) {
+	calc := &EquityCalculator{Variant: variant}
+
+	return calc.Calculate(holes, board, dead)
+}
+
+// Calculate returns the equity of each player's hole cards in holes, in the
+// same order. a player can have fewer hole cards than the variant deals, even
+// none: the missing cards are dealt at random from the cards nobody can see,
+// which is how a hand is measured against unknown opponents. dead cards are
+// known to be out of the deck, e.g. folded or burned cards that were shown.
+func (calc *EquityCalculator) Calculate(holes []Cards, board, dead Cards) ([]Equity, error) {
+	variant := calc.Variant
+	if variant == nil {
+		variant = Holdem{}
+	}
+
+	valuer, ok := variant.(handValuer)
+	if !ok {
+		return nil, fmt.Errorf("equity can't be calculated for %s", variant.Name())
+	}
+
+	if len(holes) < 2 {
+		return nil, errors.New("equity needs at least two players")
+	}
+
+	boardSize := 0
+	for _, street := range variant.Streets() {
+		boardSize += street.Community
+	}
+	if len(board) > boardSize {
+		return nil, fmt.Errorf("%s has at most %d community cards, got %d", variant.Name(), boardSize, len(board))
+	}
+
+	missing := boardSize - len(board)
+	for i, hole := range holes {
+		if len(hole) > variant.NumHoleCards() {
+			return nil, fmt.Errorf("player %d has %d hole cards, %s deals %d",
+				i+1, len(hole), variant.Name(), variant.NumHoleCards())
+		}
+
+		missing += variant.NumHoleCards() - len(hole)
+	}
+
+	deck := calc.Deck
+	if deck == nil {
+		deck = variant.NewDeck(nil)
+	}
+
+	unknown, err := unknownCards(deck, holes, board, dead)
+	if err != nil {
+		return nil, err
+	}
+	if missing > len(unknown) {
+		return nil, fmt.Errorf("%d cards are needed to finish the hand but the deck has %d left", missing, len(unknown))
+	}
+
+	run := newEquityRun(variant, valuer, holes, board)
+
+	if run.exactRunouts(len(unknown)) <= calc.maxExactRunouts() {
+		run.enumerate(unknown)
+	} else {
+		run.sample(unknown, calc.trials(), calc.src())
+	}
+
+	return run.equities(), nil
+}
+
+func (calc *EquityCalculator) trials() int {
+	if calc.Trials > 0 {
+		return calc.Trials
+	}
+
+	return DefaultEquityTrials
+}
+
+func (calc *EquityCalculator) maxExactRunouts() int {
+	if calc.MaxExactRunouts > 0 {
+		return calc.MaxExactRunouts
+	}
+
+	return DefaultMaxExactRunouts
+}
+
+func (calc *EquityCalculator) src() math_rand.Source {
+	if calc.Src != nil {
+		return calc.Src
+	}
+
+	seed := NewCryptoSource()
+
+	return math_rand.NewPCG(seed.Uint64(), seed.Uint64())
+}
+
+// the deck's cards minus every card that's known. each known card takes one
+// copy out of the deck, so a card that's known twice has to be in a
+// multi-deck twice.
+func unknownCards(deck *Deck, holes []Cards, board, dead Cards) (Cards, error) {
+	unknown := append(Cards{}, deck.order...)
+	if slices.ContainsFunc(unknown, isJoker) {
+		return nil, errors.New("equity can't be calculated with jokers in the deck")
+	}
+
+	known := append(append(slices.Concat(holes...), board...), dead...)
+	for _, card := range known {
+		i := slices.IndexFunc(unknown, func(c *Card) bool {
+			return c.NumValue == card.NumValue && c.Suit == card.Suit
+		})
+		if i == -1 {
+			return nil, fmt.Errorf("%s is used twice or isn't in the deck", card.Name)
+		}
+
+		unknown = slices.Delete(unknown, i, i+1)
+	}
+
+	return unknown, nil
+}
+
+// caps runout counts so they don't overflow
+const runoutsLimit = 1 << 40
+
+// n choose k, capped at runoutsLimit
+func binomial(n, k int) int {
+	result := 1
+	for i := 0; i < k; i++ {
+		result = result * (n - i) / (i + 1)
+		if result > runoutsLimit {
+			return runoutsLimit
+		}
+	}
+
+	return result
+}
+
+// the running totals of an equity calculation
+type equityRun struct {
+	valuer handValuer
+	hiLo   HiLoVariant // nil if the variant isn't split hi-lo
+
+	numHole, boardSize int
+
+	known []Cards // the hole cards each player was given
+	holes []Cards // each player's hole cards for the runout being scored
+	board Cards   // the board for the runout being scored
+
+	values []HandValue
+
+	runouts           int
+	wins, ties, total []float64
+
+	// scratch table & players for ranking lows, see HiLoVariant.RankLowHand()
+	lowTable   *Table
+	lowPlayers []*Player
+}
+
+func newEquityRun(variant Variant, valuer handValuer, holes []Cards, board Cards) *equityRun {
+	run := &equityRun{
+		valuer:  valuer,
+		numHole: variant.NumHoleCards(),
+		known:   holes,
+		holes:   make([]Cards, len(holes)),
+		values:  make([]HandValue, len(holes)),
+		wins:    make([]float64, len(holes)),
+		ties:    make([]float64, len(holes)),
+		total:   make([]float64, len(holes)),
+	}
+
+	for _, street := range variant.Streets() {
+		run.boardSize += street.Community
+	}
+	run.board = append(make(Cards, 0, run.boardSize), board...)
+
+	for i, hole := range holes {
+		run.holes[i] = append(make(Cards, 0, run.numHole), hole...)
+	}
+
+	if hiLo, ok := variant.(HiLoVariant); ok {
+		run.hiLo = hiLo
+		run.lowTable = &Table{}
+		run.lowPlayers = make([]*Player, len(holes))
+		for i := range run.lowPlayers {
+			run.lowPlayers[i] = &Player{Hole: &Hole{}}
+		}
+	}
+
+	return run
+}
+
+// how many runouts enumerate() deals from unknown cards, capped at
+// runoutsLimit
+func (run *equityRun) exactRunouts(unknown int) int {
+	boardLeft := run.boardSize - len(run.board)
+
+	runouts := binomial(unknown, boardLeft)
+	unknown -= boardLeft
+	for _, hole := range run.known {
+		missing := run.numHole - len(hole)
+
+		holes := binomial(unknown, missing)
+		if runouts > runoutsLimit/holes {
+			return runoutsLimit
+		}
+
+		runouts *= holes
+		unknown -= missing
+	}
+
+	return runouts
+}
+
+// deals out every board that can be made from unknown, then every way to
+// give each player their missing hole cards from what's left, and scores each
+// one
+func (run *equityRun) enumerate(unknown Cards) {
+	used := make([]bool, len(unknown))
+
+	// adds n of the unused cards from start on to cards, then calls next for
+	// each way to do it
+	var deal func(cards *Cards, start, n int, next func())
+	deal = func(cards *Cards, start, n int, next func()) {
+		if n == 0 {
+			next()
+			return
+		}
+
+		for i := start; i < len(unknown); i++ {
+			if used[i] {
+				continue
+			}
+
+			used[i] = true
+			*cards = append(*cards, unknown[i])
+			deal(cards, i+1, n-1, next)
+			*cards = (*cards)[:len(*cards)-1]
+			used[i] = false
+		}
+	}
+
+	var dealHole func(player int)
+	dealHole = func(player int) {
+		if player == len(run.holes) {
+			run.score()
+			return
+		}
+
+		missing := run.numHole - len(run.known[player])
+		deal(&run.holes[player], 0, missing, func() { dealHole(player + 1) })
+	}
+
+	deal(&run.board, 0, run.boardSize-len(run.board), func() { dealHole(0) })
+}
+
+// deals trials random runouts from unknown, missing hole cards included, and
+// scores each one
+func (run *equityRun) sample(unknown Cards, trials int, src math_rand.Source) {
+	rng := math_rand.New(src)
+	pool := append(Cards{}, unknown...)
+	boardLen := len(run.board)
+
+	// NOTE: a partial Fisher-Yates shuffle. the pool doesn't have to be put
+	// back in order between runouts
+	next := 0
+	draw := func() *Card {
+		i := next + rng.IntN(len(pool)-next)
+		pool[next], pool[i] = pool[i], pool[next]
+		next++
+
+		return pool[next-1]
+	}
+
+	for range trials {
+		next = 0
+
+		for i, hole := range run.known {
+			run.holes[i] = append(run.holes[i][:0], hole...)
+			for len(run.holes[i]) < run.numHole {
+				run.holes[i] = append(run.holes[i], draw())
+			}
+		}
+
+		run.board = run.board[:boardLen]
+		for len(run.board) < run.boardSize {
+			run.board = append(run.board, draw())
+		}
+
+		run.score()
+	}
+}
+
+// splits the pot of the current runout between the best high hands and, in
+// hi-lo games, the best lows
+func (run *equityRun) score() {
+	for i, hole := range run.holes {
+		run.values[i] = run.valuer.handValue(hole, run.board)
+	}
+
+	bestHigh, highWinners := slices.Max(run.values), 0
+	for _, value := range run.values {
+		if value == bestHigh {
+			highWinners++
+		}
+	}
+
+	bestLow, lowWinners := run.rankLows()
+
+	highShare := 1.0
+	if lowWinners > 0 {
+		highShare = 0.5
+	}
+
+	run.runouts++
+	for i, value := range run.values {
+		share := 0.0
+		if value == bestHigh {
+			share += highShare / float64(highWinners)
+		}
+		if lowWinners > 0 {
+			if low := run.lowPlayers[i].LowHand; low != nil && compareLowHands(low, bestLow) == 0 {
+				share += 0.5 / float64(lowWinners)
+			}
+		}
+
+		run.total[i] += share
+		if share == 1 {
+			run.wins[i]++
+		} else if share > 0 {
+			run.ties[i]++
+		}
+	}
+}
+
+// ranks each player's low for the current runout. returns the best low and
+// how many players have it, 0 if nobody has a low or the game isn't hi-lo.
+func (run *equityRun) rankLows() (*Hand, int) {
+	if run.hiLo == nil {
+		return nil, 0
+	}
+
+	var (
+		best    *Hand
+		winners int
+	)
+
+	run.lowTable.Community = run.board
+	for i, player := range run.lowPlayers {
+		player.Hole.Cards, player.LowHand = run.holes[i], nil
+		run.hiLo.RankLowHand(run.lowTable, player)
+
+		if player.LowHand == nil {
+			continue
+		}
+
+		if best == nil || compareLowHands(player.LowHand, best) > 0 {
+			best, winners = player.LowHand, 1
+		} else if compareLowHands(player.LowHand, best) == 0 {
+			winners++
+		}
+	}
+
+	return best, winners
+}
+
+func (run *equityRun) equities() []Equity {
+	equities := make([]Equity, len(run.total))
+	if run.runouts == 0 {
+		return equities
+	}
+
+	runouts := float64(run.runouts)
+	for i := range equities {
+		equities[i] = Equity{
+			Win:    run.wins[i] / runouts,
+			Tie:    run.ties[i] / runouts,
+			Equity: run.total[i] / runouts,
+		}
+	}
+
+	return equities
+}
+
+// HandStrength returns player's equity against every other player still in
+// the hand. only what the player can see is known: their own hole cards and
+// the community cards. everyone else's hole cards are dealt at random.
+func (table *Table) HandStrength(player *Player) (Equity, error) {
+	query, err := table.HandStrengthQuery(player)
+	if err != nil {
+		return Equity{}, err
+	}
+
+	return query.Calculate()
+}
+
+// HandStrengthQuery is what a player can see of the hand, taken from the table
+// so their hand strength can be worked out without holding it, see
+// Table.HandStrengthQuery().
+type HandStrengthQuery struct {
+	calc  EquityCalculator
+	holes []Cards
+	board Cards
+}
+
+// HandStrengthQuery returns what's needed to work out player's hand strength
+// later, see HandStrength().
+func (table *Table) HandStrengthQuery(player *Player) (*HandStrengthQuery, error) {
+	players := table.GetNonFoldedPlayers()
+	if !slices.Contains(players, player) || len(player.Hole.Cards) == 0 {
+		return nil, fmt.Errorf("%s isn't in the hand", player.Name)
+	}
+
+	holes := []Cards{slices.Clone(player.Hole.Cards)}
+	for range len(players) - 1 {
+		holes = append(holes, nil)
+	}
+
+	// NOTE: the calculator only reads the deck's unshuffled cards, which
+	// don't change once the deck is made
+	return &HandStrengthQuery{
+		calc: EquityCalculator{
+			Variant: table.Variant(),
+			Deck:    table.deck,
+			Trials:  handStrengthTrials,
+		},
+		holes: holes,
+		board: slices.Clone(table.Community),
+	}, nil
+}
+
+// Calculate returns the player's hand strength. heads up in hold'em the turn
+// and the river have few enough runouts to deal out every one of them.
+func (query *HandStrengthQuery) Calculate() (Equity, error) {
+	equities, err := query.calc.Calculate(query.holes, query.board, nil)
+	if err != nil {
+		return Equity{}, err
+	}
+
+	return equities[0], nil
+}
+
+// AllInEquity returns the equity of each player still in the hand with every
+// hole card known and the community cards dealt so far, e.g. once the hands
+// are tabled in an all in, see TableHands().
+func (table *Table) AllInEquity() (map[*Player]Equity, error) {
+	players := table.GetNonFoldedPlayers()
+
+	holes := make([]Cards, len(players))
+	for i, player := range players {
+		holes[i] = player.Hole.Cards
+	}
+
+	calc := &EquityCalculator{Variant: table.Variant(), Deck: table.deck}
+
+	equities, err := calc.Calculate(holes, table.Community, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	playerEquity := make(map[*Player]Equity, len(players))
+	for i, player := range players {
+		playerEquity[player] = equities[i]
+	}
+
+	return playerEquity, nil
+}
@@ -0,0 +1,172 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func mustHoles(t *testing.T, holes ...string) []Cards {
+	t.Helper()
+
+	cards := make([]Cards, len(holes))
+	for i, hole := range holes {
+		cards[i] = mustCards(t, hole)
+	}
+
+	return cards
+}
+
+func TestCalculateEquity(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant Variant
+		holes   []string
+		board   string
+		want    []Equity
+	}{
+		{
+			name:  "river",
+			holes: []string{"Ah Ad", "Kc Ks"},
+			board: "2h 7d 9c Js 3c",
+			want:  []Equity{{Win: 1, Equity: 1}, {}},
+		},
+		{
+			name:  "board plays",
+			holes: []string{"2c 3d", "4c 5d"},
+			board: "Th Jh Qh Kh Ah",
+			want:  []Equity{{Tie: 1, Equity: 0.5}, {Tie: 1, Equity: 0.5}},
+		},
+		{
+			// NOTE: 990 turn & river cards. kings win with a king unless the
+			// other card is an ace
+			name:  "flop",
+			holes: []string{"Ah Ad", "Kc Ks"},
+			board: "2h 7d 9c",
+			want:  []Equity{{Win: 907.0 / 990, Equity: 907.0 / 990}, {Win: 83.0 / 990, Equity: 83.0 / 990}},
+		},
+		{
+			// NOTE: 990 hands the other player can have. aces lose to 15 sets,
+			// 90 two pairs and 16 straights and tie with the last two aces
+			name:  "river against a random hand",
+			holes: []string{"Ah Ad", ""},
+			board: "2c 7d 9h Js 3s",
+			want: []Equity{
+				{Win: 868.0 / 990, Tie: 1.0 / 990, Equity: 868.5 / 990},
+				{Win: 121.0 / 990, Tie: 1.0 / 990, Equity: 121.5 / 990},
+			},
+		},
+		{
+			name:    "hi-lo split",
+			variant: OmahaHiLo{},
+			holes:   []string{"4h 6d Jc Jd", "Kd Kc 7h 8s"},
+			board:   "As 2d 3c Kh Qs",
+			want:    []Equity{{Tie: 1, Equity: 0.5}, {Tie: 1, Equity: 0.5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equities, err := CalculateEquity(tt.variant, mustHoles(t, tt.holes...), mustCards(t, tt.board), nil)
+			if err != nil {
+				t.Fatalf("CalculateEquity: %v", err)
+			}
+
+			for i, equity := range equities {
+				if !equityNear(equity, tt.want[i], 1e-9) {
+					t.Errorf("player %d: equity %+v, want %+v", i+1, equity, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func equityNear(equity, want Equity, tolerance float64) bool {
+	return math.Abs(equity.Win-want.Win) <= tolerance &&
+		math.Abs(equity.Tie-want.Tie) <= tolerance &&
+		math.Abs(equity.Equity-want.Equity) <= tolerance
+}
+
+func TestMonteCarloEquity(t *testing.T) {
+	calc := &EquityCalculator{Trials: 20000, Src: NewSeededSource(1)}
+
+	// NOTE: too many preflop boards to deal out. aces are about an 82%
+	// favourite over kings
+	equities, err := calc.Calculate(mustHoles(t, "Ah Ad", "Kc Ks"), nil, nil)
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if got := equities[0].Equity; math.Abs(got-0.82) > 0.02 {
+		t.Fatalf("aces have %.3f equity against kings", got)
+	}
+
+	// random opponent hands are dealt for unknown hole cards. dead kings
+	// leave them fewer pairs to beat aces with
+	equities, err = calc.Calculate(mustHoles(t, "Ah Ad", ""), nil, mustCards(t, "Kc Ks"))
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if got := equities[0].Equity; got < 0.8 || got > 0.9 {
+		t.Fatalf("aces have %.3f equity against a random hand", got)
+	}
+	if sum := equities[0].Equity + equities[1].Equity; math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("equities add up to %f", sum)
+	}
+}
+
+func TestEquityErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant Variant
+		holes   []string
+		board   string
+	}{
+		{name: "one player", holes: []string{"Ah Ad"}},
+		{name: "card used twice", holes: []string{"Ah Ad", "Ah Ks"}},
+		{name: "too many hole cards", holes: []string{"Ah Ad Ac", "Kc Ks"}},
+		{name: "too many board cards", holes: []string{"Ah Ad", "Kc Ks"}, board: "2c 3c 4c 5c 7d 8d"},
+		{name: "card not in the deck", variant: ShortDeck{}, holes: []string{"Ah Ad", "2c 2d"}},
+		{name: "stud", variant: SevenCardStud{}, holes: []string{"Ah Ad", "Kc Ks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CalculateEquity(tt.variant, mustHoles(t, tt.holes...), mustCards(t, tt.board), nil); err == nil {
+				t.Fatalf("no error")
+			}
+		})
+	}
+}
+
+func TestTableEquity(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Fold},
+	})
+
+	players := testPlayersByName(table)
+	if _, err := table.HandStrength(players["p0"]); err == nil {
+		t.Fatalf("folded player has a hand strength")
+	}
+
+	strength, err := table.HandStrength(players["p1"])
+	if err != nil {
+		t.Fatalf("HandStrength: %v", err)
+	}
+	if strength.Equity <= 0 || strength.Equity >= 1 {
+		t.Fatalf("preflop hand strength is %f", strength.Equity)
+	}
+
+	equities, err := table.AllInEquity()
+	if err != nil {
+		t.Fatalf("AllInEquity: %v", err)
+	}
+	if len(equities) != 2 {
+		t.Fatalf("%d players have an equity, want 2", len(equities))
+	}
+	if sum := equities[players["p1"]].Equity + equities[players["p2"]].Equity; math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("equities add up to %f", sum)
+	}
+}
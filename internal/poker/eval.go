@@ -0,0 +1,245 @@
+package poker
+
+import "math/bits"
+
+// HandValue is the strength of a poker hand as a single number: a higher
+// value beats a lower one and equal values tie. from the top, a value holds
+// the hand's rank as it's ordered by the hand rules, the Rank itself and the
+// values of the five cards that decide ties, most important card first.
+type HandValue uint32
+
+const (
+	handValueCategoryShift = 24
+	handValueRankShift     = 20
+)
+
+// a mask of card ranks, bit 0 is a two and bit 12 is an ace
+type rankMask uint16
+
+var (
+	// the high card of the best straight in a rank mask, 0 when there's no
+	// straight. the ace-low straight isn't in the table since it depends on
+	// the hand rules, see handRules.straightHigh().
+	straightTable [1 << 13]CardVal
+
+	// the values of the five highest ranks in a rank mask as HandValue card
+	// bits, highest first. fewer ranks leave the low bits 0.
+	topFiveTable [1 << 13]HandValue
+)
+
+func init() {
+	for mask := range 1 << 13 {
+		for high := CardVal(CardAce); high >= CardSix; high-- {
+			straight := rankMask(0x1f) << (high - CardSix)
+			if rankMask(mask)&straight == straight {
+				straightTable[mask] = high
+				break
+			}
+		}
+
+		var top HandValue
+		shift := 16
+		for m := rankMask(mask); m != 0 && shift >= 0; shift -= 4 {
+			high := bits.Len16(uint16(m)) - 1
+			top |= HandValue(CardTwo+CardVal(high)) << shift
+			m &^= 1 << high
+		}
+		topFiveTable[mask] = top
+	}
+}
+
+func rankBit(value CardVal) rankMask {
+	return 1 << (value - CardTwo)
+}
+
+// EvaluateHand returns the value of the best five card hand that can be made
+// out of cards with the standard hand rankings. cards should have five to
+// seven cards and no jokers.
+func EvaluateHand(cards Cards) HandValue {
+	return standardHandRules.evaluate(cards)
+}
+
+// Rank returns the rank of the hand, e.g. RankFlush
+func (value HandValue) Rank() Rank {
+	return Rank(value>>handValueRankShift&0xf) - 1
+}
+
+// the value of a hand of rank whose tie-breaking card values are already
+// packed into cardBits
+func (rules handRules) handValue(rank Rank, cardBits HandValue) HandValue {
+	return HandValue(rules.rankValue(rank)+1)<<handValueCategoryShift |
+		HandValue(rank+1)<<handValueRankShift | cardBits
+}
+
+// packs card values into HandValue card bits, most important first
+func packCardValues(values ...CardVal) HandValue {
+	var cardBits HandValue
+	for i, value := range values[:min(len(values), 5)] {
+		cardBits |= HandValue(value) << (16 - 4*i)
+	}
+
+	return cardBits
+}
+
+// the high card of the best straight in mask, counting the ace-low straight.
+// wheel is true for the ace-low straight.
+func (rules handRules) straightHigh(mask rankMask) (high CardVal, wheel bool) {
+	if high := straightTable[mask]; high != 0 {
+		return high, false
+	}
+
+	wheelMask := rankBit(CardAce) | rankMask(0xf)<<(rules.wheelLowCard-CardTwo)
+	if mask&wheelMask == wheelMask {
+		return rules.wheelLowCard + 3, true
+	}
+
+	return 0, false
+}
+
+// the card bits of a straight as assembleHand orders it. the ace of an
+// ace-low straight is its lowest card.
+func straightCardBits(high CardVal, wheel bool) HandValue {
+	if wheel {
+		return packCardValues(high, high-1, high-2, high-3, CardAce)
+	}
+
+	return packCardValues(high, high-1, high-2, high-3, high-4)
+}
+
+// the top n card values of mask as HandValue card bits starting at card i
+func topCardBits(mask rankMask, n, i int) HandValue {
+	top := topFiveTable[mask] >> (4 * (5 - n)) // NOTE: keep the top n values
+	top &= 1<<(4*n) - 1
+
+	return top << (4 * (5 - n - i))
+}
+
+// the value of the best five card hand that can be made out of cards.
+//
+// NOTE: doesn't allocate. five or more cards of the same rank from a
+// multi-deck play as quads
+func (rules handRules) evaluate(cards Cards) HandValue {
+	var (
+		suits  [SuitSpade + 1]rankMask
+		counts [CardAce + 1]uint8
+		ranks  rankMask
+	)
+
+	for _, card := range cards {
+		bit := rankBit(card.NumValue)
+		suits[card.Suit] |= bit
+		ranks |= bit
+		counts[card.NumValue]++
+	}
+
+	// straight & royal flush. there can only be one flush suit in 7 cards
+	var flush rankMask
+	for _, mask := range suits[SuitClub:] {
+		if bits.OnesCount16(uint16(mask)) < 5 {
+			continue
+		}
+
+		if high, wheel := rules.straightHigh(mask); high != 0 {
+			rank := RankStraightFlush
+			if high == CardAce {
+				rank = RankRoyalFlush
+			}
+
+			return rules.handValue(rank, straightCardBits(high, wheel))
+		}
+
+		flush = mask
+	}
+
+	var quads, trips, secondTrips, pair, secondPair CardVal
+	for value := CardVal(CardAce); value >= CardTwo; value-- {
+		switch count := counts[value]; {
+		case count >= 4 && quads == 0:
+			quads = value
+		case count >= 3 && trips == 0:
+			trips = value
+		case count >= 3 && secondTrips == 0:
+			secondTrips = value
+		case count >= 2 && pair == 0:
+			pair = value
+		case count >= 2 && secondPair == 0:
+			secondPair = value
+		}
+	}
+
+	switch {
+	case quads != 0:
+		return rules.handValue(RankQuads, packCardValues(quads, quads, quads, quads)|
+			topCardBits(ranks&^rankBit(quads), 1, 4))
+	case trips != 0 && (secondTrips != 0 || pair != 0):
+		fullOf := max(secondTrips, pair)
+
+		return rules.handValue(RankFullHouse, packCardValues(trips, trips, trips, fullOf, fullOf))
+	case flush != 0:
+		return rules.handValue(RankFlush, topCardBits(flush, 5, 0))
+	}
+
+	if high, wheel := rules.straightHigh(ranks); high != 0 {
+		return rules.handValue(RankStraight, straightCardBits(high, wheel))
+	}
+
+	switch {
+	case trips != 0:
+		return rules.handValue(RankTrips, packCardValues(trips, trips, trips)|
+			topCardBits(ranks&^rankBit(trips), 2, 3))
+	case secondPair != 0:
+		return rules.handValue(RankTwoPair, packCardValues(pair, pair, secondPair, secondPair)|
+			topCardBits(ranks&^rankBit(pair)&^rankBit(secondPair), 1, 4))
+	case pair != 0:
+		return rules.handValue(RankPair, packCardValues(pair, pair)|
+			topCardBits(ranks&^rankBit(pair), 3, 2))
+	}
+
+	return rules.handValue(RankHighCard, topCardBits(ranks, 5, 0))
+}
+
+// the value of a hand that was already assembled. the hand's cards are
+// ordered least important first, see assembleHand().
+func (rules handRules) assembledValue(hand *Hand) HandValue {
+	var cardBits HandValue
+	for i, shift := len(hand.Cards)-1, 16; i >= 0 && shift >= 0; i, shift = i-1, shift-4 {
+		cardBits |= HandValue(hand.Cards[i].NumValue) << shift
+	}
+
+	return rules.handValue(hand.Rank, cardBits)
+}
+
+// picks the cards out of cards that make up the hand value, least important
+// card first like the rest of the Hand code expects.
+func handCards(value HandValue, cards Cards) Cards {
+	var flushSuit Suit
+	switch value.Rank() {
+	case RankFlush, RankStraightFlush, RankRoyalFlush:
+		var suitCounts [SuitSpade + 1]int
+		for _, card := range cards {
+			if suitCounts[card.Suit]++; suitCounts[card.Suit] == 5 {
+				flushSuit = card.Suit
+			}
+		}
+	}
+
+	picked := make(Cards, 0, 5)
+	var used uint64
+	for shift := 16; shift >= 0; shift -= 4 {
+		cardValue := CardVal(value >> shift & 0xf)
+		if cardValue == 0 {
+			break
+		}
+
+		for i, card := range cards {
+			if used&(1<<i) == 0 && card.NumValue == cardValue &&
+				(flushSuit == 0 || card.Suit == flushSuit) {
+				used |= 1 << i
+				picked = append(picked, card)
+				break
+			}
+		}
+	}
+
+	return reverseCards(picked)
+}
@@ -0,0 +1,185 @@
+package poker
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEvaluateHand(t *testing.T) {
+	// NOTE: each hand beats the one before it
+	hands := []struct {
+		cards string
+		rank  Rank
+	}{
+		{"2c 3d 4h 5s 7c 8d 9h", RankHighCard},
+		{"2c 3d 4h 6s 7c 8d Ah", RankHighCard},
+		{"2c 2d 4h 5s 7c 8d 9h", RankPair},
+		{"Ac Ad 4h 5s 7c 8d 9h", RankPair},
+		{"2c 2d 3h 3s 7c 8d 9h", RankTwoPair},
+		{"2c 2d 3h 3s 7c 8d Th", RankTwoPair},
+		{"Ac Ad Kh Ks 2c 3d 4h", RankTwoPair},
+		{"2c 2d 2h 5s 7c 8d 9h", RankTrips},
+		{"Ac 2d 3h 4s 5c 9d Jh", RankStraight},
+		{"2c 3d 4h 5s 6c 9d Jh", RankStraight},
+		{"Tc Jd Qh Ks Ac 2d 3h", RankStraight},
+		{"2c 3c 4c 5c 7c 9d Jh", RankFlush},
+		{"2c 2d 2h 3s 3c 9d Jh", RankFullHouse},
+		{"2c 2d 2h 3s 3c 3d Jh", RankFullHouse},
+		{"3c 3d 3h 4s 4c 9d Jh", RankFullHouse},
+		{"2c 2d 2h 2s 3c 4d 5h", RankQuads},
+		{"2c 2d 2h 2s Ac 4d 5h", RankQuads},
+		{"Ac 2c 3c 4c 5c 6d 7h", RankStraightFlush},
+		{"2c 3c 4c 5c 6c 7d 8h", RankStraightFlush},
+		{"Tc Jc Qc Kc Ac 2d 3h", RankRoyalFlush},
+	}
+
+	var prev HandValue
+	for i, hand := range hands {
+		value := EvaluateHand(mustCards(t, hand.cards))
+
+		if value.Rank() != hand.rank {
+			t.Errorf("%s: rank %d, want %d", hand.cards, value.Rank(), hand.rank)
+		}
+		if i > 0 && value <= prev {
+			t.Errorf("%s doesn't beat %s", hand.cards, hands[i-1].cards)
+		}
+
+		prev = value
+	}
+
+	// kickers past the best five cards don't count
+	if EvaluateHand(mustCards(t, "Ac Ad Kh Qs Jc 3d 2h")) != EvaluateHand(mustCards(t, "As Ah Kd Qc Jd 4d 3h")) {
+		t.Errorf("a sixth card played")
+	}
+}
+
+// the hands assembleHand has to make, written out by hand. best is the best
+// five cards, most important first. NOTE: suits of best are only checked for
+// flushes
+var assembleHandGolden = []struct {
+	name  string
+	rules handRules
+	cards string
+	rank  Rank
+	best  string
+}{
+	{"high card", standardHandRules, "2c 3d 5h 7s 9c Jd Kh", RankHighCard, "Kh Jd 9c 7s 5h"},
+	{"high card, five cards", standardHandRules, "Ac Kd 9h 5s 3c", RankHighCard, "Ac Kd 9h 5s 3c"},
+	{"pair kickers", standardHandRules, "Ac Ad Kh Qs Jc 3d 2h", RankPair, "Ac Ad Kh Qs Jc"},
+	{"pair, six cards", standardHandRules, "4c 4d 9h 2s 7c Jd", RankPair, "4c 4d Jd 9h 7c"},
+	{"two pair kicker", standardHandRules, "Kc Kd 9h 9s 5c 5d Ah", RankTwoPair, "Kc Kd 9h 9s Ah"},
+	{"two pair out of three", standardHandRules, "Kc Kd 9h 9s 5c 5d 2h", RankTwoPair, "Kc Kd 9h 9s 5c"},
+	{"trips kickers", standardHandRules, "7c 7d 7h As 2c 9d Th", RankTrips, "7c 7d 7h As Th"},
+	{"wheel", standardHandRules, "Ac 2d 3h 4s 5c Kd Kh", RankStraight, "5c 4s 3h 2d Ac"},
+	{"six in a row", standardHandRules, "4c 5d 6h 7s 8c 9d 2h", RankStraight, "9d 8c 7s 6h 5d"},
+	{"broadway", standardHandRules, "Tc Jd Qh Ks Ac 2d 3h", RankStraight, "Ac Ks Qh Jd Tc"},
+	{"flush out of six", standardHandRules, "2c 4c 6c 8c Tc Qc As", RankFlush, "Qc Tc 8c 6c 4c"},
+	{"flush over a straight", standardHandRules, "5c 6d 7c 8c 9h Kc Ac", RankFlush, "Ac Kc 8c 7c 5c"},
+	{"full house out of two trips", standardHandRules, "9c 9d 9h 4s 4c 4d Kh", RankFullHouse, "9c 9d 9h 4s 4c"},
+	{"full house with the higher pair", standardHandRules, "5c 5d 5h Ks Kc Qd Qh", RankFullHouse, "5c 5d 5h Ks Kc"},
+	{"quads kicker", standardHandRules, "2c 2d 2h 2s Ac Ad 5h", RankQuads, "2c 2d 2h 2s Ac"},
+	{"straight flush under a straight", standardHandRules, "5h 6h 7h 8h 9h Ts 2c", RankStraightFlush, "9h 8h 7h 6h 5h"},
+	{"steel wheel", standardHandRules, "Ah 2h 3h 4h 5h 6c Kd", RankStraightFlush, "5h 4h 3h 2h Ah"},
+	{"royal flush", standardHandRules, "Ts Js Qs Ks As 9s 2c", RankRoyalFlush, "As Ks Qs Js Ts"},
+
+	{"short deck wheel", shortDeckHandRules, "Ac 6d 7h 8s 9c Kd Kh", RankStraight, "9c 8s 7h 6d Ac"},
+	{"short deck no 2 to 5 wheel", shortDeckHandRules, "Ac 2d 3h 4s 5c Kd Qh", RankHighCard, "Ac Kd Qh 5c 4s"},
+	{"short deck steel wheel", shortDeckHandRules, "Ac 6c 7c 8c 9c Kd Jh", RankStraightFlush, "9c 8c 7c 6c Ac"},
+	{"short deck flush", shortDeckHandRules, "6c 7c 8c Tc Qc 9d Jh", RankFlush, "Qc Tc 8c 7c 6c"},
+}
+
+func TestAssembleHandGolden(t *testing.T) {
+	for _, hand := range assembleHandGolden {
+		player := &Player{}
+		assembleHand(player, mustCards(t, hand.cards), hand.rules)
+
+		best := mustCards(t, hand.best)
+		got := reverseCards(player.Hand.Cards)
+
+		if player.Hand.Rank != hand.rank {
+			t.Errorf("%s: rank %d, want %d", hand.name, player.Hand.Rank, hand.rank)
+			continue
+		}
+		if len(got) != len(best) {
+			t.Errorf("%s: got %v, want %v", hand.name, cardNames(got), cardNames(best))
+			continue
+		}
+
+		flush := hand.rank == RankFlush || hand.rank == RankStraightFlush || hand.rank == RankRoyalFlush
+		for i := range best {
+			if got[i].NumValue != best[i].NumValue || (flush && got[i].Suit != best[i].Suit) {
+				t.Errorf("%s: got %v, want %v", hand.name, cardNames(got), cardNames(best))
+				break
+			}
+		}
+	}
+}
+
+// NOTE: each hand beats the one before it
+func TestEvaluateShortDeckHand(t *testing.T) {
+	hands := []string{
+		"6c 7d 8h Ts Qc Kd Ah",
+		"6c 6d 8h Ts Qc Kd Ah",
+		"6c 6d 8h 8s Qc Kd Ah",
+		"6c 6d 6h Ts Qc Kd Ah",
+		"Ac 6d 7h 8s 9c Kd Jh",
+		"6c 7d 8h 9s Tc Kd Ah",
+		"Tc Jd Qh Ks Ac 7d 6h",
+		"6c 6d 6h 7s 7c 9d Jh",
+		"Ac Ad Ah Ks Kc 9d Jh",
+		"6c 7c 8c Tc Qc 9d Jh",
+		"6c 6d 6h 6s 7c 9d Jh",
+		"Ac 6c 7c 8c 9c Kd Jh",
+		"Tc Jc Qc Kc Ac 6d 7h",
+	}
+
+	for i := 1; i < len(hands); i++ {
+		prev := shortDeckHandRules.evaluate(mustCards(t, hands[i-1]))
+		if value := shortDeckHandRules.evaluate(mustCards(t, hands[i])); value <= prev {
+			t.Errorf("%s doesn't beat %s", hands[i], hands[i-1])
+		}
+	}
+
+	// a full house beats a flush outside short deck
+	fullHouse, flush := mustCards(t, "Ac Ad Ah Ks Kc 9d Jh"), mustCards(t, "6c 7c 8c Tc Qc 9d Jh")
+	if EvaluateHand(fullHouse) <= EvaluateHand(flush) {
+		t.Errorf("a flush beat a full house")
+	}
+}
+
+func benchmarkHands(b *testing.B, n int) []Cards {
+	b.Helper()
+
+	deck := NewDeck(NewSeededSource(1))
+
+	hands := make([]Cards, 1024)
+	for i := range hands {
+		deck.Shuffle()
+		hands[i] = deck.peek(n)
+	}
+
+	return hands
+}
+
+func BenchmarkEvaluateHand(b *testing.B) {
+	for n := 5; n <= 7; n++ {
+		b.Run(fmt.Sprintf("%d cards", n), func(b *testing.B) {
+			hands := benchmarkHands(b, n)
+
+			b.ReportAllocs()
+			for i := 0; b.Loop(); i++ {
+				EvaluateHand(hands[i%len(hands)])
+			}
+		})
+	}
+}
+
+func BenchmarkAssembleHand(b *testing.B) {
+	hands := benchmarkHands(b, 7)
+	player := &Player{}
+
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		assembleHand(player, hands[i%len(hands)], standardHandRules)
+	}
+}
@@ -66,7 +66,7 @@ func mustCards(t *testing.T, cards string) Cards {
 func mustPlayerWithHole(t *testing.T, name, hole string) *Player {
 	t.Helper()
 
-	player := NewPlayer(name, false)
+	player := NewPlayer(name, false, DefaultStartingStack)
 	player.IsVacant = false
 	player.Hole.Cards = mustCards(t, hole)
 	player.Hole.FillHoleInfo()
@@ -79,7 +79,7 @@ func mustPlayerWithHole(t *testing.T, name, hole string) *Player {
 func mustPlayerWithHand(t *testing.T, name string, rank Rank, cards string) *Player {
 	t.Helper()
 
-	player := NewPlayer(name, false)
+	player := NewPlayer(name, false, DefaultStartingStack)
 	player.IsVacant = false
 	player.Hand = &Hand{
 		Rank:  rank,
@@ -93,6 +93,7 @@ func newTestTable(t *testing.T, community string) *Table {
 	t.Helper()
 
 	return &Table{
+		variant:   Holdem{},
 		Community: mustCards(t, community),
 		State:     TableStateRiver,
 	}
@@ -286,6 +287,27 @@ func TestAssembleBestHandEdgeCases(t *testing.T) {
 	})
 }
 
+// NOTE: the evaluator before EvaluateHand() only looked for a straight flush
+// in the highest straight, so these were ranked as flushes
+func TestAssembleBestHandStraightFlushUnderStraight(t *testing.T) {
+	runAssembleBestHandCases(t, []assembleBestHandCase{
+		{
+			name:      "straight flush under a higher straight",
+			community: "6h 7h 8h 9h Tc",
+			hole:      "5h Kd",
+			wantRank:  RankStraightFlush,
+			wantCards: []CardVal{CardFive, CardSix, CardSeven, CardEight, CardNine},
+		},
+		{
+			name:      "wheel straight flush with a six",
+			community: "2d 3d 4d 5d 6h",
+			hole:      "Ad 8c",
+			wantRank:  RankStraightFlush,
+			wantCards: []CardVal{CardAce, CardTwo, CardThree, CardFour, CardFive},
+		},
+	})
+}
+
 func TestBestHandRanksAllCategories(t *testing.T) {
 	fixtures := []struct {
 		name  string
@@ -0,0 +1,132 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func TestHeadsUpBlinds(t *testing.T) {
+	table := newTestGame(t, 1000, 1000)
+
+	if table.Game != (Holdem{}).Name() || table.NumSeats != 2 {
+		t.Fatalf("expected a 2 seat hold'em table, got %d seat %s", table.NumSeats, table.Game)
+	}
+
+	// NOTE: the dealer posts the small blind and the button moves every hand
+	dealers := []string{"p0", "p1", "p0", "p1"}
+	for hand, dealer := range dealers {
+		if hand > 0 {
+			nextHand(t, table)
+		}
+
+		if got := nodeName(table.Dealer); got != dealer {
+			t.Fatalf("hand %d: dealer is %s, want %s", hand+1, got, dealer)
+		}
+		if table.SmallBlind != table.Dealer {
+			t.Fatalf("hand %d: small blind is %s, want the dealer", hand+1, nodeName(table.SmallBlind))
+		}
+		if table.BigBlind == table.Dealer {
+			t.Fatalf("hand %d: the dealer is the big blind", hand+1)
+		}
+
+		if got, want := table.SmallBlind.Player.Action.Amount, table.Blinds.SmallBlind; got != want {
+			t.Fatalf("hand %d: dealer posted %d, want %d", hand+1, got, want)
+		}
+		if got, want := table.BigBlind.Player.Action.Amount, table.Blinds.BigBlind; got != want {
+			t.Fatalf("hand %d: big blind posted %d, want %d", hand+1, got, want)
+		}
+	}
+}
+
+func TestHeadsUpActionOrder(t *testing.T) {
+	const (
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	table := newTestGame(t, 1000, 1000)
+
+	// NOTE: an empty step deals the next street. the dealer (p0) acts first
+	//       preflop and last after the flop
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: call},
+		{player: "p1", action: check},
+		{},
+		{player: "p1", action: check},
+		{player: "p0", action: check},
+		{},
+		{player: "p1", action: playerState.Bet, amount: 20},
+		{player: "p0", action: call},
+		{},
+		{player: "p1", action: check},
+		{player: "p0", action: check},
+	})
+
+	if table.State != TableStateDoneBetting {
+		t.Fatalf("betting isn't done on the river: %s", table.TableStateToString())
+	}
+}
+
+// when a game goes from three players to two the big blind keeps moving
+// and the other player gets the button
+func TestThreeToHeadsUp(t *testing.T) {
+	const (
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	// NOTE: the first hand has p0 on the button, p1 in the small blind and
+	//       p2 in the big blind
+	tests := []struct {
+		name     string
+		leaves   string
+		dealer   string
+		bigBlind string
+	}{
+		{name: "dealer leaves", leaves: "p0", dealer: "p2", bigBlind: "p1"},
+		{name: "small blind leaves", leaves: "p1", dealer: "p2", bigBlind: "p0"},
+		{name: "big blind leaves", leaves: "p2", dealer: "p1", bigBlind: "p0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestGame(t, 1000, 1000, 1000)
+
+			testRemovePlayer(table, testPlayersByName(table)[tt.leaves])
+
+			nextHand(t, table)
+
+			if got := nodeName(table.Dealer); got != tt.dealer {
+				t.Fatalf("dealer is %s, want %s", got, tt.dealer)
+			}
+			if got := nodeName(table.SmallBlind); got != tt.dealer {
+				t.Fatalf("small blind is %s, want the dealer %s", got, tt.dealer)
+			}
+			if got := nodeName(table.BigBlind); got != tt.bigBlind {
+				t.Fatalf("big blind is %s, want %s", got, tt.bigBlind)
+			}
+			if table.DeadButton || table.DeadSmallBlind {
+				t.Fatalf("dead button or small blind heads up")
+			}
+
+			runBettingSteps(t, table, []bettingStep{
+				{player: tt.dealer, action: call},
+				{player: tt.bigBlind, action: check},
+				{},
+				{player: tt.bigBlind, action: check},
+				{player: tt.dealer, action: check},
+			})
+
+			// the next hand swaps the button
+			nextHand(t, table)
+
+			if got := nodeName(table.Dealer); got != tt.bigBlind {
+				t.Fatalf("next hand: dealer is %s, want %s", got, tt.bigBlind)
+			}
+			if got := nodeName(table.BigBlind); got != tt.dealer {
+				t.Fatalf("next hand: big blind is %s, want %s", got, tt.dealer)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package poker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HiLoVariant is a variant where each pot is split between the best high hand
+// and the best eight or better low hand. when nobody makes a low the best
+// high hand wins the whole pot.
+type HiLoVariant interface {
+	Variant
+
+	// ranks the best low hand player can make into player.LowHand.
+	// player.LowHand is left nil if the player has no qualifying low.
+	RankLowHand(table *Table, player *Player)
+}
+
+// the highest card allowed in a low hand
+const lowHandMaxCard = CardEight
+
+func (table *Table) isHiLo() bool {
+	_, ok := table.Variant().(HiLoVariant)
+
+	return ok
+}
+
+// value of card in a low hand. aces are low.
+func lowValue(card *Card) CardVal {
+	if card.NumValue == CardAce {
+		return CardAceLow
+	}
+
+	return card.NumValue
+}
+
+// the best eight or better low that can be made out of cards: the five lowest
+// cards of different values, none higher than an eight. straights and flushes
+// don't count against a low. returns nil if there's no qualifying low.
+//
+// NOTE: the cards are in ascending order like a high hand's, so the card
+// compared first is last.
+func lowHand(cards Cards) *Hand {
+	sorted := append(Cards{}, cards...)
+	slices.SortStableFunc(sorted, func(a, b *Card) int {
+		return int(lowValue(a)) - int(lowValue(b))
+	})
+
+	hand := &Hand{Rank: RankHighCard, Cards: make(Cards, 0, 5)}
+	for _, card := range sorted {
+		if len(hand.Cards) == 5 || lowValue(card) > lowHandMaxCard {
+			break
+		} else if len(hand.Cards) > 0 && lowValue(hand.Cards[len(hand.Cards)-1]) == lowValue(card) {
+			continue // pairs don't count
+		}
+
+		hand.Cards = append(hand.Cards, card)
+	}
+
+	if len(hand.Cards) < 5 {
+		return nil
+	}
+
+	return hand
+}
+
+// returns a positive number if hand is a better (lower) low than otherHand, a
+// negative number if it's worse and 0 on a tie. lows are compared from the
+// highest card down.
+func compareLowHands(hand, otherHand *Hand) int {
+	for i := len(hand.Cards) - 1; i >= 0; i-- {
+		if val, otherVal := lowValue(hand.Cards[i]), lowValue(otherHand.Cards[i]); val != otherVal {
+			return int(otherVal) - int(val)
+		}
+	}
+
+	return 0
+}
+
+// LowName returns a low hand the way it's read out, e.g. "8-6-4-3-A"
+func (hand *Hand) LowName() string {
+	names := make([]string, 0, len(hand.Cards))
+	for _, card := range reverseCards(hand.Cards) {
+		names = append(names, cardValueName(card))
+	}
+
+	return strings.Join(names, "-")
+}
+
+// the card's value without its suit, e.g. "10" for a ten
+func cardValueName(card *Card) string {
+	value, _, _ := strings.Cut(card.Name, " ")
+
+	return value
+}
+
+// the players with the best low hand, ties included. returns nil if none of
+// the players has a low. the hands need to be ranked already, see BestHand().
+func (table *Table) BestLowHand(players []*Player, sidePot *SidePot) []*Player {
+	winInfo := &table.WinInfo
+	if sidePot != nil {
+		winInfo = &sidePot.WinInfo
+	}
+
+	var tiedPlayers []*Player
+	for _, player := range players {
+		if player.LowHand == nil {
+			continue
+		}
+
+		if len(tiedPlayers) == 0 {
+			tiedPlayers = []*Player{player}
+		} else if cmp := compareLowHands(player.LowHand, tiedPlayers[0].LowHand); cmp == 0 {
+			tiedPlayers = append(tiedPlayers, player)
+		} else if cmp > 0 {
+			tiedPlayers = []*Player{player}
+		}
+	}
+
+	if len(tiedPlayers) == 0 {
+		*winInfo += "\nlow: no qualifying low\n"
+		log.Info().Msg("no qualifying low")
+
+		return nil
+	}
+
+	names := make([]string, 0, len(tiedPlayers))
+	for _, player := range tiedPlayers {
+		names = append(names, player.Name)
+	}
+
+	lowName := tiedPlayers[0].LowHand.LowName()
+	if len(tiedPlayers) > 1 {
+		*winInfo += fmt.Sprintf("\nlow: split between %s\nwinning low => %s\n", strings.Join(names, " "), lowName)
+	} else {
+		*winInfo += fmt.Sprintf("\nlow: %s  wins with %s\n", names[0], lowName)
+	}
+
+	log.Info().
+		Strs("players", names).
+		Str("low", lowName).
+		Msg("low winner")
+
+	return tiedPlayers
+}
+
+// awards a pot to the best high hand among players. in hi-lo games the pot is
+// split in half between the best high and the best low hand instead, and each
+// half is split again between tied players, so a pot can be quartered.
+// returns every player that won part of the pot.
+//
+// odd chips: when a pot is split between the high and the low hand the odd
+// chip goes to the high hand. when tied players split a pot (or half a pot)
+// the odd chips go one each to the tied players starting with the first one
+// left of the button.
+func (table *Table) awardPot(total Chips, players []*Player, sidePot *SidePot) []*Player {
+	potName := "mainpot"
+	if sidePot != nil {
+		potName = sidePot.Name
+	}
+
+	highWinners := table.BestHand(players, sidePot)
+
+	var lowWinners []*Player
+	if table.isHiLo() {
+		lowWinners = table.BestLowHand(players, sidePot)
+	}
+
+	chips := make(map[*Player]Chips, len(players))
+	for _, player := range players {
+		chips[player] = player.ChipCount
+	}
+
+	winners := highWinners
+	if len(lowWinners) == 0 {
+		splitChips(potName, total, table.seatOrder(highWinners))
+	} else {
+		lowHalf := total / 2
+		splitChips(potName, total-lowHalf, table.seatOrder(highWinners))
+		splitChips(potName, lowHalf, table.seatOrder(lowWinners))
+
+		winners = append([]*Player{}, highWinners...)
+		for _, player := range lowWinners {
+			if !slices.Contains(winners, player) {
+				winners = append(winners, player)
+			}
+		}
+	}
+
+	for _, player := range table.seatOrder(players) {
+		table.history.award(player, table.historyPot(sidePot), player.ChipCount-chips[player])
+	}
+
+	return winners
+}
+
+// splits chips evenly between players. the odd chips left over go one each
+// to the players in the order they're given.
+func splitChips(potName string, chips Chips, players []*Player) {
+	share := chips / Chips(len(players))
+	oddChips := chips % Chips(len(players))
+
+	for i, player := range players {
+		player.ChipCount += share
+		if Chips(i) < oddChips {
+			player.ChipCount++
+		}
+	}
+
+	if len(players) > 1 {
+		log.Debug().Str("pot", potName).
+			Str("share", printer.Sprintf("%d", share)).
+			Str("oddChips", printer.Sprintf("%d", oddChips)).
+			Msg("split chips")
+	}
+}
@@ -0,0 +1,198 @@
+package poker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLowHand(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards string
+		want  string // empty means no qualifying low
+	}{
+		{"wheel", "As 2c 3d 4h 5s", "5-4-3-2-A"},
+		{"eight low", "8c 6d 4h 3s Ac", "8-6-4-3-A"},
+		{"nine doesn't qualify", "9c 6d 4h 3s Ac", ""},
+		{"pairs don't count", "Ac Ad 2h 3s 4c Kd 4s", ""},
+		{"best five of seven", "Ac 2d 8h 7s 3c 6d 4s", "6-4-3-2-A"},
+		{"paired cards are skipped", "Ac As 2d 2h 3c 5s 7d", "7-5-3-2-A"},
+		{"flushes don't count against a low", "Ah 2h 3h 4h 6h", "6-4-3-2-A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hand := lowHand(mustCards(t, tt.cards))
+
+			got := ""
+			if hand != nil {
+				got = hand.LowName()
+			}
+
+			if got != tt.want {
+				t.Fatalf("low mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareLowHands(t *testing.T) {
+	tests := []struct {
+		hand, otherHand string
+		want            int // sign of the result
+	}{
+		{"As 2c 3d 4h 5s", "As 2c 3d 4h 6s", 1},
+		{"8c 5d 4h 3s 2c", "8c 6d 4h 3s Ac", 1},
+		{"7c 6d 4h 3s 2c", "8c 3d 4h 2s Ac", 1},
+		{"8c 6d 4h 3s Ac", "8d 6h 4s 3c As", 0},
+	}
+
+	for _, tt := range tests {
+		got := compareLowHands(lowHand(mustCards(t, tt.hand)), lowHand(mustCards(t, tt.otherHand)))
+		if got > 0 {
+			got = 1
+		} else if got < 0 {
+			got = -1
+		}
+
+		if got != tt.want {
+			t.Errorf("compareLowHands(%s, %s) = %d, want %d", tt.hand, tt.otherHand, got, tt.want)
+		}
+	}
+}
+
+func TestOmahaHiLoLowUsesTwoHoleCards(t *testing.T) {
+	tests := []struct {
+		name      string
+		community string
+		hole      string
+		want      string
+	}{
+		{"two low hole cards", "2c 3d 7h Kc Ks", "Ah 4h Kd Qd", "7-4-3-2-A"},
+		{"one low hole card isn't enough", "2c 3d 4h 5s Ks", "Ah Kd Qd Jd", ""},
+		{"only three board cards play", "Ac 2d 3h 4s 5c", "6h 7d Kc Kd", "7-6-3-2-A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestTable(t, tt.community)
+			table.variant = OmahaHiLo{}
+			player := mustPlayerWithHole(t, "p1", tt.hole)
+
+			AssembleBestHand(false, table, player)
+
+			got := ""
+			if player.LowHand != nil {
+				got = player.LowHand.LowName()
+			}
+
+			if got != tt.want {
+				t.Fatalf("low mismatch: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHiLoAwardPot(t *testing.T) {
+	tests := []struct {
+		name      string
+		variant   Variant
+		community string
+		holes     []string
+		pot       Chips
+		want      []Chips // chips won by each player
+		wantInfo  []string
+	}{
+		{
+			name:      "high and low split the pot",
+			variant:   OmahaHiLo{},
+			community: "2c 3d 7h Kc Ks",
+			holes:     []string{"Kd Qd Jh Th", "Ah 4h 9c 9d"},
+			pot:       100,
+			want:      []Chips{50, 50},
+			wantInfo:  []string{"high: p0  wins with three of a kind", "low: p1  wins with 7-4-3-2-A"},
+		},
+		{
+			name:      "odd chip goes to the high hand",
+			variant:   OmahaHiLo{},
+			community: "2c 3d 7h Kc Ks",
+			holes:     []string{"Kd Qd Jh Th", "Ah 4h 9c 9d"},
+			pot:       101,
+			want:      []Chips{51, 50},
+		},
+		{
+			name:      "scoop",
+			variant:   OmahaHiLo{},
+			community: "2c 3d 7h Kc Qs",
+			holes:     []string{"Ah 4h Kd Kh", "9c 9d Jh Th"},
+			pot:       100,
+			want:      []Chips{100, 0},
+		},
+		{
+			name:      "no low, high takes the pot",
+			variant:   OmahaHiLo{},
+			community: "9c Td 7h Kc Ks",
+			holes:     []string{"Kd Qd Jh Th", "Ah 4h 2c 3d"},
+			pot:       100,
+			want:      []Chips{100, 0},
+			wantInfo:  []string{"low: no qualifying low"},
+		},
+		{
+			name:      "quartered",
+			variant:   OmahaHiLo{},
+			community: "2c 3d 7h Kc Ks",
+			holes:     []string{"Ah 4h Kd Qd", "As 4s Jc Jd", "9c 9d 8c 8d"},
+			pot:       100,
+			want:      []Chips{75, 25, 0},
+			wantInfo:  []string{"low: split between p0 p1"},
+		},
+		{
+			name:      "quartered with odd chips",
+			variant:   OmahaHiLo{},
+			community: "2c 3d 7h Kc Ks",
+			holes:     []string{"Ah 4h Kd Qd", "As 4s Jc Jd", "9c 9d 8c 8d"},
+			pot:       102,
+			want:      []Chips{77, 25, 0},
+		},
+		{
+			name:     "stud hi-lo",
+			variant:  SevenCardStudHiLo{},
+			holes:    []string{"Ac 2d 3h 4s 8c 8d 8h", "Kc Kd 2c 3d 5h 6s 7c"},
+			pot:      100,
+			want:     []Chips{50, 50},
+			wantInfo: []string{"high: p0  wins with three of a kind", "low: p1  wins with 7-6-5-3-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestTable(t, tt.community)
+			table.variant = tt.variant
+
+			players := make([]*Player, 0, len(tt.holes))
+			for i, hole := range tt.holes {
+				player := mustPlayerWithHole(t, "p"+string(rune('0'+i)), hole)
+				player.ChipCount = 0
+				players = append(players, player)
+			}
+
+			table.awardPot(tt.pot, players, nil)
+
+			got := make([]Chips, 0, len(players))
+			for _, player := range players {
+				got = append(got, player.ChipCount)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("chips won mismatch: got %v, want %v\n%s", got, tt.want, table.WinInfo)
+			}
+
+			for _, info := range tt.wantInfo {
+				if !strings.Contains(table.WinInfo, info) {
+					t.Errorf("WinInfo doesn't contain %q:\n%s", info, table.WinInfo)
+				}
+			}
+		})
+	}
+}
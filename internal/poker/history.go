@@ -0,0 +1,379 @@
+package poker
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+// HandHistory is the record of a single hand: who was dealt in and with how
+// many chips, every forced bet, card and action in the order they happened,
+// the showdown and who won what. see WritePokerStars() and MarshalJSON()
+type HandHistory struct {
+	ID     uint64    `json:"id"`             // unique across tables and restarts. used as the PokerStars hand number
+	Number uint64    `json:"number"`         // hand number at the table
+	Table  string    `json:"room"`           // name of the table. left to the caller, e.g. the room name
+	Seed   string    `json:"seed,omitempty"` // the shuffle seed in commit-reveal mode. left to the caller, see Table.DeckSeed()
+	Start  time.Time `json:"start"`
+
+	Game     string           `json:"game"` // name of the variant
+	Stud     bool             `json:"stud"`
+	Betting  BettingStructure `json:"-"` // see MarshalJSON()
+	Blinds   Blinds           `json:"blinds"`
+	NumSeats uint8            `json:"numSeats"`
+
+	// seats of the button & blinds, 0 if there's none
+	Button     uint `json:"button,omitempty"`
+	SmallBlind uint `json:"smallBlind,omitempty"`
+	BigBlind   uint `json:"bigBlind,omitempty"`
+
+	Seats  []HistorySeat  `json:"seats"`
+	Events []HistoryEvent `json:"actions"`
+	Boards []Cards        `json:"boards,omitempty"` // the final board, or each run's board when the board was run more than once
+	Shows  []HistoryShow  `json:"shows,omitempty"`
+	Awards []HistoryAward `json:"awards"`
+
+	street     TableState       // the street being played
+	run        int              // the run of the board being dealt
+	streetBets map[string]Chips // chips each player has put in on the street, antes left out
+}
+
+// HistorySeat is a player dealt into a hand
+type HistorySeat struct {
+	Seat  uint   `json:"seat"` // Player.TablePos + 1
+	Name  string `json:"name"`
+	Chips Chips  `json:"chips"` // chips at the start of the hand
+}
+
+type HistoryEventKind uint8
+
+const (
+	HistoryPostAnte HistoryEventKind = iota
+	HistoryPostSmallBlind
+	HistoryPostBigBlind // includes big blinds posted by players back from sitting out
+	HistoryPostStraddle
+	HistoryBringIn
+	HistoryDeal   // cards dealt to Player
+	HistoryStreet // a new street. Cards are the new community cards
+	HistoryFold
+	HistoryCheck
+	HistoryCall
+	HistoryBet
+	HistoryRaise
+	HistoryUncalledBet // Amount was returned to Player
+)
+
+// HistoryEvent is something that happened during a hand
+type HistoryEvent struct {
+	Kind   HistoryEventKind `json:"action"`
+	Street TableState       `json:"-"`             // the street it happened on. see MarshalJSON()
+	Run    int              `json:"run,omitempty"` // the run of the board, see Table.Boards
+
+	Player string `json:"player,omitempty"`
+	Amount Chips  `json:"amount,omitempty"` // chips put in, or returned with HistoryUncalledBet
+	To     Chips  `json:"to,omitempty"`     // the player's bet for the street after a bet or raise
+	AllIn  bool   `json:"allIn,omitempty"`
+
+	Cards   Cards `json:"cards,omitempty"`   // dealt down cards, or a street's new community cards
+	UpCards Cards `json:"upCards,omitempty"` // dealt up cards (stud)
+	Board   Cards `json:"board,omitempty"`   // every community card after a street is dealt
+}
+
+// HistoryShow is a player's hand at showdown
+type HistoryShow struct {
+	Player  string `json:"player"`
+	Mucked  bool   `json:"mucked,omitempty"`
+	Cards   Cards  `json:"cards,omitempty"` // every card the player holds, up cards included
+	Hand    *Hand  `json:"hand,omitempty"`
+	LowHand *Hand  `json:"lowHand,omitempty"`
+}
+
+// HistoryAward is chips a player won from a pot
+type HistoryAward struct {
+	Player string `json:"player"`
+	Pot    int    `json:"pot"` // 0 is the main pot, side pots count from 1
+	Amount Chips  `json:"amount"`
+}
+
+var lastHandID atomic.Uint64
+
+// hand ids count up from the time of the first hand so they don't repeat
+// when the server restarts
+func newHandID(now time.Time) uint64 {
+	for {
+		last := lastHandID.Load()
+		id := max(last+1, uint64(now.UnixMicro()))
+		if lastHandID.CompareAndSwap(last, id) {
+			return id
+		}
+	}
+}
+
+// HandHistory returns the history of the current hand, or the last one once
+// the hand is over. nil before the first hand is dealt.
+func (table *Table) HandHistory() *HandHistory {
+	return table.history
+}
+
+// starts the history of a new hand. called once the players dealt in are
+// known, before any chips go in. see startHandChips()
+func (table *Table) startHandHistory() {
+	now := time.Now()
+
+	history := &HandHistory{
+		ID:       newHandID(now),
+		Number:   table.roundCount,
+		Start:    now,
+		Game:     table.Game,
+		Stud:     table.isStud(),
+		Betting:  table.Betting,
+		Blinds:   table.Blinds,
+		NumSeats: table.NumSeats,
+
+		street:     TableStatePreFlop,
+		streetBets: make(map[string]Chips),
+	}
+
+	table.HandID = history.ID
+
+	if !history.Stud {
+		history.Button = nodeSeat(table.Dealer)
+		history.SmallBlind = nodeSeat(table.SmallBlind)
+		history.BigBlind = nodeSeat(table.BigBlind)
+	}
+
+	for _, player := range table.handPlayers {
+		history.Seats = append(history.Seats, HistorySeat{
+			Seat:  player.TablePos + 1,
+			Name:  player.Name,
+			Chips: player.ChipCount,
+		})
+	}
+	slices.SortFunc(history.Seats, func(a, b HistorySeat) int {
+		return int(a.Seat) - int(b.Seat)
+	})
+
+	table.history = history
+}
+
+func nodeSeat(node *PlayerNode) uint {
+	if node == nil {
+		return 0
+	}
+
+	return node.Player.TablePos + 1
+}
+
+// NOTE: the recording methods below do nothing on a nil history, e.g. on a
+// table that hasn't dealt a hand yet
+
+func (history *HandHistory) add(event HistoryEvent) {
+	event.Street = history.street
+	event.Run = history.run
+
+	history.Events = append(history.Events, event)
+}
+
+// records a forced bet that player already posted
+func (history *HandHistory) post(kind HistoryEventKind, player *Player, amount Chips) {
+	if history == nil || amount == 0 {
+		return
+	}
+
+	if kind != HistoryPostAnte {
+		history.streetBets[player.Name] += amount
+	}
+
+	history.add(HistoryEvent{
+		Kind:   kind,
+		Player: player.Name,
+		Amount: amount,
+		AllIn:  player.ChipCount == 0,
+	})
+}
+
+func (history *HandHistory) deal(player *Player, down, up Cards) {
+	if history == nil || len(down)+len(up) == 0 {
+		return
+	}
+
+	history.add(HistoryEvent{
+		Kind:    HistoryDeal,
+		Player:  player.Name,
+		Cards:   append(Cards{}, down...),
+		UpCards: append(Cards{}, up...),
+	})
+}
+
+// starts a new street. the community cards are added as they're dealt, see
+// community()
+func (history *HandHistory) newStreet(state TableState, run int) {
+	if history == nil {
+		return
+	}
+
+	history.street, history.run = state, run
+	clear(history.streetBets)
+
+	history.add(HistoryEvent{Kind: HistoryStreet})
+}
+
+// adds newly dealt community cards to the current street
+func (history *HandHistory) community(cards, board Cards) {
+	if history == nil || len(history.Events) == 0 {
+		return
+	}
+
+	event := &history.Events[len(history.Events)-1]
+	if event.Kind != HistoryStreet {
+		history.add(HistoryEvent{Kind: HistoryStreet})
+		event = &history.Events[len(history.Events)-1]
+	}
+
+	event.Cards = append(event.Cards, cards...)
+	event.Board = append(Cards{}, board...)
+}
+
+// records what player just did. chips is the player's chip count before the
+// action.
+func (history *HandHistory) action(player *Player, chips Chips) {
+	if history == nil {
+		return
+	}
+
+	var bet Chips // the bet to call before the action
+	for _, streetBet := range history.streetBets {
+		bet = max(bet, streetBet)
+	}
+
+	put := chips - player.ChipCount
+	history.streetBets[player.Name] += put
+
+	event := HistoryEvent{
+		Player: player.Name,
+		Amount: put,
+		AllIn:  player.ChipCount == 0 && put > 0,
+	}
+
+	switch to := history.streetBets[player.Name]; {
+	case player.Action.Action == playerState.Fold:
+		event.Kind = HistoryFold
+	case put == 0:
+		event.Kind = HistoryCheck
+	case to <= bet:
+		event.Kind = HistoryCall
+	case bet == 0:
+		event.Kind = HistoryBet
+	default:
+		event.Kind = HistoryRaise
+		event.Amount, event.To = to-bet, to
+	}
+
+	history.add(event)
+}
+
+// records the pot won by folds. the part of the winner's last bet nobody
+// called goes back to them.
+func (history *HandHistory) winByFolds(winner *Player, pot Chips) {
+	if history == nil {
+		return
+	}
+
+	var called Chips
+	for name, bet := range history.streetBets {
+		if name != winner.Name {
+			called = max(called, bet)
+		}
+	}
+
+	if uncalled := history.streetBets[winner.Name] - min(called, history.streetBets[winner.Name]); uncalled > 0 {
+		history.add(HistoryEvent{
+			Kind:   HistoryUncalledBet,
+			Player: winner.Name,
+			Amount: uncalled,
+		})
+		pot -= uncalled
+	}
+
+	history.award(winner, 0, pot)
+}
+
+func (history *HandHistory) award(player *Player, pot int, amount Chips) {
+	if history == nil || amount == 0 {
+		return
+	}
+
+	history.Awards = append(history.Awards, HistoryAward{
+		Player: player.Name,
+		Pot:    pot,
+		Amount: amount,
+	})
+}
+
+// keeps the board, or each run's board, once the hand is decided
+func (history *HandHistory) finish(boards []Cards) {
+	if history == nil {
+		return
+	}
+
+	history.Boards = nil
+	for _, board := range boards {
+		history.Boards = append(history.Boards, append(Cards{}, board...))
+	}
+}
+
+func (history *HandHistory) showdown(shown, mucked []*Player) {
+	if history == nil {
+		return
+	}
+
+	for _, player := range shown {
+		history.Shows = append(history.Shows, HistoryShow{
+			Player:  player.Name,
+			Cards:   player.allCards(),
+			Hand:    player.Hand,
+			LowHand: player.LowHand,
+		})
+	}
+
+	for _, player := range mucked {
+		history.Shows = append(history.Shows, HistoryShow{
+			Player: player.Name,
+			Mucked: true,
+		})
+	}
+}
+
+// records a hand shown once the hand was over, see Table.ShowHand(). it takes
+// the place of the player's muck at showdown, if they mucked.
+func (history *HandHistory) show(player *Player) {
+	if history == nil {
+		return
+	}
+
+	show := HistoryShow{
+		Player:  player.Name,
+		Cards:   player.allCards(),
+		Hand:    player.Hand,
+		LowHand: player.LowHand,
+	}
+
+	if i := slices.IndexFunc(history.Shows, func(show HistoryShow) bool {
+		return show.Player == player.Name
+	}); i != -1 {
+		history.Shows[i] = show
+	} else {
+		history.Shows = append(history.Shows, show)
+	}
+}
+
+// the history number of sidePot, see HistoryAward.Pot
+func (table *Table) historyPot(sidePot *SidePot) int {
+	if sidePot == nil {
+		return 0
+	}
+
+	return slices.Index(table.sidePots.GetAllPots(), sidePot) + 1
+}
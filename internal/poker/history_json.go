@@ -0,0 +1,140 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+var historyEventKindNames = map[HistoryEventKind]string{
+	HistoryPostAnte:       "ante",
+	HistoryPostSmallBlind: "small blind",
+	HistoryPostBigBlind:   "big blind",
+	HistoryPostStraddle:   "straddle",
+	HistoryBringIn:        "bring-in",
+	HistoryDeal:           "deal",
+	HistoryStreet:         "street",
+	HistoryFold:           "fold",
+	HistoryCheck:          "check",
+	HistoryCall:           "call",
+	HistoryBet:            "bet",
+	HistoryRaise:          "raise",
+	HistoryUncalledBet:    "uncalled bet",
+}
+
+func (kind HistoryEventKind) String() string {
+	if name, ok := historyEventKindNames[kind]; ok {
+		return name
+	}
+
+	return "invalid history event"
+}
+
+func (kind HistoryEventKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(kind.String())
+}
+
+func (kind *HistoryEventKind) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	for k, kindName := range historyEventKindNames {
+		if kindName == name {
+			*kind = k
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown history event '%s'", name)
+}
+
+// MarshalJSON writes the betting structure by name, e.g. "pot-limit", and
+// cards by their codes, see Card.Code()
+func (history HandHistory) MarshalJSON() ([]byte, error) {
+	type handHistory HandHistory
+
+	return json.Marshal(struct {
+		handHistory
+		Betting string `json:"betting"`
+	}{handHistory(history), history.Betting.String()})
+}
+
+func (history *HandHistory) UnmarshalJSON(data []byte) error {
+	type handHistory HandHistory
+
+	aux := struct {
+		*handHistory
+		Betting string `json:"betting"`
+	}{handHistory: (*handHistory)(history)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	betting, err := ParseBettingStructure(aux.Betting)
+	if err != nil {
+		return err
+	}
+	history.Betting = betting
+
+	return nil
+}
+
+// MarshalJSON writes the street by name, e.g. "flop"
+func (event HistoryEvent) MarshalJSON() ([]byte, error) {
+	type historyEvent HistoryEvent
+
+	return json.Marshal(struct {
+		historyEvent
+		Street string `json:"street"`
+	}{historyEvent(event), tableStateNames[event.Street]})
+}
+
+func (event *HistoryEvent) UnmarshalJSON(data []byte) error {
+	type historyEvent HistoryEvent
+
+	aux := struct {
+		*historyEvent
+		Street string `json:"street"`
+	}{historyEvent: (*historyEvent)(event)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for state, name := range tableStateNames {
+		if name == aux.Street {
+			event.Street = state
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown street '%s'", aux.Street)
+}
+
+// VisibleTo returns a copy of the hand with only the hole cards player got
+// to see: their own and the ones shown down. up cards are always visible. an
+// empty player sees the hand like a spectator.
+func (history *HandHistory) VisibleTo(player string) *HandHistory {
+	shown := make(map[string]bool)
+	for _, show := range history.Shows {
+		if !show.Mucked {
+			shown[show.Player] = true
+		}
+	}
+
+	visible := *history
+	visible.Events = slices.Clone(history.Events)
+
+	for i, event := range visible.Events {
+		if event.Kind == HistoryDeal && event.Player != player && !shown[event.Player] {
+			visible.Events[i].Cards = nil
+		}
+	}
+
+	return &visible
+}
@@ -0,0 +1,183 @@
+package poker
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func pokerStarsText(t *testing.T, table *Table, hero string) string {
+	t.Helper()
+
+	var hh strings.Builder
+	if err := table.HandHistory().WritePokerStars(&hh, hero); err != nil {
+		t.Fatalf("WritePokerStars: %v", err)
+	}
+
+	return hh.String()
+}
+
+func checkHistoryLines(t *testing.T, hh string, want []string) {
+	t.Helper()
+
+	// NOTE: lines have to be in order but can have others in between
+	rest := hh
+	for _, line := range want {
+		i := strings.Index(rest, line+"\n")
+		if i == -1 {
+			t.Fatalf("missing or out of order line %q in:\n%s", line, hh)
+		}
+		rest = rest[i+len(line):]
+	}
+}
+
+// plays a hand to showdown. p0 folds 2c 3d on the flop, p1's aces lose to
+// p2's set of kings
+func playShowdownHand(t *testing.T) *Table {
+	t.Helper()
+
+	const (
+		bet   = playerState.Bet
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	// NOTE: a burn card before every street
+	deck := stackedDeck(t, "2c 3d Ac Ad Kc Kd 8s Kh Qs 9c 7s 5d 7h 4h")
+	table := newVariantTestGame(t, Holdem{}, deck, DefaultBlinds, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: bet, amount: 20},
+		{player: "p1", action: call},
+		{player: "p2", action: call},
+		{},
+		{player: "p1", action: bet, amount: 50},
+		{player: "p2", action: bet, amount: 150},
+		{player: "p0", action: playerState.Fold},
+		{player: "p1", action: call},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+	})
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+	table.Showdown(func(*Player) bool { return false })
+
+	return table
+}
+
+func TestHandHistoryShowdown(t *testing.T) {
+	table := playShowdownHand(t)
+
+	hh := pokerStarsText(t, table, "p0")
+
+	checkHistoryLines(t, hh, []string{
+		"Table 'gopoker' 3-max Seat #1 is the button",
+		"Seat 1: p0 (1000 in chips)",
+		"p1: posts small blind 5",
+		"p2: posts big blind 10",
+		"*** HOLE CARDS ***",
+		"Dealt to p0 [2c 3d]",
+		"p0: raises 10 to 20",
+		"p1: calls 15",
+		"p2: calls 10",
+		"*** FLOP *** [Kh Qs 9c]",
+		"p1: bets 50",
+		"p2: raises 100 to 150",
+		"p0: folds",
+		"p1: calls 100",
+		"*** TURN *** [Kh Qs 9c] [5d]",
+		"*** RIVER *** [Kh Qs 9c 5d] [4h]",
+		"*** SHOW DOWN ***",
+		"p1: shows [Ac Ad] (a pair of Aces)",
+		"p2: shows [Kc Kd] (three of a kind, Kings)",
+		"p2 collected 360 from pot",
+		"*** SUMMARY ***",
+		"Total pot 360 | Rake 0",
+		"Board [Kh Qs 9c 5d 4h]",
+		"Seat 1: p0 (button) folded on the Flop",
+		"Seat 2: p1 (small blind) showed [Ac Ad] and lost with a pair of Aces",
+		"Seat 3: p2 (big blind) showed [Kc Kd] and won (360) with three of a kind, Kings",
+	})
+
+	// NOTE: only the hero's hole cards are dealt face up
+	if strings.Count(hh, "Dealt to") != 1 || !strings.Contains(hh, "Dealt to p0 [") {
+		t.Errorf("hole cards dealt to someone other than p0:\n%s", hh)
+	}
+}
+
+func TestHandHistoryWinByFolds(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Bet, amount: 60},
+		{player: "p1", action: playerState.Fold},
+		{player: "p2", action: playerState.Fold},
+	})
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	hh := pokerStarsText(t, table, "")
+
+	checkHistoryLines(t, hh, []string{
+		"p0: raises 50 to 60",
+		"p1: folds",
+		"p2: folds",
+		"Uncalled bet (50) returned to p0",
+		"p0 collected 25 from pot",
+		"Total pot 25 | Rake 0",
+		"Seat 1: p0 (button) collected (25)",
+		"Seat 2: p1 (small blind) folded before Flop",
+	})
+
+	if strings.Contains(hh, "Dealt to") || strings.Contains(hh, "SHOW DOWN") || strings.Contains(hh, "Board") {
+		t.Errorf("observer history has hole cards, a showdown or a board:\n%s", hh)
+	}
+}
+
+func TestHandHistoryJSON(t *testing.T) {
+	table := playShowdownHand(t)
+	history := table.HandHistory()
+
+	data, err := json.Marshal(history.VisibleTo("p1"))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var decoded HandHistory
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if decoded.ID != history.ID || decoded.Betting != history.Betting || len(decoded.Events) != len(history.Events) {
+		t.Fatalf("decoded hand doesn't match:\n%s", data)
+	}
+
+	// NOTE: p0 folded without showing, p2 showed down
+	holes := make(map[string]string)
+	for _, event := range decoded.Events {
+		if event.Kind == HistoryDeal {
+			holes[event.Player] = starsCards(event.Cards)
+		}
+	}
+	if want := map[string]string{"p0": "", "p1": "Ac Ad", "p2": "Kc Kd"}; !reflect.DeepEqual(holes, want) {
+		t.Errorf("hole cards visible to p1: got %v, want %v", holes, want)
+	}
+
+	var want, got strings.Builder
+	history.WritePokerStars(&want, "p1")
+	decoded.WritePokerStars(&got, "p1")
+	if got.String() != want.String() {
+		t.Errorf("decoded hand writes\n%s\nwant\n%s", got.String(), want.String())
+	}
+}
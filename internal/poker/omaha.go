@@ -0,0 +1,96 @@
+package poker
+
+import math_rand "math/rand/v2"
+
+// omaha, usually played pot-limit. players get four hole cards and must make
+// their hand with exactly two of them and three community cards.
+type Omaha struct{}
+
+func (Omaha) Name() string {
+	return "omaha"
+}
+
+func (Omaha) NewDeck(src math_rand.Source) *Deck {
+	return NewDeck(src)
+}
+
+func (Omaha) NumHoleCards() int {
+	return 4
+}
+
+func (Omaha) Streets() []Street {
+	return holdemStreets
+}
+
+// every combination of two hole cards and three community cards is ranked
+// and the best one is kept.
+func (Omaha) RankHand(table *Table, player *Player) {
+	hole, community := player.Hole.Cards, table.Community
+	if len(community) < 3 {
+		return
+	}
+
+	var bestHand *Hand
+
+	for i := 0; i < len(hole)-1; i++ {
+		for j := i + 1; j < len(hole); j++ {
+			for a := 0; a < len(community)-2; a++ {
+				for b := a + 1; b < len(community)-1; b++ {
+					for c := b + 1; c < len(community); c++ {
+						player.Hand = &Hand{Rank: RankMuck, Cards: make(Cards, 0, 5)}
+
+						assembleHand(player, Cards{hole[i], hole[j], community[a], community[b], community[c]},
+							standardHandRules)
+
+						if bestHand == nil || player.Hand.Compare(bestHand) > 0 {
+							bestHand = player.Hand
+						}
+					}
+				}
+			}
+		}
+	}
+
+	player.Hand = bestHand
+}
+
+func (Omaha) CompareHands(hand, otherHand *Hand) int {
+	return hand.Compare(otherHand)
+}
+
+// omaha hi-lo (omaha eight or better). the pot is split between the best high
+// hand and the best eight or better low. the low also has to be made with
+// exactly two hole cards and three community cards.
+type OmahaHiLo struct {
+	Omaha
+}
+
+func (OmahaHiLo) Name() string {
+	return "omaha hi-lo"
+}
+
+func (OmahaHiLo) RankLowHand(table *Table, player *Player) {
+	hole, community := player.Hole.Cards, table.Community
+	if len(community) < 3 {
+		return
+	}
+
+	for i := 0; i < len(hole)-1; i++ {
+		for j := i + 1; j < len(hole); j++ {
+			for a := 0; a < len(community)-2; a++ {
+				for b := a + 1; b < len(community)-1; b++ {
+					for c := b + 1; c < len(community); c++ {
+						hand := lowHand(Cards{hole[i], hole[j], community[a], community[b], community[c]})
+						if hand == nil {
+							continue
+						}
+
+						if player.LowHand == nil || compareLowHands(hand, player.LowHand) > 0 {
+							player.LowHand = hand
+						}
+					}
+				}
+			}
+		}
+	}
+}
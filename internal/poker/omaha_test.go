@@ -0,0 +1,126 @@
+package poker
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestOmahaAssembleBestHand(t *testing.T) {
+	tests := []assembleBestHandCase{
+		{
+			name:      "four flush on board needs two suited hole cards",
+			community: "2h 7h 9h Kh 4c",
+			hole:      "Ah 3s 8d 8c",
+			wantRank:  RankPair,
+			wantCards: []CardVal{CardSeven, CardNine, CardKing, CardEight, CardEight},
+		},
+		{
+			name:      "two suited hole cards make the flush",
+			community: "2h 7h 9h Kc 4c",
+			hole:      "Ah 3h 8d 8c",
+			wantRank:  RankFlush,
+			wantCards: []CardVal{CardTwo, CardThree, CardSeven, CardNine, CardAce},
+		},
+		{
+			name:      "board straight doesn't play",
+			community: "5c 6d 7h 8s 9c",
+			hole:      "As Ad Kc Kh",
+			wantRank:  RankPair,
+			wantCards: []CardVal{CardSeven, CardEight, CardNine, CardAce, CardAce},
+		},
+		{
+			name:      "straight with two hole cards",
+			community: "6h 7d 8c Ks Kd",
+			hole:      "9s Tc 2h 3d",
+			wantRank:  RankStraight,
+			wantCards: []CardVal{CardSix, CardSeven, CardEight, CardNine, CardTen},
+		},
+		{
+			name:      "one connecting hole card isn't a straight",
+			community: "6h 7d 8c Ks Kd",
+			hole:      "9s 2c 3h Jd",
+			wantRank:  RankPair,
+			wantCards: []CardVal{CardEight, CardNine, CardJack, CardKing, CardKing},
+		},
+		{
+			name:      "board trips play with two hole kickers",
+			community: "9c 9d 9h Ks 2d",
+			hole:      "Ac Qd Js 4h",
+			wantRank:  RankTrips,
+			wantCards: []CardVal{CardQueen, CardAce, CardNine, CardNine, CardNine},
+		},
+		{
+			name:      "hole pair fills up with board trips",
+			community: "9c 9d 9h Ks 2d",
+			hole:      "Ac Ad Js 4h",
+			wantRank:  RankFullHouse,
+			wantCards: []CardVal{CardAce, CardAce, CardNine, CardNine, CardNine},
+		},
+		{
+			name:      "quads with a pair on board and in the hole",
+			community: "Qc Qd 5h 5s 2d",
+			hole:      "Qh Qs 7c 8c",
+			wantRank:  RankQuads,
+			wantCards: []CardVal{CardFive, CardQueen, CardQueen, CardQueen, CardQueen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestTable(t, tt.community)
+			table.variant = Omaha{}
+			player := mustPlayerWithHole(t, "p1", tt.hole)
+
+			AssembleBestHand(false, table, player)
+
+			if player.Hand.Rank != tt.wantRank {
+				t.Fatalf("rank mismatch: got %s (%d), want %s (%d)",
+					player.Hand.RankName(), player.Hand.Rank, (&Hand{Rank: tt.wantRank}).RankName(), tt.wantRank)
+			}
+
+			if got := handValues(player.Hand.Cards); !reflect.DeepEqual(got, tt.wantCards) {
+				t.Fatalf("hand cards mismatch: got %v, want %v", got, tt.wantCards)
+			}
+
+			holeCardsUsed := 0
+			for _, card := range player.Hand.Cards {
+				if slices.Contains(player.Hole.Cards, card) {
+					holeCardsUsed++
+				}
+			}
+			if holeCardsUsed != 2 {
+				t.Fatalf("hand uses %d hole cards, want 2", holeCardsUsed)
+			}
+		})
+	}
+}
+
+func TestOmahaBestHand(t *testing.T) {
+	table := newTestTable(t, "Ah Kh Qh 2c 3d")
+	table.variant = Omaha{}
+
+	p1 := mustPlayerWithHole(t, "p1", "Jh Th 4s 5s") // royal flush
+	p2 := mustPlayerWithHole(t, "p2", "9h 4c 5c 6d") // wheel, only one heart
+	p3 := mustPlayerWithHole(t, "p3", "As Ac 9d 9c") // trip aces
+
+	winners := table.BestHand([]*Player{p1, p2, p3}, nil)
+
+	if got := winnerNames(winners); !reflect.DeepEqual(got, []string{"p1"}) {
+		t.Fatalf("winners mismatch: got %v, want [p1]", got)
+	}
+
+	for _, tt := range []struct {
+		player   *Player
+		wantRank Rank
+	}{
+		{p1, RankRoyalFlush},
+		{p2, RankStraight},
+		{p3, RankTrips},
+	} {
+		if tt.player.Hand.Rank != tt.wantRank {
+			t.Errorf("%s rank mismatch: got %s, want %s", tt.player.Name,
+				tt.player.Hand.RankName(), (&Hand{Rank: tt.wantRank}).RankName())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package poker
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bkazemi/gopoker/internal/playerState"
 	"github.com/rs/zerolog/log"
@@ -27,10 +28,24 @@ type Player struct {
 	TablePos uint
 
 	ChipCount Chips
-	Hole      *Hole
-	Hand      *Hand
-	preHand   *Hand
-	Action    Action
+	TimeBank  time.Duration // extra time left to act this game
+
+	IsSittingOut bool   // player keeps their seat but isn't dealt in
+	timeouts     uint8  // turns in a row the player ran out of time on
+	handsSatOut  uint64 // hands missed while sitting out
+	owesBlind    bool   // missed blinds while sitting out
+	Straddles    bool   // posts a straddle when under the gun. see Table.SetStraddle()
+
+	actedOnRaise uint64 // Table.fullRaises when the player last acted
+
+	Hole    *Hole
+	UpCards Cards // face up cards (stud). everyone can see these
+	Hand    *Hand
+	LowHand *Hand // best eight or better low in hi-lo games. nil means no low
+	preHand *Hand
+	Action  Action
+
+	showsHand bool // hole cards are shown to everyone. see Table.Showdown()
 }
 
 func (p *Player) DefaultName() string {
@@ -53,7 +68,7 @@ func (p *Player) SetName(name string) {
 
 func (p *Player) canBet() bool {
 	return !p.IsVacant && p.Action.Action != playerState.MidroundAddition &&
-		p.Action.Action != playerState.Fold && p.Action.Action != playerState.AllIn
+		p.Action.Action != playerState.SittingOut && p.Action.Action != playerState.Fold && p.Action.Action != playerState.AllIn
 }
 
 // XXX: should consider adding an ~IsBlind field to Player struct
@@ -268,7 +283,7 @@ func (list *PlayerList) ToPlayerArray() []*Player {
 	return players
 }
 
-func NewPlayer(name string, isCPU bool) *Player {
+func NewPlayer(name string, isCPU bool, chips Chips) *Player {
 	player := &Player{
 		defaultName: name,
 		Name:        name,
@@ -276,7 +291,7 @@ func NewPlayer(name string, isCPU bool) *Player {
 
 		IsVacant: true,
 
-		ChipCount: 1e5, // TODO: add knob
+		ChipCount: chips,
 
 		Action: Action{Action: playerState.VacantSeat},
 	}
@@ -288,14 +303,29 @@ func NewPlayer(name string, isCPU bool) *Player {
 
 func (player *Player) NewCards() {
 	player.Hole = &Hole{Cards: make(Cards, 0, 2)}
+	player.UpCards = nil
 	player.Hand = &Hand{Rank: RankMuck, Cards: make(Cards, 0, 5)}
+	player.LowHand = nil
+	player.showsHand = false
+}
+
+// the hole cards followed by the up cards
+func (player *Player) allCards() Cards {
+	cards := append(Cards{}, player.Hole.Cards...)
+
+	return append(cards, player.UpCards...)
 }
 
-func (player *Player) Clear() {
+// clear the seat, leaving chips for the next player to sit down
+func (player *Player) Clear(chips Chips) {
 	player.Name = player.defaultName
 	player.IsVacant = true
 
-	player.ChipCount = 1e5 // XXX
+	player.ChipCount = chips
+	player.TimeBank = 0
+	player.IsSittingOut, player.timeouts = false, 0
+	player.handsSatOut, player.owesBlind = 0, false
+	player.Straddles = false
 	player.NewCards()
 
 	player.Action.Amount = 0
@@ -327,6 +357,8 @@ func (player *Player) ActionToString() string {
 		return "waiting for first action"
 	case playerState.MidroundAddition:
 		return "waiting to add to next round"
+	case playerState.SittingOut:
+		return "sitting out"
 
 	default:
 		return "bad player state"
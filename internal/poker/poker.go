@@ -9,6 +9,7 @@ import (
 
 	"errors"
 	"sync"
+	"time"
 
 	//_ "net/http/pprof"
 
@@ -47,6 +48,12 @@ const (
 	TableStateNewRound
 	TableStateGameOver
 	TableStateReset
+
+	// seven card stud streets after third street (the preflop)
+	TableStateFourthStreet
+	TableStateFifthStreet
+	TableStateSixthStreet
+	TableStateSeventhStreet
 )
 
 const (
@@ -74,27 +81,67 @@ type Table struct {
 	Community  Cards // community cards
 	_comsorted Cards // sorted community cards
 
-	MainPot  *Pot     // table pot
-	sidePots SidePots // sidepots for allins
-	Ante     Chips    // current ante TODO allow both ante & blind modes
-	Bet      Chips    // current bet
+	MainPot    *Pot     // table pot
+	sidePots   SidePots // sidepots for allins
+	Blinds     Blinds   // current blinds & ante
+	baseBlinds Blinds   // blinds the table was configured with
+	Bet        Chips    // current bet
+
+	StartingStack Chips // chips each player starts a game with
+
+	TurnTime time.Duration // time a player has to act on their turn
+	TimeBank time.Duration // extra time each player gets per game
+
+	SitOutOrbits uint8 // orbits a player can sit out before losing their seat. 0 means never
+	RunItTimes   uint8 // times the board can be run when everyone is all in. see SetRunItTimes()
 
-	Dealer     *PlayerNode // current dealer
-	SmallBlind *PlayerNode // current small blind
+	BlindSchedule *BlindSchedule // optional increasing blinds
+
+	variant  Variant          // the game played at the table
+	Game     string           // name of the variant
+	Betting  BettingStructure // no-limit, pot-limit or fixed-limit
+	betCount uint8            // bets & raises made this street
+
+	Dealer     *PlayerNode // current dealer. the player before the button when the button is dead
+	SmallBlind *PlayerNode // current small blind. nil when the small blind is dead
 	BigBlind   *PlayerNode // current big blind
+	Straddler  *PlayerNode // player that straddled this hand, if any
+
+	DeadButton     bool            // the button is on a seat nobody is playing from this hand
+	DeadSmallBlind bool            // nobody posts the small blind this hand
+	positions      *tablePositions // seats of the button & blinds this hand. see rotatePlayers()
+
+	AllowStraddle bool // players may post an under the gun straddle. see SetAllowStraddle()
+	CommitReveal  bool // the deck is shuffled in commit-reveal mode. see SetCommitReveal()
 
 	players       []*Player   // array of all seats at table
 	activePlayers PlayerList  // list of all active players
 	curPlayers    PlayerList  // list of actively betting players (no folders or all-ins)
 	Winners       []*Player   // array of round winners
 	curPlayer     *PlayerNode // keeps track of whose turn it is
-	better        *Player     // last player to (re-)raise XXX currently unused
+	better        *Player     // last player to (re-)raise
+	lastAggressor *Player     // better when the last street's betting closed. shows first at showdown
+	lastRaise     Chips       // size of the last full bet or raise this street
+	fullBet       Chips       // table bet after the last full bet or raise this street
+	fullRaises    uint64      // full bets & raises made, plus one per street. see Player.actedOnRaise
 	NumPlayers    uint8       // number of current players
 	NumSeats      uint8       // number of total possible players
 	roundCount    uint64      // total number of rounds played
 
+	handPlayers []*Player // players dealt into the current hand, starting left of the button
+	handChips   Chips     // chips handPlayers had at the start of the hand
+	anteAllIns  []*Player // went all in posting the ante. only win the ante pots, see potAntes()
+
+	history *HandHistory // the current or last hand. see HandHistory()
+	HandID  uint64       // id of the current or last hand's history. 0 before the first hand
+
 	WinInfo string // XXX tmp
 
+	Boards          []Cards    // community cards of each run when the board was run more than once
+	runsLeft        int        // runs left to deal after the current one
+	runoutFrom      int        // number of community cards dealt before the runout
+	runoutCommState TableState // CommState before the runout
+
 	State        TableState // current status of table
 	CommState    TableState // current status of community
 	NumConnected uint64     // number of people (players+spectators) currently at table (online mode)
@@ -102,7 +149,7 @@ type Table struct {
 	Lock     TableLock // table admin option that restricts new connections
 	Password string    // table password (optional)
 
-	mtx sync.Mutex
+	mtx *sync.Mutex
 }
 
 // NOTE: the methods defined below are necessary so that
@@ -167,6 +214,38 @@ func (table *Table) ValidateNumSeats(numSeats uint8) error {
 	return table.validateNumSeats(numSeats)
 }
 
+// NOTE: this matches the old hard-coded starting stack
+const DefaultStartingStack Chips = 1e5
+
+// SetStartingStack changes the number of chips each player starts a game
+// with. only allowed between games. players that are already seated get
+// the new stack.
+func (table *Table) SetStartingStack(chips Chips) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if chips == 0 {
+		return errors.New("starting stack must be greater than 0")
+	} else if chips < table.baseBlinds.BigBlind {
+		return errors.New(printer.Sprintf("starting stack must be at least the big blind (%d chips)",
+			table.baseBlinds.BigBlind))
+	} else if table.State != TableStateNotStarted {
+		return errors.New("starting stack can't be changed while a game is in progress")
+	}
+
+	table.StartingStack = chips
+
+	for _, player := range table.players {
+		player.ChipCount = chips
+	}
+
+	return nil
+}
+
+func (table *Table) StartingStackToString() string {
+	return printer.Sprintf("%d chips", table.StartingStack)
+}
+
 func (table *Table) SetNumSeats(numSeats uint8) error {
 	table.mtx.Lock()
 	defer table.mtx.Unlock()
@@ -183,22 +262,31 @@ func (table *Table) SetNumSeats(numSeats uint8) error {
 // XXX we should probably only have the poker package
 // accessing the table lock, but for now i'm leaving it.
 func (table *Table) Mtx() *sync.Mutex {
-	return &table.mtx
+	return table.mtx
 }
 
-func NewTable(deck *Deck, numSeats uint8, lock TableLock, password string, CPUPlayers []bool) (*Table, error) {
+func NewTable(deck *Deck, variant Variant, numSeats uint8, lock TableLock, password string, CPUPlayers []bool) (*Table, error) {
 	if numSeats < 2 || numSeats > 7 {
 		return nil, errors.New("numPlayers must be between 2 and 7")
+	} else if variant == nil {
+		return nil, errors.New("a table needs a game variant")
 	}
 
 	players := make([]*Player, 0, numSeats)
 	for i := uint8(0); i < numSeats; i++ {
-		players = append(players, NewPlayer(fmt.Sprintf("p%d", i), CPUPlayers[i]))
+		players = append(players, NewPlayer(fmt.Sprintf("p%d", i), CPUPlayers[i], DefaultStartingStack))
 	}
 
 	table := &Table{
-		deck: deck,
-		Ante: 10, // TODO: add knob
+		deck:       deck,
+		variant:    variant,
+		Game:       variant.Name(),
+		Blinds:     DefaultBlinds,
+		baseBlinds: DefaultBlinds,
+
+		StartingStack: DefaultStartingStack,
+
+		TurnTime: DefaultTurnTime,
 
 		MainPot:  NewPot("mainpot", 0), // TODO: make .Players private
 		sidePots: *NewSidePots(),
@@ -211,6 +299,8 @@ func NewTable(deck *Deck, numSeats uint8, lock TableLock, password string, CPUPl
 		Password: password,
 
 		NumSeats: numSeats,
+
+		mtx: &sync.Mutex{},
 	}
 
 	table.newCommunity()
@@ -226,14 +316,18 @@ func (table *Table) Reset(player *Player) {
 
 	log.Info().Msg("resetting table")
 
-	table.Ante = 10
+	table.Blinds = table.baseBlinds
+	if table.BlindSchedule != nil {
+		table.BlindSchedule.reset(time.Now())
+	}
 
 	table.newCommunity()
+	table.deck.Shuffle()
 
 	for _, node := range table.curPlayers.ToNodeArray() {
 		if player == nil || player.Name != node.Player.Name {
 			log.Debug().Str("player", node.Player.Name).Msg("cleared")
-			node.Player.Clear()
+			node.Player.Clear(table.StartingStack)
 			table.curPlayers.RemovePlayer(node.Player)
 		} else { // XXX: not being found sometimes
 			log.Debug().Str("player", node.Player.Name).Msg("skipped")
@@ -244,7 +338,7 @@ func (table *Table) Reset(player *Player) {
 		}
 	}
 
-	table.Winners, table.better = nil, nil
+	table.Winners, table.better, table.lastAggressor = nil, nil, nil
 
 	if table.curPlayers.Len == 0 && player != nil {
 		log.Debug().Str("player", player.Name).Msg("curPlayers was empty, adding winner")
@@ -257,7 +351,7 @@ func (table *Table) Reset(player *Player) {
 	for _, p := range table.players {
 		if player == nil || p.Name != player.Name {
 			log.Debug().Str("player", p.Name).Msg("XXX: second clear()")
-			p.Clear()
+			p.Clear(table.StartingStack)
 		}
 	}
 
@@ -271,11 +365,17 @@ func (table *Table) Reset(player *Player) {
 		log.Debug().Str("player", player.Name).Msg("clearing winner's action and cards")
 		player.Action.Clear()
 		player.NewCards()
+		player.ChipCount = table.StartingStack
+		player.TimeBank = table.TimeBank
+		player.IsSittingOut, player.timeouts = false, 0
+		player.handsSatOut, player.owesBlind = 0, false
+		player.Straddles = false
 
 		table.NumPlayers++
 	}
 
 	table.WinInfo = ""
+	table.Boards = nil
 
 	table.State = TableStateNotStarted
 
@@ -283,6 +383,10 @@ func (table *Table) Reset(player *Player) {
 
 	table.SmallBlind = nil
 	table.BigBlind = nil
+	table.Straddler = nil
+
+	table.DeadButton, table.DeadSmallBlind = false, false
+	table.positions = nil
 }
 
 func (table *Table) InBettingState() bool {
@@ -299,27 +403,32 @@ func (table *Table) InBettingState() bool {
 	return true
 }
 
-func (table *Table) TableStateToString() string {
-	tableStateNameMap := map[TableState]string{
-		TableStateNotStarted: "waiting for start",
+var tableStateNames = map[TableState]string{
+	TableStateNotStarted: "waiting for start",
 
-		TableStatePreFlop: "preflop",
-		TableStateFlop:    "flop",
-		TableStateTurn:    "turn",
-		TableStateRiver:   "river",
+	TableStatePreFlop: "preflop",
+	TableStateFlop:    "flop",
+	TableStateTurn:    "turn",
+	TableStateRiver:   "river",
 
-		TableStateRounds:    "betting rounds",
-		TableStateRoundOver: "round over",
-		TableStateNewRound:  "new round",
-		TableStateGameOver:  "game over",
+	TableStateFourthStreet:  "fourth street",
+	TableStateFifthStreet:   "fifth street",
+	TableStateSixthStreet:   "sixth street",
+	TableStateSeventhStreet: "seventh street",
 
-		TableStatePlayerRaised: "player raised",
-		TableStateDoneBetting:  "finished betting",
-		TableStateShowHands:    "showing hands",
-		TableStateSplitPot:     "split pot",
-	}
+	TableStateRounds:    "betting rounds",
+	TableStateRoundOver: "round over",
+	TableStateNewRound:  "new round",
+	TableStateGameOver:  "game over",
 
-	if state, ok := tableStateNameMap[table.State]; ok {
+	TableStatePlayerRaised: "player raised",
+	TableStateDoneBetting:  "finished betting",
+	TableStateShowHands:    "showing hands",
+	TableStateSplitPot:     "split pot",
+}
+
+func (table *Table) TableStateToString() string {
+	if state, ok := tableStateNames[table.State]; ok {
 		return state
 	}
 
@@ -341,15 +450,19 @@ func (table *Table) PotToString() string {
 }
 
 func (table *Table) BigBlindToString() string {
-	if table.BigBlind != nil {
-		return printer.Sprintf("%s (%d chip bet)", table.BigBlind.Player.Name, table.Ante)
+	if table.isStud() {
+		return printer.Sprintf("none (%d chip small bet)", table.Blinds.BigBlind)
+	} else if table.BigBlind != nil {
+		return printer.Sprintf("%s (%d chip bet)", table.BigBlind.Player.Name, table.Blinds.BigBlind)
 	}
 
 	return "none"
 }
 
 func (table *Table) DealerToString() string {
-	if table.Dealer != nil {
+	if table.Dealer != nil && table.DeadButton {
+		return table.Dealer.Player.Name + " (dead button)"
+	} else if table.Dealer != nil {
 		return table.Dealer.Player.Name
 	}
 
@@ -357,8 +470,20 @@ func (table *Table) DealerToString() string {
 }
 
 func (table *Table) SmallBlindToString() string {
-	if table.SmallBlind != nil {
-		return printer.Sprintf("%s (%d chip bet)", table.SmallBlind.Player.Name, table.Ante/2)
+	if table.isStud() {
+		return printer.Sprintf("none (%d chip bring-in)", table.Blinds.SmallBlind)
+	} else if table.SmallBlind != nil {
+		return printer.Sprintf("%s (%d chip bet)", table.SmallBlind.Player.Name, table.Blinds.SmallBlind)
+	} else if table.DeadSmallBlind {
+		return "dead"
+	}
+
+	return "none"
+}
+
+func (table *Table) StraddleToString() string {
+	if table.Straddler != nil {
+		return printer.Sprintf("%s (%d chip bet)", table.Straddler.Player.Name, table.straddleSize())
 	}
 
 	return "none"
@@ -389,9 +514,23 @@ func (table *Table) PublicInfo() *Table {
 			Player: table.PublicPlayerInfo(*pubTable.BigBlind.Player),
 		}
 	}
+	if pubTable.Straddler != nil {
+		pubTable.Straddler = &PlayerNode{
+			Player: table.PublicPlayerInfo(*pubTable.Straddler.Player),
+		}
+	}
+
+	if pubTable.BlindSchedule != nil {
+		schedule := *table.BlindSchedule
+		schedule.NextLevelIn, _ = schedule.TimeLeft(time.Now())
+		pubTable.BlindSchedule = &schedule
+	}
 
 	if pubTable.MainPot != nil {
 		// deep copy MainPot.Players map
+		// NOTE: copy the pot first so we don't overwrite the table's own map
+		mainPot := *table.MainPot
+		pubTable.MainPot = &mainPot
 		pubTable.MainPot.Players = make(map[string]*Player)
 		for name, player := range table.MainPot.Players {
 			pubTable.MainPot.Players[name] = table.PublicPlayerInfo(*player)
@@ -409,9 +548,11 @@ func (table *Table) PublicInfo() *Table {
 	return &pubTable
 }
 
+// NOTE: up cards (stud) are always public, the hole cards and hand are only
+// shown at showdown
 func (table *Table) PublicPlayerInfo(player Player) *Player {
-	if table.State != TableStateShowHands {
-		player.Hole, player.Hand = nil, nil
+	if !player.showsHand {
+		player.Hole, player.Hand, player.LowHand = nil, nil, nil
 	}
 
 	return &player
@@ -504,7 +645,24 @@ func (table *Table) getChipLeaders(includeAllIns bool) (Chips, Chips) {
 	return chipLeader, secondChipLeader
 }
 
+// PlayerAction makes player's move on their turn and records it in the hand
+// history.
 func (table *Table) PlayerAction(player *Player, action Action) error {
+	if player == nil {
+		return errors.New("no player")
+	}
+
+	chips := player.ChipCount
+	if err := table.playerAction(player, action); err != nil {
+		return err
+	}
+
+	table.history.action(player, chips)
+
+	return nil
+}
+
+func (table *Table) playerAction(player *Player, action Action) error {
 	if table.State == TableStateNotStarted {
 		return errors.New("game has not started yet")
 	}
@@ -539,9 +697,9 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 		table.State != TableStatePlayerRaised { // XXX mixed states...
 		if table.SmallBlind != nil && player.Name == table.SmallBlind.Player.Name {
 			isSmallBlindPreFlop = true
-			blindRequiredBet = min(table.SmallBlind.Player.ChipCount, table.Ante/2)
+			blindRequiredBet = table.Bet - player.Action.Amount
 		} else if table.BigBlind != nil && player.Name == table.BigBlind.Player.Name {
-			blindRequiredBet = min(table.BigBlind.Player.ChipCount, table.Ante)
+			blindRequiredBet = min(table.BigBlind.Player.ChipCount, table.Blinds.BigBlind)
 		}
 	}
 
@@ -557,6 +715,10 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 
 	switch action.Action {
 	case playerState.AllIn:
+		if err := table.validateAllIn(player); err != nil {
+			return err
+		}
+
 		player.Action.Action = playerState.AllIn
 
 		prevChips := player.Action.Amount
@@ -584,6 +746,7 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 			}
 
 			if player.Action.Amount > table.Bet {
+				table.recordRaise(player.Action.Amount)
 				table.Bet = player.Action.Amount
 				table.State = TableStatePlayerRaised
 				table.better = player
@@ -611,12 +774,8 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 		prevChips := player.Action.Amount
 		log.Debug().Str("player", player.Name).Uint64("prevChips", uint64(prevChips)).Msg("bet")
 
-		if action.Amount < table.Ante {
-			return errors.New(printer.Sprintf("bet must be greater than the ante (%d chips)", table.Ante))
-		} else if action.Amount <= table.Bet {
-			return errors.New(printer.Sprintf("bet must be greater than the current bet (%d chips)", table.Bet))
-		} else if action.Amount > player.ChipCount+prevChips {
-			return errors.New("not enough chips")
+		if err := table.validateBet(player, action.Amount); err != nil {
+			return err
 		}
 
 		chipLeaderCount, secondChipLeaderCount := table.getChipLeaders(true)
@@ -653,6 +812,7 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 
 		player.ChipCount -= player.Action.Amount
 
+		table.recordRaise(player.Action.Amount)
 		table.Bet = player.Action.Amount
 
 		log.Debug().
@@ -704,7 +864,7 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 			player.ChipCount -= player.Action.Amount
 		}
 	case playerState.Check:
-		if table.State == TableStatePlayerRaised {
+		if table.State == TableStatePlayerRaised && !table.isStraddleOption(player) {
 			return errors.New(printer.Sprintf("you must call the raise (%d chips)", table.Bet))
 		}
 
@@ -723,6 +883,8 @@ func (table *Table) PlayerAction(player *Player, action Action) error {
 		return errors.New(fmt.Sprintf("BUG: invalid player action: %b", action.Action))
 	}
 
+	player.actedOnRaise = table.fullRaises
+
 	table.SetNextPlayerTurn()
 
 	return nil
@@ -0,0 +1,441 @@
+package poker
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WritePokerStars writes the hand in the PokerStars text format that hand
+// trackers and review tools import. hero's hole cards are written as dealt
+// to them; everyone else's are only written if they were shown. an empty
+// hero writes the hand the way a spectator saw it.
+//
+// NOTE: each hand ends with two blank lines so histories can be appended to
+// the same file
+func (history *HandHistory) WritePokerStars(w io.Writer, hero string) error {
+	var hh strings.Builder
+
+	history.writeHeader(&hh)
+
+	// NOTE: forced bets go before the hole cards, except the stud bring-in
+	// which depends on the up cards
+	posts := func(event HistoryEvent) bool {
+		switch event.Kind {
+		case HistoryPostAnte, HistoryPostSmallBlind, HistoryPostBigBlind, HistoryPostStraddle:
+			return true
+		}
+
+		return false
+	}
+
+	for _, event := range history.Events {
+		if posts(event) {
+			history.writeEvent(&hh, event, hero, nil)
+		}
+	}
+
+	if history.Stud {
+		hh.WriteString("*** 3rd STREET ***\n")
+	} else {
+		hh.WriteString("*** HOLE CARDS ***\n")
+	}
+
+	dealt := make(map[string]HistoryEvent) // the cards dealt to each player so far
+	for _, event := range history.Events {
+		if !posts(event) {
+			history.writeEvent(&hh, event, hero, dealt)
+		}
+	}
+
+	history.writeShowdown(&hh)
+	history.writeSummary(&hh)
+	hh.WriteString("\n\n")
+
+	_, err := io.WriteString(w, hh.String())
+
+	return err
+}
+
+func (history *HandHistory) writeHeader(hh *strings.Builder) {
+	games := map[string]string{
+		(Holdem{}).Name():            "Hold'em",
+		(ShortDeck{}).Name():         "6+ Hold'em",
+		(Omaha{}).Name():             "Omaha",
+		(OmahaHiLo{}).Name():         "Omaha Hi/Lo",
+		(SevenCardStud{}).Name():     "7 Card Stud",
+		(SevenCardStudHiLo{}).Name(): "7 Card Stud Hi/Lo",
+	}
+
+	game, ok := games[history.Game]
+	if !ok {
+		game = history.Game
+	}
+
+	// NOTE: limit games go by the small & big bet
+	limit, stakes := "No Limit", fmt.Sprintf("%d/%d", history.Blinds.SmallBlind, history.Blinds.BigBlind)
+	switch {
+	case history.Stud || history.Betting == BettingFixedLimit:
+		limit, stakes = "Limit", fmt.Sprintf("%d/%d", history.Blinds.BigBlind, 2*history.Blinds.BigBlind)
+	case history.Betting == BettingPotLimit:
+		limit = "Pot Limit"
+	}
+
+	fmt.Fprintf(hh, "PokerStars Hand #%d: %s %s (%s) - %s UTC\n",
+		history.ID, game, limit, stakes, history.Start.UTC().Format("2006/01/02 15:04:05"))
+
+	table := history.Table
+	if table == "" {
+		table = "gopoker"
+	}
+
+	fmt.Fprintf(hh, "Table '%s' %d-max", table, history.NumSeats)
+	if history.Button != 0 {
+		fmt.Fprintf(hh, " Seat #%d is the button", history.Button)
+	}
+	hh.WriteString("\n")
+
+	for _, seat := range history.Seats {
+		fmt.Fprintf(hh, "Seat %d: %s (%d in chips)\n", seat.Seat, seat.Name, seat.Chips)
+	}
+}
+
+func (history *HandHistory) writeEvent(hh *strings.Builder, event HistoryEvent, hero string, dealt map[string]HistoryEvent) {
+	allIn := ""
+	if event.AllIn {
+		allIn = " and is all-in"
+	}
+
+	switch event.Kind {
+	case HistoryPostAnte:
+		fmt.Fprintf(hh, "%s: posts the ante %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryPostSmallBlind:
+		fmt.Fprintf(hh, "%s: posts small blind %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryPostBigBlind:
+		fmt.Fprintf(hh, "%s: posts big blind %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryPostStraddle:
+		fmt.Fprintf(hh, "%s: posts straddle %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryBringIn:
+		fmt.Fprintf(hh, "%s: brings in for %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryDeal:
+		history.writeDeal(hh, event, hero, dealt)
+	case HistoryStreet:
+		history.writeStreet(hh, event)
+	case HistoryFold:
+		fmt.Fprintf(hh, "%s: folds\n", event.Player)
+	case HistoryCheck:
+		fmt.Fprintf(hh, "%s: checks\n", event.Player)
+	case HistoryCall:
+		fmt.Fprintf(hh, "%s: calls %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryBet:
+		fmt.Fprintf(hh, "%s: bets %d%s\n", event.Player, event.Amount, allIn)
+	case HistoryRaise:
+		fmt.Fprintf(hh, "%s: raises %d to %d%s\n", event.Player, event.Amount, event.To, allIn)
+	case HistoryUncalledBet:
+		fmt.Fprintf(hh, "Uncalled bet (%d) returned to %s\n", event.Amount, event.Player)
+	}
+}
+
+// hero sees all of their cards, everyone else only sees up cards. the cards
+// dealt on earlier streets come first, e.g. "Dealt to p0 [Ah Kd 7c] [9s]"
+func (history *HandHistory) writeDeal(hh *strings.Builder, event HistoryEvent, hero string, dealt map[string]HistoryEvent) {
+	before := dealt[event.Player]
+	dealt[event.Player] = HistoryEvent{
+		Cards:   append(slices.Clone(before.Cards), event.Cards...),
+		UpCards: append(slices.Clone(before.UpCards), event.UpCards...),
+	}
+
+	var old, cards Cards
+	if event.Player == hero {
+		old, cards = append(slices.Clone(before.Cards), before.UpCards...), append(slices.Clone(event.Cards), event.UpCards...)
+	} else if history.Stud {
+		old, cards = before.UpCards, event.UpCards
+	}
+
+	if len(cards) == 0 {
+		return
+	}
+
+	fmt.Fprintf(hh, "Dealt to %s ", event.Player)
+	if len(old) > 0 {
+		fmt.Fprintf(hh, "[%s] ", starsCards(old))
+	}
+	fmt.Fprintf(hh, "[%s]\n", starsCards(cards))
+}
+
+func (history *HandHistory) writeStreet(hh *strings.Builder, event HistoryEvent) {
+	name := starsStreetName(event.Street)
+	if len(history.Boards) > 1 {
+		name = starsRunName(event.Run) + " " + name
+	}
+
+	fmt.Fprintf(hh, "*** %s ***", name)
+	if old := len(event.Board) - len(event.Cards); old > 0 {
+		fmt.Fprintf(hh, " [%s]", starsCards(event.Board[:old]))
+	}
+	if len(event.Cards) > 0 {
+		fmt.Fprintf(hh, " [%s]", starsCards(event.Cards))
+	}
+	hh.WriteString("\n")
+}
+
+func (history *HandHistory) writeShowdown(hh *strings.Builder) {
+	if len(history.Shows) > 0 {
+		hh.WriteString("*** SHOW DOWN ***\n")
+	}
+
+	for _, show := range history.Shows {
+		if show.Mucked {
+			fmt.Fprintf(hh, "%s: mucks hand\n", show.Player)
+		} else {
+			fmt.Fprintf(hh, "%s: shows [%s] (%s)\n", show.Player, starsCards(show.Cards), show.handName())
+		}
+	}
+
+	for _, award := range history.totalAwards() {
+		fmt.Fprintf(hh, "%s collected %d from %s\n", award.Player, award.Amount, history.potName(award.Pot))
+	}
+}
+
+func (history *HandHistory) writeSummary(hh *strings.Builder) {
+	hh.WriteString("*** SUMMARY ***\n")
+
+	var (
+		total Chips
+		pots  []Chips // each pot's total, main pot first
+	)
+	for _, award := range history.Awards {
+		total += award.Amount
+
+		for len(pots) <= award.Pot {
+			pots = append(pots, 0)
+		}
+		pots[award.Pot] += award.Amount
+	}
+
+	fmt.Fprintf(hh, "Total pot %d", total)
+	if len(pots) > 1 {
+		for i, pot := range pots {
+			fmt.Fprintf(hh, " %s %d.", capitalize(history.potName(i)), pot)
+		}
+	}
+	hh.WriteString(" | Rake 0\n")
+
+	for i, board := range history.Boards {
+		if len(board) == 0 {
+			continue
+		}
+
+		if len(history.Boards) > 1 {
+			fmt.Fprintf(hh, "%s ", starsRunName(i))
+		}
+		fmt.Fprintf(hh, "Board [%s]\n", starsCards(board))
+	}
+
+	won := make(map[string]Chips)
+	for _, award := range history.Awards {
+		won[award.Player] += award.Amount
+	}
+
+	for _, seat := range history.Seats {
+		fmt.Fprintf(hh, "Seat %d: %s", seat.Seat, seat.Name)
+
+		switch seat.Seat {
+		case history.Button:
+			hh.WriteString(" (button)")
+		case history.SmallBlind:
+			hh.WriteString(" (small blind)")
+		case history.BigBlind:
+			hh.WriteString(" (big blind)")
+		}
+
+		fmt.Fprintf(hh, "%s\n", history.seatResult(seat.Name, won[seat.Name]))
+	}
+}
+
+// how the hand ended for player, e.g. " folded on the Flop"
+func (history *HandHistory) seatResult(player string, won Chips) string {
+	for _, event := range history.Events {
+		if event.Kind == HistoryFold && event.Player == player {
+			return " folded " + starsFoldStreet(event.Street, history.Stud)
+		}
+	}
+
+	for _, show := range history.Shows {
+		switch {
+		case show.Player != player:
+			continue
+		case show.Mucked:
+			return " mucked"
+		case won > 0:
+			return fmt.Sprintf(" showed [%s] and won (%d) with %s", starsCards(show.Cards), won, show.handName())
+		default:
+			return fmt.Sprintf(" showed [%s] and lost with %s", starsCards(show.Cards), show.handName())
+		}
+	}
+
+	if won > 0 {
+		return fmt.Sprintf(" collected (%d)", won)
+	}
+
+	return ""
+}
+
+// each player's winnings from each pot, in the order they were first awarded.
+// a pot split between runs of the board is added up.
+func (history *HandHistory) totalAwards() []HistoryAward {
+	var awards []HistoryAward
+	for _, award := range history.Awards {
+		i := slices.IndexFunc(awards, func(a HistoryAward) bool {
+			return a.Player == award.Player && a.Pot == award.Pot
+		})
+		if i == -1 {
+			awards = append(awards, award)
+		} else {
+			awards[i].Amount += award.Amount
+		}
+	}
+
+	return awards
+}
+
+func (history *HandHistory) potName(pot int) string {
+	sidePots := slices.ContainsFunc(history.Awards, func(award HistoryAward) bool {
+		return award.Pot > 0
+	})
+
+	switch {
+	case !sidePots:
+		return "pot"
+	case pot == 0:
+		return "main pot"
+	default:
+		return fmt.Sprintf("side pot-%d", pot)
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (show HistoryShow) handName() string {
+	high := starsHandName(show.Hand)
+	if show.LowHand == nil {
+		return high
+	}
+
+	low := make([]string, 0, len(show.LowHand.Cards))
+	for _, card := range reverseCards(show.LowHand.Cards) {
+		low = append(low, cardValueCode(card.NumValue))
+	}
+
+	return fmt.Sprintf("HI: %s; LO: %s", high, strings.Join(low, ","))
+}
+
+// cards the way PokerStars writes them, e.g. "Ah Td 9c"
+func starsCards(cards Cards) string {
+	names := make([]string, len(cards))
+	for i, card := range cards {
+		names[i] = card.Code()
+	}
+
+	return strings.Join(names, " ")
+}
+
+var starsValueNames = map[CardVal][2]string{
+	CardTwo:   {"Deuce", "Deuces"},
+	CardThree: {"Three", "Threes"},
+	CardFour:  {"Four", "Fours"},
+	CardFive:  {"Five", "Fives"},
+	CardSix:   {"Six", "Sixes"},
+	CardSeven: {"Seven", "Sevens"},
+	CardEight: {"Eight", "Eights"},
+	CardNine:  {"Nine", "Nines"},
+	CardTen:   {"Ten", "Tens"},
+	CardJack:  {"Jack", "Jacks"},
+	CardQueen: {"Queen", "Queens"},
+	CardKing:  {"King", "Kings"},
+	CardAce:   {"Ace", "Aces"},
+}
+
+// a hand the way PokerStars describes it, e.g. "two pair, Kings and Sevens".
+//
+// NOTE: the hand's cards are least important first, see assembleHand()
+func starsHandName(hand *Hand) string {
+	if hand == nil {
+		return "a hand"
+	} else if len(hand.Cards) < 5 {
+		return hand.RankName()
+	}
+
+	cards := hand.Cards
+	one := func(i int) string { return starsValueNames[cards[i].NumValue][0] }
+	many := func(i int) string { return starsValueNames[cards[i].NumValue][1] }
+
+	switch hand.Rank {
+	case RankHighCard:
+		return "high card " + one(4)
+	case RankPair:
+		return "a pair of " + many(4)
+	case RankTwoPair:
+		return fmt.Sprintf("two pair, %s and %s", many(4), many(2))
+	case RankTrips:
+		return "three of a kind, " + many(4)
+	case RankStraight:
+		return fmt.Sprintf("a straight, %s to %s", one(0), one(4))
+	case RankFlush:
+		return fmt.Sprintf("a flush, %s high", one(4))
+	case RankFullHouse:
+		return fmt.Sprintf("a full house, %s full of %s", many(4), many(0))
+	case RankQuads:
+		return "four of a kind, " + many(4)
+	case RankStraightFlush:
+		return fmt.Sprintf("a straight flush, %s to %s", one(0), one(4))
+	case RankRoyalFlush:
+		return "a Royal Flush"
+	}
+
+	return hand.RankName()
+}
+
+func starsStreetName(street TableState) string {
+	names := map[TableState]string{
+		TableStateFlop:          "FLOP",
+		TableStateTurn:          "TURN",
+		TableStateRiver:         "RIVER",
+		TableStateFourthStreet:  "4th STREET",
+		TableStateFifthStreet:   "5th STREET",
+		TableStateSixthStreet:   "6th STREET",
+		TableStateSeventhStreet: "RIVER",
+	}
+
+	return names[street]
+}
+
+func starsFoldStreet(street TableState, stud bool) string {
+	if street == TableStatePreFlop {
+		if stud {
+			return "on the 3rd Street"
+		}
+
+		return "before Flop"
+	}
+
+	name := starsStreetName(street)
+
+	return "on the " + strings.ToUpper(name[:1]) + strings.ToLower(name[1:])
+}
+
+func starsRunName(run int) string {
+	names := []string{"FIRST", "SECOND", "THIRD", "FOURTH"}
+	if run < len(names) {
+		return names[run]
+	}
+
+	return fmt.Sprintf("RUN #%d", run+1)
+}
@@ -242,12 +242,16 @@ func (arr *SidePotArray) IsEmpty() bool {
 }
 
 type SidePots struct {
+	AntePots   SidePotArray // see Table.potAntes()
 	AllInPots  SidePotArray
 	BettingPot *SidePot
 }
 
 func NewSidePots() *SidePots {
 	return &SidePots{
+		AntePots: SidePotArray{
+			Pots: make([]*SidePot, 0),
+		},
 		AllInPots: SidePotArray{
 			Pots: make([]*SidePot, 0),
 		},
@@ -257,6 +261,7 @@ func NewSidePots() *SidePots {
 func (sidePots *SidePots) GetAllPots() []*SidePot {
 	pots := make([]*SidePot, 0)
 
+	pots = append(pots, sidePots.AntePots.Pots...)
 	for _, sidePot := range sidePots.AllInPots.Pots {
 		pots = append(pots, sidePot)
 	}
@@ -267,11 +272,15 @@ func (sidePots *SidePots) GetAllPots() []*SidePot {
 	return pots
 }
 
+// NOTE: ante pots aren't counted, they're settled before the betting starts
 func (sidePots *SidePots) IsEmpty() bool {
 	return sidePots.AllInPots.IsEmpty() && sidePots.BettingPot == nil
 }
 
 func (sidePots *SidePots) Clear() {
+	sidePots.AntePots = SidePotArray{
+		Pots: make([]*SidePot, 0),
+	}
 	sidePots.AllInPots = SidePotArray{
 		Pots: make([]*SidePot, 0),
 	}
@@ -279,7 +288,7 @@ func (sidePots *SidePots) Clear() {
 }
 
 func (sidePots *SidePots) Print() {
-	for _, sidePot := range sidePots.AllInPots.Pots {
+	for _, sidePot := range append(sidePots.AntePots.Pots, sidePots.AllInPots.Pots...) {
 		log.Debug().
 			Str("sidePot", sidePot.Name).
 			Uint64("bet", uint64(sidePot.Bet)).
@@ -1,6 +1,8 @@
 package poker
 
 import (
+	"errors"
+	"fmt"
 	"maps"
 	"slices"
 
@@ -20,29 +22,46 @@ func (table *Table) NewRound() {
 		player.Action.Action = playerState.FirstAction // NOTE: set twice w/ new player
 	}
 
+	table.sitOutPlayers()
+
 	table.newCommunity()
+	table.Boards, table.runsLeft = nil, 0
 
 	table.roundCount++
 
-	if table.roundCount%10 == 0 {
-		table.Ante *= 2 // TODO increase with time interval instead
-		log.Info().Uint64("ante", uint64(table.Ante)).Msg("ante increased")
-	}
+	table.updateBlindLevel()
 
-	table.handleOrphanedSeats()
+	if table.positions == nil {
+		table.handleOrphanedSeats()
+	}
+	table.Straddler = nil
 
 	table.curPlayers = *table.activePlayers.Clone("curPlayers")
-	table.better = nil
-	table.Bet = table.Ante // min bet is big blind bet
+	table.better, table.lastAggressor = nil, nil
+	table.Bet = table.Blinds.BigBlind // min bet is big blind bet
 	table.MainPot.Clear()
 	table.MainPot.Bet = table.Bet
 	table.sidePots.Clear()
 	table.State = TableStateNewRound
 }
 
-func (table *Table) FinishRound() {
+// FinishRound awards the pots to the winners of the hand. it returns an error
+// if the players' chips don't add up to what they started the hand with, see
+// checkChips().
+func (table *Table) FinishRound() error {
 	table.mtx.Lock()
 	defer table.mtx.Unlock()
+
+	log.Debug().Msgf("mainpot: last bet: %s pot: %s %s",
+		printer.Sprintf("%d", table.MainPot.Bet), printer.Sprintf("%d", table.MainPot.Total), table.MainPot.PlayerInfo())
+	table.calculateSidePotTotals()
+	table.sidePots.Print()
+	if table.sidePots.BettingPot != nil &&
+		table.sidePots.BettingPot.Total == 0 {
+		log.Debug().Msg("removing empty bettingpot")
+		table.sidePots.BettingPot = nil
+	}
+
 	// special case for when everyone except a folded player
 	// leaves the table
 	if table.activePlayers.Len == 1 &&
@@ -54,25 +73,21 @@ func (table *Table) FinishRound() {
 		table.State = TableStateGameOver
 		table.Winners = []*Player{table.activePlayers.Head.Player}
 
-		return
+		abandoned := table.MainPot.Total
+		for _, sidePot := range table.sidePots.GetAllPots() {
+			abandoned += sidePot.Total
+		}
+
+		return table.checkChips(abandoned)
 	}
 
 	players := table.GetNonFoldedPlayers()
 
-	log.Debug().Msgf("mainpot: last bet: %s pot: %s %s",
-		printer.Sprintf("%d", table.MainPot.Bet), printer.Sprintf("%d", table.MainPot.Total), table.MainPot.PlayerInfo())
-	table.calculateSidePotTotals()
-	table.sidePots.Print()
-	if table.sidePots.BettingPot != nil &&
-		table.sidePots.BettingPot.Total == 0 {
-		log.Debug().Msg("removing empty bettingpot")
-		table.sidePots.BettingPot = nil
-	}
-
 	if len(players) == 1 { // win by folds
 		player := players[0]
 
 		player.ChipCount += table.MainPot.Total
+		table.history.winByFolds(player, table.MainPot.Total)
 
 		Assert(table.sidePots.IsEmpty(),
 			printer.Sprintf("BUG: Table.FinishRound(): %s won by folds but there are sidepots", player.Name))
@@ -80,95 +95,118 @@ func (table *Table) FinishRound() {
 		table.State = TableStateRoundOver
 		table.Winners = players
 
-		return
+		return table.checkChips(0)
 	}
 
 	table.State = TableStateShowHands
+	table.WinInfo = ""
+
+	// NOTE: when the board was run more than once every pot is split evenly
+	// between the runs, and each run's share goes to that run's winners.
+	boards := table.Boards
+	if len(boards) < 2 {
+		boards = []Cards{table.Community}
+	}
 
-	bestPlayers := table.BestHand(players, nil)
+	var uncollected Chips // pots nobody is left in
 
-	// TODO: redundant code
-	if len(bestPlayers) == 1 {
-		bestPlayers[0].ChipCount += table.MainPot.Total
-	} else {
-		splitChips := table.MainPot.Total / Chips(len(bestPlayers))
+	playerMap := make(map[string]*Player)
 
-		log.Debug().Msgf("mainpot: split chips: %s", printer.Sprintf("%v", splitChips))
+	for run, board := range boards {
+		table.Community = board
 
-		for _, player := range bestPlayers {
-			player.ChipCount += splitChips
+		if len(boards) > 1 {
+			table.WinInfo += fmt.Sprintf("run #%d: ", run+1)
 		}
 
-		table.State = TableStateSplitPot
-	}
-
-	playerMap := make(map[string]*Player)
+		// NOTE: players that went all in posting the ante only win the ante
+		//       pots. their hands are ranked here since the mainpot doesn't
+		mainPotPlayers := slices.DeleteFunc(slices.Clone(players), func(player *Player) bool {
+			return slices.Contains(table.anteAllIns, player)
+		})
+		for _, player := range table.anteAllIns {
+			AssembleBestHand(false, table, player)
+		}
 
-	table.Winners = bestPlayers
-	for _, p := range bestPlayers {
-		playerMap[p.Name] = p
-	}
+		var bestPlayers []*Player
+		if len(mainPotPlayers) == 0 {
+			uncollected += runShare(table.MainPot.Total, run, len(boards))
+		} else {
+			bestPlayers = table.awardPot(runShare(table.MainPot.Total, run, len(boards)), mainPotPlayers, nil)
+		}
+		if len(bestPlayers) > 1 {
+			table.State = TableStateSplitPot
+		}
 
-	for _, sidePot := range table.sidePots.GetAllPots() {
-		// remove players that folded from sidePots
-		// XXX: probably not the best place to do this.
-		for _, player := range sidePot.Players {
-			if player.Action.Action == playerState.Fold {
-				log.Debug().
-					Str("player", player.Name).
-					Str("pot", sidePot.Name).
-					Msg("removing folded player from sidepot")
-				sidePot.RemovePlayer(player)
-			}
+		for _, p := range bestPlayers {
+			playerMap[p.Name] = p
 		}
 
-		if len(sidePot.Players) == 0 {
-			log.Debug().Str("pot", sidePot.Name).Msg("no players attached, skipping")
-			continue
+		if len(boards) > 1 && run < len(boards)-1 {
+			table.WinInfo += "\n\n"
 		}
 
-		if len(sidePot.Players) == 1 { // win by folds
-			var player *Player
-			// XXX
-			for _, p := range sidePot.Players {
-				player = p
+		for _, sidePot := range table.sidePots.GetAllPots() {
+			// remove players that folded from sidePots
+			// XXX: probably not the best place to do this.
+			for _, player := range sidePot.Players {
+				if player.Action.Action == playerState.Fold {
+					log.Debug().
+						Str("player", player.Name).
+						Str("pot", sidePot.Name).
+						Msg("removing folded player from sidepot")
+					sidePot.RemovePlayer(player)
+				}
 			}
 
-			log.Debug().
-				Str("player", player.Name).
-				Str("pot", sidePot.Name).
-				Msg("won by folds")
+			sidePotShare := runShare(sidePot.Total, run, len(boards))
 
-			player.ChipCount += sidePot.Total
+			if len(sidePot.Players) == 0 {
+				log.Debug().Str("pot", sidePot.Name).Msg("no players attached, skipping")
+				uncollected += sidePotShare
+				continue
+			}
 
-			playerMap[player.Name] = player
-		} else {
-			bestPlayers := table.BestHand(slices.Collect(maps.Values(sidePot.Players)), sidePot)
+			if len(sidePot.Players) == 1 { // win by folds
+				var player *Player
+				// XXX
+				for _, p := range sidePot.Players {
+					player = p
+				}
 
-			if len(bestPlayers) == 1 {
 				log.Debug().
-					Str("player", bestPlayers[0].Name).
+					Str("player", player.Name).
 					Str("pot", sidePot.Name).
-					Msg("won sidepot")
-				bestPlayers[0].ChipCount += sidePot.Total
-			} else {
-				splitChips := sidePot.Total / Chips(len(bestPlayers))
+					Msg("won by folds")
 
-				log.Debug().Str("pot", sidePot.Name).Msgf("split chips: %s", printer.Sprintf("%v", splitChips))
+				player.ChipCount += sidePotShare
+				table.history.award(player, table.historyPot(sidePot), sidePotShare)
 
-				for _, player := range bestPlayers {
-					player.ChipCount += splitChips
+				playerMap[player.Name] = player
+			} else {
+				if len(boards) > 1 {
+					sidePot.WinInfo += fmt.Sprintf("run #%d:\n", run+1)
 				}
 
-				//table.State = TableStateSplitPot
-			}
+				bestPlayers := table.awardPot(sidePotShare, slices.Collect(maps.Values(sidePot.Players)), sidePot)
 
-			for _, p := range bestPlayers {
-				playerMap[p.Name] = p
+				if len(boards) > 1 {
+					sidePot.WinInfo += "\n"
+				}
+
+				for _, p := range bestPlayers {
+					log.Debug().
+						Str("player", p.Name).
+						Str("pot", sidePot.Name).
+						Msg("won sidepot")
+					playerMap[p.Name] = p
+				}
 			}
 		}
 	}
 
+	table.history.finish(boards)
+
 	table.Winners = slices.Collect(maps.Values(playerMap))
 	for _, winner := range table.Winners {
 		log.Debug().
@@ -176,4 +214,61 @@ func (table *Table) FinishRound() {
 			Str("chipcount", winner.ChipCountToString()).
 			Msg("final chipcount")
 	}
+
+	return table.checkChips(uncollected)
+}
+
+// records the players dealt into the hand, in seat order starting left of the
+// button, and the chips they start the hand with. see checkChips().
+func (table *Table) startHandChips() {
+	table.handPlayers = table.handPlayers[:0]
+	table.handChips = 0
+
+	for _, node := range table.curPlayersFromDealer() {
+		table.handPlayers = append(table.handPlayers, node.Player)
+		table.handChips += node.Player.ChipCount
+	}
+}
+
+// PlayerLeft takes a player that leaves the table mid-hand out of the hand's
+// chip count. the chips they already bet stay in the pots. must be called
+// before the player's seat is cleared.
+func (table *Table) PlayerLeft(player *Player) {
+	if idx := slices.Index(table.handPlayers, player); idx != -1 {
+		table.handChips -= player.ChipCount
+		table.handPlayers = slices.Delete(table.handPlayers, idx, idx+1)
+	}
+}
+
+// checkChips makes sure no chips were created or lost during the hand: the
+// chips of the players dealt in plus the uncollected chips must add up to
+// the chips they started the hand with.
+func (table *Table) checkChips(uncollected Chips) error {
+	total := uncollected
+	for _, player := range table.handPlayers {
+		total += player.ChipCount
+	}
+
+	if total != table.handChips {
+		log.Error().
+			Str("total", printer.Sprintf("%d", total)).
+			Str("uncollected", printer.Sprintf("%d", uncollected)).
+			Str("startOfHand", printer.Sprintf("%d", table.handChips)).
+			Msg("chip count mismatch")
+
+		return errors.New(printer.Sprintf("BUG: chip count mismatch: players have %d chips (%d uncollected) but started the hand with %d",
+			total, uncollected, table.handChips))
+	}
+
+	return nil
+}
+
+// sorts players by seat starting left of the button
+func (table *Table) seatOrder(players []*Player) []*Player {
+	sorted := append([]*Player{}, players...)
+	slices.SortStableFunc(sorted, func(a, b *Player) int {
+		return slices.Index(table.handPlayers, a) - slices.Index(table.handPlayers, b)
+	})
+
+	return sorted
 }
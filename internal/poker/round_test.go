@@ -0,0 +1,99 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func TestOddChipsGoLeftOfButton(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000, 1000)
+	table.Community = mustCards(t, "Ah Kh Qh Jh Th") // everyone plays the board
+
+	players := make(map[string]*Player)
+	for _, player := range table.activePlayers.ToPlayerArray() {
+		player.ChipCount = 0
+		players[player.Name] = player
+	}
+	p0, p1, p3 := players["p0"], players["p1"], players["p3"]
+
+	// NOTE: p0 is the button, so p3 is the last player left of it
+	table.awardPot(5, []*Player{p3, p0, p1}, nil)
+
+	for _, tt := range []struct {
+		player *Player
+		want   Chips
+	}{
+		{p1, 2},
+		{p3, 2},
+		{p0, 1},
+	} {
+		if tt.player.ChipCount != tt.want {
+			t.Errorf("%s got %d chips, want %d", tt.player.Name, tt.player.ChipCount, tt.want)
+		}
+	}
+}
+
+func TestFinishRoundChecksChips(t *testing.T) {
+	const fold = playerState.Fold
+
+	t.Run("chips add up", func(t *testing.T) {
+		table := newTestGame(t, 1000, 1000, 1000, 1000)
+
+		runBettingSteps(t, table, []bettingStep{
+			{player: "p3", action: fold},
+			{player: "p0", action: fold},
+			{player: "p1", action: fold},
+		})
+
+		if err := table.FinishRound(); err != nil {
+			t.Fatalf("FinishRound: %v", err)
+		}
+	})
+
+	t.Run("player left mid-hand", func(t *testing.T) {
+		table := newTestGame(t, 1000, 1000, 1000, 1000)
+
+		runBettingSteps(t, table, []bettingStep{
+			{player: "p3", action: playerState.Bet, amount: 20},
+		})
+
+		var p3 *Player
+		for _, player := range table.activePlayers.ToPlayerArray() {
+			if player.Name == "p3" {
+				p3 = player
+			}
+		}
+		table.PlayerLeft(p3)
+		table.activePlayers.RemovePlayer(p3)
+		table.curPlayers.RemovePlayer(p3)
+		p3.Clear(DefaultStartingStack)
+
+		runBettingSteps(t, table, []bettingStep{
+			{player: "p0", action: fold},
+			{player: "p1", action: fold},
+		})
+
+		if err := table.FinishRound(); err != nil {
+			t.Fatalf("FinishRound: %v", err)
+		}
+	})
+
+	t.Run("missing chips are reported", func(t *testing.T) {
+		table := newTestGame(t, 1000, 1000, 1000, 1000)
+
+		runBettingSteps(t, table, []bettingStep{
+			{player: "p3", action: fold},
+			{player: "p0", action: fold},
+			{player: "p1", action: fold},
+		})
+
+		table.activePlayers.Head.Player.ChipCount--
+
+		err := table.FinishRound()
+		if err == nil || !strings.Contains(err.Error(), "chip count mismatch") {
+			t.Fatalf("expected a chip count mismatch, got %v", err)
+		}
+	})
+}
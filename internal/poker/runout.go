@@ -0,0 +1,116 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// the most times the rest of the board can be run
+const MaxRunItTimes = 4
+
+// SetRunItTimes changes how many times the rest of the board can be run when
+// every player is all in. 0 and 1 mean the board is always run once. only
+// allowed between games.
+func (table *Table) SetRunItTimes(times uint8) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if times > MaxRunItTimes {
+		return fmt.Errorf("the board can be run at most %d times", MaxRunItTimes)
+	} else if table.State != TableStateNotStarted {
+		return errors.New("run it twice can't be changed while a game is in progress")
+	}
+
+	table.RunItTimes = times
+
+	return nil
+}
+
+// number of community cards left to deal this hand
+func (table *Table) communityLeft() int {
+	left := 0
+	for _, street := range table.variant.Streets()[table.streetIndex()+1:] {
+		left += street.Community
+	}
+
+	return left
+}
+
+// number of streets left to deal this hand. a card is burned before each one.
+func (table *Table) streetsLeft() int {
+	return len(table.variant.Streets()[table.streetIndex()+1:])
+}
+
+// RunItTimesOffered returns how many times the rest of the board can be run
+// this hand. it's 1 unless betting is over with community cards left to come
+// and the table allows running it more than once. stud boards are only run
+// once since the players' own cards would have to be dealt again.
+//
+// NOTE: the deck has to have enough cards left for every run, burn cards
+// included.
+func (table *Table) RunItTimesOffered() int {
+	if table.RunItTimes < 2 || table.isStud() || !table.BettingIsImpossible() ||
+		len(table.GetNonFoldedPlayers()) < 2 {
+		return 1
+	}
+
+	left := table.communityLeft()
+	if left == 0 {
+		return 1
+	}
+
+	return max(min(int(table.RunItTimes), table.deck.CardsLeft()/(left+table.streetsLeft())), 1)
+}
+
+// StartRunout starts dealing the rest of the board times times. every run is
+// dealt from the same deck starting from the community cards dealt so far.
+// call NextRun() after each run is dealt.
+func (table *Table) StartRunout(times int) {
+	table.Boards = nil
+	table.runsLeft = max(times, 1) - 1
+	table.runoutFrom = len(table.Community)
+	table.runoutCommState = table.CommState
+
+	log.Debug().
+		Int("times", times).
+		Int("fromCard", table.runoutFrom).
+		Msg("running the board")
+}
+
+// NextRun saves the board of the run that was just dealt and rewinds the
+// community cards to where the runout started. it returns false once every
+// run has been dealt, the community cards are then left as the last run's.
+func (table *Table) NextRun() bool {
+	if table.runsLeft == 0 && len(table.Boards) == 0 {
+		return false // only run once
+	}
+
+	table.Boards = append(table.Boards, append(Cards{}, table.Community...))
+	if table.runsLeft == 0 {
+		return false
+	}
+	table.runsLeft--
+
+	table.Community = append(Cards{}, table.Community[:table.runoutFrom]...)
+	table._comsorted = append(Cards{}, table.Community...)
+	table.SortCommunity()
+	table.CommState = table.runoutCommState
+	table.State = TableStateRounds
+
+	log.Debug().Int("run", len(table.Boards)+1).Msg("running the board again")
+
+	return true
+}
+
+// the part of a pot that's awarded in run out of runs. odd chips go to the
+// first runs.
+func runShare(total Chips, run, runs int) Chips {
+	share := total / Chips(runs)
+	if Chips(run) < total%Chips(runs) {
+		share++
+	}
+
+	return share
+}
@@ -0,0 +1,109 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func TestRunShare(t *testing.T) {
+	tests := []struct {
+		total Chips
+		runs  int
+		want  []Chips
+	}{
+		{100, 2, []Chips{50, 50}},
+		{101, 2, []Chips{51, 50}},
+		{100, 3, []Chips{34, 33, 33}},
+		{5, 4, []Chips{2, 1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		var sum Chips
+		for run := 0; run < tt.runs; run++ {
+			share := runShare(tt.total, run, tt.runs)
+			if share != tt.want[run] {
+				t.Errorf("runShare(%d, %d, %d) = %d, want %d", tt.total, run, tt.runs, share, tt.want[run])
+			}
+			sum += share
+		}
+
+		if sum != tt.total {
+			t.Errorf("shares of %d over %d runs add up to %d", tt.total, tt.runs, sum)
+		}
+	}
+}
+
+func TestNextRunRewindsBoard(t *testing.T) {
+	table := newTestTable(t, "Ah Kh Qh")
+	table.CommState = TableStateFlop
+
+	table.StartRunout(2)
+
+	table.Community = append(table.Community, mustCards(t, "2c 3d")...)
+	table.State = TableStateRoundOver
+	if !table.NextRun() {
+		t.Fatal("expected a second run")
+	}
+
+	if len(table.Community) != 3 || table.CommState != TableStateFlop || table.State != TableStateRounds {
+		t.Fatalf("board wasn't rewound to the flop: %s (state %d, commState %d)",
+			table.CommunityToString(), table.State, table.CommState)
+	}
+
+	table.Community = append(table.Community, mustCards(t, "4h 5s")...)
+	if table.NextRun() {
+		t.Fatal("expected the runout to be over")
+	}
+
+	if len(table.Boards) != 2 {
+		t.Fatalf("expected 2 boards, got %d", len(table.Boards))
+	}
+	for i, want := range []string{"2c", "4h"} {
+		if got, wantCard := table.Boards[i][3], mustCards(t, want)[0]; got.Name != wantCard.Name {
+			t.Errorf("board %d has turn %s, want %s", i+1, got.Name, wantCard.Name)
+		}
+	}
+}
+
+func TestFinishRoundSplitsPotBetweenRuns(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Fold},
+		{player: "p1", action: playerState.AllIn},
+		{player: "p2", action: playerState.Call},
+	})
+
+	players := make(map[string]*Player)
+	for _, player := range table.activePlayers.ToPlayerArray() {
+		players[player.Name] = player
+	}
+	p1, p2 := players["p1"], players["p2"]
+	p1.Hole.Cards = mustCards(t, "Ac Ad")
+	p1.Hole.FillHoleInfo()
+	p2.Hole.Cards = mustCards(t, "Kc Kd")
+	p2.Hole.FillHoleInfo()
+
+	// p1's aces hold on the first run, p2 hits a king on the second
+	table.Boards = []Cards{
+		mustCards(t, "2h 7s 9c Jd 3h"),
+		mustCards(t, "2h 7s 9c Ks 3h"),
+	}
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	if p1.ChipCount != 1000 || p2.ChipCount != 1000 {
+		t.Fatalf("expected each run to win half the pot, p1 has %d chips, p2 has %d\n%s",
+			p1.ChipCount, p2.ChipCount, table.WinInfo)
+	}
+
+	for _, info := range []string{"run #1: ", "run #2: "} {
+		if !strings.Contains(table.WinInfo, info) {
+			t.Errorf("WinInfo doesn't contain %q:\n%s", info, table.WinInfo)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package poker
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/bkazemi/gopoker/internal/playerState"
 	"github.com/rs/zerolog/log"
 )
@@ -26,6 +29,7 @@ func (table *Table) GetSeat(_pos uint8) *Player {
 	seat := table.players[pos]
 	seat.IsVacant = false
 	seat.TablePos = uint(pos)
+	seat.TimeBank = table.TimeBank
 	table.NumPlayers++
 
 	return seat
@@ -43,6 +47,7 @@ func (table *Table) GetOpenSeat() *Player {
 		if seat.IsVacant {
 			seat.IsVacant = false
 			seat.TablePos = uint(i)
+			seat.TimeBank = table.TimeBank
 			table.NumPlayers++
 
 			return seat
@@ -81,7 +86,8 @@ func (table *Table) getActiveSeats() []*Player {
 
 	for _, seat := range table.players {
 		if !seat.IsVacant &&
-			seat.Action.Action != playerState.MidroundAddition {
+			seat.Action.Action != playerState.MidroundAddition &&
+			seat.Action.Action != playerState.SittingOut {
 			seats = append(seats, seat)
 		}
 	}
@@ -102,6 +108,146 @@ func (table *Table) addNewPlayers() {
 	}
 }
 
+// SitOut marks a seated player as sitting out. if they're in the current
+// hand they stay in it (the frontend should act for them), and they are
+// skipped starting with the next hand.
+func (table *Table) SitOut(player *Player) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if table.State == TableStateNotStarted {
+		return errors.New("a game has not been started yet")
+	} else if player.IsSittingOut {
+		return errors.New("you are already sitting out")
+	}
+
+	player.IsSittingOut = true
+
+	return nil
+}
+
+// SitIn brings back a player that is sitting out. a player that missed any
+// hands is added back like a midround addition. if the big blind passed
+// their seat while they were away they post it on their first hand back,
+// see missBigBlinds().
+func (table *Table) SitIn(player *Player) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if !player.IsSittingOut {
+		return errors.New("you are not sitting out")
+	}
+
+	player.IsSittingOut, player.timeouts = false, 0
+
+	if player.Action.Action == playerState.SittingOut {
+		log.Debug().Str("player", player.Name).Uint64("handsSatOut", player.handsSatOut).Msg("sitting back in")
+
+		player.handsSatOut = 0
+		player.Action.Action = playerState.MidroundAddition
+		table.addActivePlayer(player)
+	}
+
+	return nil
+}
+
+// adds a player back to the active players list after the player seated
+// closest to their right, so that the hands are dealt and played in seat
+// order.
+func (table *Table) addActivePlayer(player *Player) {
+	numSeats := len(table.players)
+
+	for i := 1; i < numSeats; i++ {
+		prev := table.players[(int(player.TablePos)-i+numSeats)%numSeats]
+		if prev.IsVacant {
+			continue
+		}
+
+		if node := table.activePlayers.GetPlayerNode(prev); node != nil {
+			node.SetNext(&PlayerNode{Player: player, next: node.Next()})
+			table.activePlayers.Len++
+
+			return
+		}
+	}
+
+	table.activePlayers.AddPlayer(player)
+}
+
+// takes players that are sitting out out of the active players list. they
+// keep their seat but aren't dealt in. called at the start of a new hand.
+func (table *Table) sitOutPlayers() {
+	for _, player := range table.players {
+		if !player.IsVacant && player.Action.Action == playerState.SittingOut {
+			player.handsSatOut++
+		}
+	}
+
+	sittingOut := make([]*Player, 0)
+	for _, player := range table.activePlayers.ToPlayerArray() {
+		if player.IsSittingOut {
+			sittingOut = append(sittingOut, player)
+		}
+	}
+
+	if table.activePlayers.Len-len(sittingOut) < 2 {
+		// not enough players left for a hand. deal everyone back in and let
+		// the frontend act for the players that are sitting out
+		for _, player := range table.players {
+			if !player.IsVacant && player.Action.Action == playerState.SittingOut {
+				log.Debug().Str("player", player.Name).Msg("not enough players, dealing in sitting out player")
+				player.Action.Action = playerState.FirstAction
+				table.addActivePlayer(player)
+			}
+		}
+
+		return
+	}
+
+	for _, player := range sittingOut {
+		log.Debug().Str("player", player.Name).Msg("sitting out")
+
+		table.activePlayers.RemovePlayer(player)
+
+		if table.Dealer != nil && player == table.Dealer.Player {
+			table.Dealer = nil
+		}
+		if table.SmallBlind != nil && player == table.SmallBlind.Player {
+			table.SmallBlind = nil
+		}
+		if table.BigBlind != nil && player == table.BigBlind.Player {
+			table.BigBlind = nil
+		}
+
+		player.Action.Action, player.Action.Amount = playerState.SittingOut, 0
+		player.handsSatOut = 1 // this hand
+	}
+}
+
+// GetSatOutPlayers returns the players that have sat out for at least
+// table.SitOutOrbits orbits. an orbit is counted as one hand per seated player.
+func (table *Table) GetSatOutPlayers() []*Player {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	ret := make([]*Player, 0)
+
+	if table.SitOutOrbits == 0 {
+		return ret
+	}
+
+	orbit := max(uint64(table.NumPlayers), 2)
+
+	for _, player := range table.players {
+		if !player.IsVacant && player.Action.Action == playerState.SittingOut &&
+			player.handsSatOut >= uint64(table.SitOutOrbits)*orbit {
+			ret = append(ret, player)
+		}
+	}
+
+	return ret
+}
+
 func (table *Table) GetEliminatedPlayers() []*Player {
 	table.mtx.Lock()
 	defer table.mtx.Unlock()
@@ -133,18 +279,34 @@ func (table *Table) GetEliminatedPlayers() []*Player {
 }
 
 // resets the active players list head to
-// Bb+1 pre-flop
-// Sb post-flop
+// Bb+1 pre-flop (the dealer when heads up)
+// Sb post-flop (the big blind when heads up)
+// see reorderStudPlayers() for stud
 func (table *Table) ReorderPlayers() {
+	if table.isStud() {
+		table.reorderStudPlayers()
+		return
+	}
+
 	if table.State == TableStateNewRound ||
-		table.State == TableStatePreFlop {
-		table.activePlayers.SetHead(table.BigBlind.Next())
-		table.curPlayers.SetHead(table.curPlayers.GetPlayerNode(table.BigBlind.Next().Player))
+		table.State == TableStatePreFlop ||
+		(table.State == TableStatePlayerRaised && table.CommState == TableStatePreFlop) {
+		// NOTE: the straddler acts last preflop, see postStraddle()
+		first := table.BigBlind.Next()
+		if table.Straddler != nil {
+			first = table.Straddler.Next()
+		}
+		table.activePlayers.SetHead(first)
+		table.curPlayers.SetHead(table.curPlayers.GetPlayerNode(first.Player))
 		Assert(table.curPlayers.Head != nil,
 			"Table.ReorderPlayers(): couldn't find Bb+1 player node")
 		log.Debug().Str("curPlayersHead", table.curPlayers.Head.Player.Name).Msg("curPlayers head now")
 	} else { // post-flop
 		smallBlindNode := table.SmallBlind
+		if table.isHeadsUp() && table.Dealer != nil {
+			// heads up the dealer is the small blind and acts last after the flop
+			smallBlindNode = table.Dealer.Next()
+		}
 		if smallBlindNode == nil { // smallblind left mid game
 			if table.Dealer != nil {
 				smallBlindNode = table.Dealer.Next()
@@ -161,10 +323,13 @@ func (table *Table) ReorderPlayers() {
 			}
 			log.Debug().Str("curPlayer", smallBlindNode.Player.Name).Msg("smallblind left mid round")
 		}
-		smallBlindNode = table.curPlayers.GetPlayerNode(smallBlindNode.Player)
-		if smallBlindNode == nil {
+		if node := table.curPlayers.GetPlayerNode(smallBlindNode.Player); node != nil {
+			smallBlindNode = node
+		} else {
 			// small-blind folded or is all in so we need to search activePlayers for next actively betting player
-			smallBlindNode = table.SmallBlind.Next()
+			smallBlindName := smallBlindNode.Player.Name
+
+			smallBlindNode = smallBlindNode.Next()
 			for !smallBlindNode.Player.canBet() {
 				smallBlindNode = smallBlindNode.Next()
 			}
@@ -174,7 +339,7 @@ func (table *Table) ReorderPlayers() {
 			Assert(smallBlindNode != nil, "Table.ReorderPlayers(): couldn't find a nonfolded player after Sb")
 
 			log.Debug().
-				Str("smallBlind", table.SmallBlind.Player.Name).
+				Str("smallBlind", smallBlindName).
 				Str("curPlayer", smallBlindNode.Player.Name).
 				Msg("smallBlind not active")
 		}
@@ -243,41 +408,215 @@ func (table *Table) handleOrphanedSeats() {
 	}
 }
 
-// rotates the dealer and blinds
+// reports whether only two players are dealt in. heads up the dealer posts
+// the small blind and acts first preflop and last after the flop.
+func (table *Table) isHeadsUp() bool {
+	players := 0
+	for _, player := range table.activePlayers.ToPlayerArray() {
+		if player.Action.Action != playerState.MidroundAddition {
+			players++
+		}
+	}
+
+	return players == 2
+}
+
+// the dealer posts the small blind heads up
+func (table *Table) setHeadsUpBlinds() {
+	table.SmallBlind = table.Dealer
+	table.BigBlind = table.Dealer.Next()
+}
+
+// seats (Player.TablePos) of the button and blinds. the button and small
+// blind seats can be empty, see rotatePlayers().
+type tablePositions struct {
+	button, smallBlind, bigBlind uint
+}
+
+// records the seats of the dealer and blinds set for the first hand of a game
+func (table *Table) setPositions() {
+	table.positions = &tablePositions{
+		button:     table.Dealer.Player.TablePos,
+		smallBlind: table.SmallBlind.Player.TablePos,
+		bigBlind:   table.BigBlind.Player.TablePos,
+	}
+	table.DeadButton, table.DeadSmallBlind = false, false
+}
+
+// SetNextPositions places the button and blinds so that the next hand, dealt
+// with NewRound() and NextTableAction(), has them in seats button, smallBlind
+// and bigBlind (Player.TablePos + 1). a smallBlind of 0 is a dead small
+// blind. the players dealt in have to be seated first. used to deal a
+// recorded hand again, see the replay package.
+func (table *Table) SetNextPositions(button, smallBlind, bigBlind uint) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	numSeats := uint(len(table.players))
+
+	if table.activePlayers.Len < 2 {
+		return errors.New("not enough players")
+	} else if button == 0 || bigBlind == 0 || max(button, smallBlind, bigBlind) > numSeats {
+		return errors.New("the button and big blind need a seat")
+	}
+
+	// the last hand's big blind posts the small blind. when the small blind
+	// is dead any empty seat before the big blind stands in for it
+	lastBigBlind := smallBlind - 1
+	if smallBlind == 0 {
+		lastBigBlind = (bigBlind + numSeats - 2) % numSeats
+		if table.activeSeatNode(lastBigBlind) != nil {
+			return fmt.Errorf("there's no empty seat before the big blind in seat %d for a dead small blind", bigBlind)
+		}
+	}
+
+	if next := table.nextActiveSeat(lastBigBlind, 1); next != bigBlind-1 {
+		return fmt.Errorf("the big blind moves on to seat %d, not %d", next+1, bigBlind)
+	}
+
+	// NOTE: the last button is only logged, see rotatePlayers()
+	table.positions = &tablePositions{
+		button:     button - 1,
+		smallBlind: button - 1,
+		bigBlind:   lastBigBlind,
+	}
+
+	return nil
+}
+
+// SetMissedBigBlind marks player as owing the big blind, like a player that
+// the big blind passed while they were sitting out. they post it on the next
+// hand, see postBlinds(). used to deal a recorded hand again.
+func (table *Table) SetMissedBigBlind(player *Player) {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	player.owesBlind = true
+}
+
+// returns the node of the player dealt in from seat, or nil if nobody is
+func (table *Table) activeSeatNode(seat uint) *PlayerNode {
+	if player := table.players[seat]; !player.IsVacant &&
+		player.Action.Action != playerState.MidroundAddition {
+		return table.activePlayers.GetPlayerNode(player)
+	}
+
+	return nil
+}
+
+// returns the first seat after (dir 1) or before (dir -1) seat that a
+// player is dealt in from
+func (table *Table) nextActiveSeat(seat uint, dir int) uint {
+	numSeats := len(table.players)
+
+	for i, pos := 1, int(seat); i < numSeats; i++ {
+		pos = (pos + dir + numSeats) % numSeats
+		if table.activeSeatNode(uint(pos)) != nil {
+			return uint(pos)
+		}
+	}
+
+	Assert(false, "Table.nextActiveSeat(): no other active seat")
+
+	return seat
+}
+
+// moves the button and blinds for the next hand using the dead button rule.
+// the big blind moves to the next player left of the last big blind, the last
+// big blind's seat posts the small blind and the last small blind's seat gets
+// the button. when a player in one of those seats left or sat out the small
+// blind or the button is dead for the hand. this way nobody skips the big
+// blind or posts it twice when players leave. heads up the player that isn't
+// the big blind has the button and the small blind.
 func (table *Table) rotatePlayers() {
 	if table.State == TableStateNotStarted || table.activePlayers.Len < 2 {
 		return
 	}
 
-	if table.Dealer == nil || table.SmallBlind == nil || table.BigBlind == nil {
-		table.handleOrphanedSeats()
+	if table.positions == nil {
+		// NOTE: positions are recorded on the first hand, see NextTableAction()
+		log.Warn().Msg("no positions from the last hand, using the current dealer & blinds")
+		if table.Dealer == nil || table.SmallBlind == nil || table.BigBlind == nil {
+			table.handleOrphanedSeats()
+		}
+		table.setPositions()
 	}
 
+	last := *table.positions
+
 	log.Debug().
-		Str("dealer", table.Dealer.Player.Name).
-		Str("smallBlind", table.SmallBlind.Player.Name).
-		Str("bigBlind", table.BigBlind.Player.Name).
-		Msg("before")
+		Uint("button", last.button).
+		Uint("smallBlind", last.smallBlind).
+		Uint("bigBlind", last.bigBlind).
+		Msg("seats before")
 
 	Panic := &Panic{}
 
 	defer Panic.IfNoPanic(func() {
 		log.Debug().
 			Str("dealer", table.Dealer.Player.Name).
-			Str("smallBlind", table.SmallBlind.Player.Name).
+			Str("smallBlind", table.SmallBlindToString()).
 			Str("bigBlind", table.BigBlind.Player.Name).
+			Bool("deadButton", table.DeadButton).
 			Msg("after")
 
 		table.ReorderPlayers()
 	})
 
-	if table.BigBlind.Next().Player.Name == table.Dealer.Player.Name {
-		table.Dealer = table.BigBlind
-	} else {
-		table.Dealer = table.Dealer.Next()
+	bigBlind := table.nextActiveSeat(last.bigBlind, 1)
+	table.missBigBlinds(last.bigBlind, bigBlind)
+
+	if table.isHeadsUp() {
+		// NOTE: the big blind moves on as usual, also when the game goes from
+		//       three players to two, and the other player gets the button
+		button := table.nextActiveSeat(bigBlind, 1)
+
+		table.positions = &tablePositions{
+			button:     button,
+			smallBlind: button,
+			bigBlind:   bigBlind,
+		}
+
+		table.Dealer = table.activeSeatNode(button)
+		table.setHeadsUpBlinds()
+		table.DeadButton, table.DeadSmallBlind = false, false
+
+		return
+	}
+
+	table.positions = &tablePositions{
+		button:     last.smallBlind,
+		smallBlind: last.bigBlind,
+		bigBlind:   bigBlind,
+	}
+
+	table.BigBlind = table.activeSeatNode(table.positions.bigBlind)
+
+	table.SmallBlind = table.activeSeatNode(table.positions.smallBlind)
+	table.DeadSmallBlind = table.SmallBlind == nil
+
+	table.Dealer = table.activeSeatNode(table.positions.button)
+	table.DeadButton = table.Dealer == nil
+	if table.DeadButton {
+		// NOTE: the player before the button stands in for it so that the
+		//       action still starts left of the button
+		table.Dealer = table.activeSeatNode(table.nextActiveSeat(table.positions.button, -1))
+	}
+}
+
+// marks the players sitting out in the seats the big blind skipped going
+// from seat from to seat to. they owe it when they sit back in, see
+// postBlinds()
+func (table *Table) missBigBlinds(from, to uint) {
+	numSeats := uint(len(table.players))
+
+	for seat := (from + 1) % numSeats; seat != to && seat != from; seat = (seat + 1) % numSeats {
+		player := table.players[seat]
+		if !player.IsVacant && player.Action.Action == playerState.SittingOut && !player.owesBlind {
+			log.Debug().Str("player", player.Name).Msg("missed the big blind")
+			player.owesBlind = true
+		}
 	}
-	table.SmallBlind = table.Dealer.Next()
-	table.BigBlind = table.SmallBlind.Next()
 }
 
 func (table *Table) SetNextPlayerTurn() {
@@ -295,7 +634,7 @@ func (table *Table) SetNextPlayerTurn() {
 
 	defer Panic.IfNoPanic(func() {
 		if table.State == TableStateDoneBetting {
-			table.better = nil
+			table.lastAggressor, table.better = table.better, nil
 			table.calculateSidePotTotals() // TODO: move me
 			table.closeSidePots()
 		}
@@ -0,0 +1,343 @@
+package poker
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+// starts the next hand the same way the frontend does between hands
+func nextHand(t *testing.T, table *Table) {
+	t.Helper()
+
+	table.NewRound()
+	table.NextTableAction()
+
+	if table.BigBlind == nil {
+		t.Fatalf("no big blind after starting a new hand")
+	}
+}
+
+// takes player out of the game the same way the frontend does when they leave
+func testRemovePlayer(table *Table, player *Player) {
+	table.PlayerLeft(player)
+	table.activePlayers.RemovePlayer(player)
+	table.curPlayers.RemovePlayer(player)
+	table.NumPlayers--
+
+	if table.Dealer != nil && player == table.Dealer.Player {
+		table.Dealer = nil
+	}
+	if table.SmallBlind != nil && player == table.SmallBlind.Player {
+		table.SmallBlind = nil
+	}
+	if table.BigBlind != nil && player == table.BigBlind.Player {
+		table.BigBlind = nil
+	}
+
+	player.Clear(table.StartingStack)
+}
+
+func testStacks(numSeats int) []Chips {
+	stacks := make([]Chips, numSeats)
+	for i := range stacks {
+		stacks[i] = 1000
+	}
+
+	return stacks
+}
+
+func nodeName(node *PlayerNode) string {
+	if node == nil {
+		return ""
+	}
+
+	return node.Player.Name
+}
+
+func TestDeadButton(t *testing.T) {
+	type positions struct {
+		dealer, smallBlind, bigBlind string
+		deadButton                   bool
+	}
+
+	// NOTE: the first hand is always p0 dealer, p1 small blind, p2 big blind.
+	//       an empty small blind is a dead small blind
+	tests := []struct {
+		name     string
+		numSeats int
+		leaves   []string // after the first hand
+		want     []positions
+	}{
+		{
+			name:     "no one leaves",
+			numSeats: 4,
+			want: []positions{
+				{dealer: "p1", smallBlind: "p2", bigBlind: "p3"},
+				{dealer: "p2", smallBlind: "p3", bigBlind: "p0"},
+				{dealer: "p3", smallBlind: "p0", bigBlind: "p1"},
+			},
+		},
+		{
+			name:     "dealer leaves",
+			numSeats: 4,
+			leaves:   []string{"p0"},
+			want: []positions{
+				{dealer: "p1", smallBlind: "p2", bigBlind: "p3"},
+				{dealer: "p2", smallBlind: "p3", bigBlind: "p1"},
+			},
+		},
+		{
+			name:     "small blind leaves",
+			numSeats: 5,
+			leaves:   []string{"p1"},
+			want: []positions{
+				{dealer: "p0", smallBlind: "p2", bigBlind: "p3", deadButton: true},
+				{dealer: "p2", smallBlind: "p3", bigBlind: "p4"},
+			},
+		},
+		{
+			name:     "big blind leaves",
+			numSeats: 5,
+			leaves:   []string{"p2"},
+			want: []positions{
+				{dealer: "p1", smallBlind: "", bigBlind: "p3"},
+				{dealer: "p1", smallBlind: "p3", bigBlind: "p4", deadButton: true},
+				{dealer: "p3", smallBlind: "p4", bigBlind: "p0"},
+			},
+		},
+		{
+			name:     "both blinds leave",
+			numSeats: 6,
+			leaves:   []string{"p1", "p2"},
+			want: []positions{
+				{dealer: "p0", smallBlind: "", bigBlind: "p3", deadButton: true},
+				{dealer: "p0", smallBlind: "p3", bigBlind: "p4", deadButton: true},
+				{dealer: "p3", smallBlind: "p4", bigBlind: "p5"},
+			},
+		},
+		{
+			name:     "next big blind leaves",
+			numSeats: 7,
+			leaves:   []string{"p3"},
+			want: []positions{
+				{dealer: "p1", smallBlind: "p2", bigBlind: "p4"},
+				{dealer: "p2", smallBlind: "p4", bigBlind: "p5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestGame(t, testStacks(tt.numSeats)...)
+
+			players := testPlayersByName(table)
+			for _, name := range tt.leaves {
+				testRemovePlayer(table, players[name])
+			}
+
+			for i, want := range tt.want {
+				nextHand(t, table)
+
+				got := positions{
+					dealer:     nodeName(table.Dealer),
+					smallBlind: nodeName(table.SmallBlind),
+					bigBlind:   nodeName(table.BigBlind),
+					deadButton: table.DeadButton,
+				}
+				if got != want {
+					t.Fatalf("hand %d: got %+v, want %+v", i+2, got, want)
+				}
+				if table.DeadSmallBlind != (want.smallBlind == "") {
+					t.Fatalf("hand %d: DeadSmallBlind is %v", i+2, table.DeadSmallBlind)
+				}
+			}
+		})
+	}
+}
+
+// whoever leaves, the big blind has to keep moving one player to the left so
+// that nobody skips it or posts it twice
+func TestBigBlindOrbit(t *testing.T) {
+	for numSeats := 2; numSeats <= 7; numSeats++ {
+		for leaver := -1; leaver < numSeats; leaver++ {
+			if leaver != -1 && numSeats == 2 {
+				continue // NOTE: nobody left to play
+			}
+
+			name := fmt.Sprintf("%d seats/nobody leaves", numSeats)
+			if leaver != -1 {
+				name = fmt.Sprintf("%d seats/p%d leaves", numSeats, leaver)
+			}
+
+			t.Run(name, func(t *testing.T) {
+				table := newTestGame(t, testStacks(numSeats)...)
+
+				seats := slices.Clone(table.players)
+				lastBigBlind := table.BigBlind.Player.TablePos
+				lastBigBlindName := table.BigBlind.Player.Name
+
+				if leaver != -1 {
+					testRemovePlayer(table, seats[leaver])
+				}
+
+				// the players left, clockwise from the first hand's big blind
+				var want []string
+				for i := 1; i <= numSeats; i++ {
+					if seat := (int(lastBigBlind) + i) % numSeats; seat != leaver {
+						want = append(want, seats[seat].Name)
+					}
+				}
+				headsUp := len(want) == 2
+				want = slices.Concat(want, want) // two orbits
+
+				for hand, wantBigBlind := range want {
+					nextHand(t, table)
+
+					if got := table.BigBlind.Player.Name; got != wantBigBlind {
+						t.Fatalf("hand %d: %s posted the big blind, want %s", hand+2, got, wantBigBlind)
+					}
+
+					// the last big blind posts the small blind, unless they left.
+					// heads up the dealer posts it
+					if headsUp {
+						if table.SmallBlind == nil || table.SmallBlind != table.Dealer ||
+							table.SmallBlind == table.BigBlind {
+							t.Fatalf("hand %d: heads up the dealer %s must post the small blind, got %q",
+								hand+2, nodeName(table.Dealer), nodeName(table.SmallBlind))
+						}
+					} else if table.SmallBlind == nil {
+						if hand != 0 || leaver != int(lastBigBlind) {
+							t.Fatalf("hand %d: dead small blind", hand+2)
+						}
+					} else if got := table.SmallBlind.Player.Name; got != lastBigBlindName {
+						t.Fatalf("hand %d: %s posted the small blind, want %s", hand+2, got, lastBigBlindName)
+					}
+
+					lastBigBlindName = table.BigBlind.Player.Name
+				}
+			})
+		}
+	}
+}
+
+func TestSitOut(t *testing.T) {
+	// NOTE: the first hand is p0 dealer, p1 small blind, p2 big blind
+	table := newTestGame(t, testStacks(4)...)
+	players := testPlayersByName(table)
+	p3 := players["p3"]
+
+	if err := table.SitIn(p3); err == nil {
+		t.Errorf("sat in a player that isn't sitting out")
+	}
+	if err := table.SitOut(p3); err != nil {
+		t.Fatalf("SitOut: %v", err)
+	}
+	if err := table.SitOut(p3); err == nil {
+		t.Errorf("sat out a player that is already sitting out")
+	}
+
+	// the big blind skips p3 and they owe it
+	nextHand(t, table)
+
+	if p3.Action.Action != playerState.SittingOut || slices.Contains(table.curPlayers.ToPlayerArray(), p3) {
+		t.Fatalf("sitting out player was dealt in")
+	}
+	if got := nodeName(table.BigBlind); got != "p0" {
+		t.Errorf("%s posted the big blind, want p0", got)
+	}
+	if !p3.owesBlind {
+		t.Errorf("p3 doesn't owe the big blind that passed them")
+	}
+
+	if err := table.SitIn(p3); err != nil {
+		t.Fatalf("SitIn: %v", err)
+	}
+
+	chips := p3.ChipCount
+	nextHand(t, table)
+
+	if !slices.Contains(table.curPlayers.ToPlayerArray(), p3) {
+		t.Fatalf("p3 wasn't dealt in after sitting back in")
+	}
+	if got := chips - p3.ChipCount; got != table.Blinds.BigBlind || p3.Action.Amount != got || p3.owesBlind {
+		t.Errorf("p3 posted %d chips coming back, want the big blind", got)
+	}
+}
+
+// a player that comes back before the big blind gets to them posts nothing
+func TestSitInBeforeBigBlind(t *testing.T) {
+	// NOTE: the first hand is p0 dealer, p1 small blind, p2 big blind
+	table := newTestGame(t, testStacks(5)...)
+	p0 := testPlayersByName(table)["p0"]
+
+	if err := table.SitOut(p0); err != nil {
+		t.Fatalf("SitOut: %v", err)
+	}
+
+	nextHand(t, table)
+	if got := nodeName(table.BigBlind); got != "p3" || p0.owesBlind {
+		t.Fatalf("%s posted the big blind, p0 owes it: %v", got, p0.owesBlind)
+	}
+
+	if err := table.SitIn(p0); err != nil {
+		t.Fatalf("SitIn: %v", err)
+	}
+
+	chips := p0.ChipCount
+	nextHand(t, table)
+
+	if p0.Action.Action == playerState.SittingOut || p0.ChipCount != chips {
+		t.Errorf("p0 %s and paid %d chips coming back", p0.ActionToString(), chips-p0.ChipCount)
+	}
+
+	nextHand(t, table)
+	if got := nodeName(table.BigBlind); got != "p0" {
+		t.Errorf("%s posted the big blind, want p0", got)
+	}
+}
+
+func TestGetSatOutPlayers(t *testing.T) {
+	table := newTestGame(t, testStacks(3)...)
+	table.SitOutOrbits = 1
+	p2 := testPlayersByName(table)["p2"]
+
+	if err := table.SitOut(p2); err != nil {
+		t.Fatalf("SitOut: %v", err)
+	}
+
+	// NOTE: an orbit is one hand per seated player
+	for hand := 1; hand <= 3; hand++ {
+		nextHand(t, table)
+
+		satOut := table.GetSatOutPlayers()
+		if want := hand == 3; want != slices.Contains(satOut, p2) || len(satOut) > 1 {
+			t.Fatalf("after %d hands sitting out GetSatOutPlayers returned %d players", hand, len(satOut))
+		}
+	}
+}
+
+// a player sitting back in is dealt in from their seat
+func TestSitInSeatOrder(t *testing.T) {
+	table := newTestGame(t, testStacks(5)...)
+	p2 := testPlayersByName(table)["p2"]
+
+	if err := table.SitOut(p2); err != nil {
+		t.Fatalf("SitOut: %v", err)
+	}
+	nextHand(t, table)
+	if err := table.SitIn(p2); err != nil {
+		t.Fatalf("SitIn: %v", err)
+	}
+	nextHand(t, table)
+
+	node := table.activePlayers.GetPlayerNode(testPlayersByName(table)["p0"])
+	for _, want := range []string{"p0", "p1", "p2", "p3", "p4"} {
+		if node.Player.Name != want {
+			t.Fatalf("%s is dealt in where %s should be", node.Player.Name, want)
+		}
+		node = node.Next()
+	}
+}
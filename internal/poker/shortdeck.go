@@ -0,0 +1,38 @@
+package poker
+
+import math_rand "math/rand/v2"
+
+// short deck (6+) hold'em. the deck has no twos through fives, a flush
+// beats a full house and A-6-7-8-9 is the lowest straight.
+type ShortDeck struct{}
+
+func (ShortDeck) Name() string {
+	return "short deck"
+}
+
+func (ShortDeck) NewDeck(src math_rand.Source) *Deck {
+	return NewShortDeck(src)
+}
+
+func (ShortDeck) NumHoleCards() int {
+	return 2
+}
+
+func (ShortDeck) Streets() []Street {
+	return holdemStreets
+}
+
+func (ShortDeck) RankHand(table *Table, player *Player) {
+	if len(table.Community) < 3 {
+		return
+	}
+
+	cards := append(Cards{}, table.Community...)
+	cards = append(cards, player.Hole.Cards...)
+
+	assembleHand(player, cards, shortDeckHandRules)
+}
+
+func (ShortDeck) CompareHands(hand, otherHand *Hand) int {
+	return shortDeckHandRules.compare(hand, otherHand)
+}
@@ -0,0 +1,163 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewShortDeck(t *testing.T) {
+	deck := NewShortDeck(nil)
+
+	if deck.size != 36 || len(deck.cards) != 36 {
+		t.Fatalf("deck size mismatch: got %d (%d cards), want 36", deck.size, len(deck.cards))
+	}
+
+	seen := make(map[[2]int]bool)
+	for _, card := range deck.cards {
+		if card.NumValue < CardSix {
+			t.Fatalf("short deck has a %s", card.FullName)
+		}
+
+		key := [2]int{int(card.NumValue), int(card.Suit)}
+		if seen[key] {
+			t.Fatalf("short deck has two %s", card.FullName)
+		}
+		seen[key] = true
+	}
+}
+
+func TestShortDeckAssembleBestHand(t *testing.T) {
+	tests := []struct {
+		assembleBestHandCase
+		variant Variant
+	}{
+		{
+			assembleBestHandCase{
+				name:      "A-6-7-8-9 is a straight",
+				community: "6c 7d 8h Ks Qd",
+				hole:      "As 9c",
+				wantRank:  RankStraight,
+				wantCards: []CardVal{CardAce, CardSix, CardSeven, CardEight, CardNine},
+			},
+			ShortDeck{},
+		},
+		{
+			assembleBestHandCase{
+				name:      "A-6-7-8-9 isn't a straight in hold'em",
+				community: "6c 7d 8h Ks Qd",
+				hole:      "As 9c",
+				wantRank:  RankHighCard,
+				wantCards: []CardVal{CardEight, CardNine, CardQueen, CardKing, CardAce},
+			},
+			Holdem{},
+		},
+		{
+			assembleBestHandCase{
+				name:      "A-6-7-8-9 straight flush",
+				community: "6h 7h 8h Ks Qd",
+				hole:      "Ah 9h",
+				wantRank:  RankStraightFlush,
+				wantCards: []CardVal{CardAce, CardSix, CardSeven, CardEight, CardNine},
+			},
+			ShortDeck{},
+		},
+		{
+			assembleBestHandCase{
+				name:      "higher straight is picked over the wheel",
+				community: "6c 7d 8h Ks Td",
+				hole:      "As 9c",
+				wantRank:  RankStraight,
+				wantCards: []CardVal{CardSix, CardSeven, CardEight, CardNine, CardTen},
+			},
+			ShortDeck{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestTable(t, tt.community)
+			table.variant = tt.variant
+			player := mustPlayerWithHole(t, "p1", tt.hole)
+
+			AssembleBestHand(false, table, player)
+
+			if player.Hand.Rank != tt.wantRank {
+				t.Fatalf("rank mismatch: got %s (%d), want %s (%d)",
+					player.Hand.RankName(), player.Hand.Rank, (&Hand{Rank: tt.wantRank}).RankName(), tt.wantRank)
+			}
+
+			if got := handValues(player.Hand.Cards); !reflect.DeepEqual(got, tt.wantCards) {
+				t.Fatalf("hand cards mismatch: got %v, want %v", got, tt.wantCards)
+			}
+		})
+	}
+}
+
+func TestShortDeckBestHand(t *testing.T) {
+	tests := []struct {
+		name        string
+		community   string
+		holes       []string
+		variant     Variant
+		wantWinners []string
+	}{
+		{
+			name:        "flush beats full house",
+			community:   "Ah Kh 7h 7c 6d",
+			holes:       []string{"Qh 9h", "7s Ks"},
+			variant:     ShortDeck{},
+			wantWinners: []string{"p1"},
+		},
+		{
+			name:        "full house beats flush in hold'em",
+			community:   "Ah Kh 7h 7c 6d",
+			holes:       []string{"Qh 9h", "7s Ks"},
+			variant:     Holdem{},
+			wantWinners: []string{"p2"},
+		},
+		{
+			name:        "quads beat a flush",
+			community:   "Ah Kh 7h 7c 6d",
+			holes:       []string{"Qh 9h", "7s 7d"},
+			variant:     ShortDeck{},
+			wantWinners: []string{"p2"},
+		},
+		{
+			name:        "full house beats a straight",
+			community:   "6c 7d 8h 8s Qd",
+			holes:       []string{"9c Tc", "8c Qh"},
+			variant:     ShortDeck{},
+			wantWinners: []string{"p2"},
+		},
+		{
+			name:        "wheel loses to a six high straight",
+			community:   "6c 7d 8h 9s Kd",
+			holes:       []string{"As Qc", "Tc Qh"},
+			variant:     ShortDeck{},
+			wantWinners: []string{"p2"},
+		},
+		{
+			name:        "flushes compare by high card",
+			community:   "Ah 9h 7h 7c 6d",
+			holes:       []string{"Qh 8h", "Kh 6h"},
+			variant:     ShortDeck{},
+			wantWinners: []string{"p2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestTable(t, tt.community)
+			table.variant = tt.variant
+
+			players := make([]*Player, 0, len(tt.holes))
+			for i, hole := range tt.holes {
+				players = append(players, mustPlayerWithHole(t, "p"+string(rune('1'+i)), hole))
+			}
+
+			if got := winnerNames(table.BestHand(players, nil)); !reflect.DeepEqual(got, tt.wantWinners) {
+				t.Fatalf("winners mismatch: got %v, want %v", got, tt.wantWinners)
+			}
+		})
+	}
+}
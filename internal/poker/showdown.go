@@ -0,0 +1,194 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+	"github.com/rs/zerolog/log"
+)
+
+// true once FinishRound() has decided the hand and until the next one starts
+func (table *Table) handIsOver() bool {
+	switch table.State {
+	case TableStateRoundOver, TableStateShowHands, TableStateSplitPot, TableStateGameOver:
+		return true
+	}
+
+	return false
+}
+
+// the players left in the hand in the order they show their hands: the last
+// player to bet or raise on the final street shows first, then the rest
+// clockwise from them. when the final street was checked through the first
+// player left of the button shows first.
+func (table *Table) showdownOrder() []*Player {
+	players := make([]*Player, 0, len(table.handPlayers))
+	for _, player := range table.handPlayers { // NOTE: in seat order from the button
+		if !player.IsVacant && player.Action.Action != playerState.Fold {
+			players = append(players, player)
+		}
+	}
+
+	if idx := slices.Index(players, table.lastAggressor); idx > 0 {
+		players = slices.Concat(players[idx:], players[:idx])
+	}
+
+	return players
+}
+
+// ShowdownOrder returns the players left in the hand in the order they show
+// their hands. see showdownOrder()
+func (table *Table) ShowdownOrder() []*Player {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	return table.showdownOrder()
+}
+
+// Showdown goes through the players left in the hand in showdown order and
+// returns the players that show their hands and the players that muck. the
+// first player to show and every player that won part of a pot always show,
+// the others muck if mucks returns true for them. a mucked hand is hidden
+// from the hand summary. returns nothing if the hand was won by folds.
+//
+// NOTE: call after FinishRound()
+func (table *Table) Showdown(mucks func(player *Player) bool) (shown, mucked []*Player) {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	players := table.showdownOrder()
+	if len(players) < 2 {
+		return nil, nil
+	}
+
+	for i, player := range players {
+		if i > 0 && !player.showsHand && !slices.Contains(table.Winners, player) && mucks(player) {
+			log.Debug().Str("player", player.Name).Msg("mucked")
+			table.WinInfo = muckWinInfo(table.WinInfo, player)
+			mucked = append(mucked, player)
+
+			continue
+		}
+
+		player.showsHand = true
+		shown = append(shown, player)
+	}
+
+	table.history.showdown(shown, mucked)
+
+	return shown, mucked
+}
+
+// TableHands shows the hands of every player left in the hand. used when
+// there's no more betting and the rest of the board is run out.
+func (table *Table) TableHands() {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	for _, player := range table.GetNonFoldedPlayers() {
+		player.showsHand = true
+	}
+}
+
+// errors if handID isn't the hand being played or just played, e.g. for a
+// request sent before the next hand was dealt. see Table.HandID
+//
+// NOTE: caller must hold the table lock
+func (table *Table) checkHandID(handID uint64) error {
+	if table.HandID == 0 {
+		return errors.New("no hand was dealt yet")
+	} else if handID != table.HandID {
+		return fmt.Errorf("hand #%d is over, a new hand was dealt", handID)
+	}
+
+	return nil
+}
+
+// ShowHand shows player's hole cards once the hand with handID is over, e.g.
+// when they won by folds or mucked at showdown. the show is added to the
+// hand's history.
+func (table *Table) ShowHand(player *Player, handID uint64) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if err := table.checkHandID(handID); err != nil {
+		return err
+	} else if !table.handIsOver() {
+		return errors.New("you can only show your hand after the hand is over")
+	} else if !slices.Contains(table.handPlayers, player) {
+		return errors.New("you weren't dealt in this hand")
+	} else if player.Action.Action == playerState.Fold {
+		return errors.New("you folded this hand")
+	} else if player.showsHand {
+		return errors.New("your hand is already shown")
+	}
+
+	player.showsHand = true
+	table.history.show(player)
+
+	return nil
+}
+
+// RabbitHunt returns the community cards that would have come had the hand
+// with handID gone on, burn cards left out. the cards are only looked at, the
+// deck position isn't changed.
+func (table *Table) RabbitHunt(handID uint64) (Cards, error) {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if err := table.checkHandID(handID); err != nil {
+		return nil, err
+	} else if table.isStud() {
+		return nil, errors.New("stud has no community cards to rabbit hunt")
+	} else if !table.handIsOver() {
+		return nil, errors.New("you can only rabbit hunt after the hand is over")
+	}
+
+	left := table.communityLeft()
+	if left == 0 {
+		return nil, errors.New("every community card was dealt this hand")
+	}
+
+	// NOTE: skip the cards that would have been burned
+	next := table.deck.peek(left + table.streetsLeft())
+
+	var cards Cards
+	for _, street := range table.variant.Streets()[table.streetIndex()+1:] {
+		if len(next) <= street.Community {
+			break
+		}
+
+		cards = append(cards, next[1:1+street.Community]...)
+		next = next[1+street.Community:]
+	}
+
+	return cards, nil
+}
+
+// the player's cards as listed in the hand summary, see BestHand()
+func handSummaryCards(player *Player) string {
+	cards := ""
+	for _, card := range player.allCards() {
+		cards += fmt.Sprintf("[%4s]", card.Name)
+	}
+
+	return cards
+}
+
+// hides the cards and hand of a player that mucked in the hand summary.
+// NOTE: a player's cards are on one line per board.
+func muckWinInfo(winInfo string, player *Player) string {
+	cards := handSummaryCards(player)
+
+	lines := strings.Split(winInfo, "\n")
+	for i, line := range lines {
+		if idx := strings.Index(line, cards); idx != -1 {
+			lines[i] = line[:idx] + "mucked"
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,242 @@
+package poker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+func playerNames(players []*Player) []string {
+	names := make([]string, 0, len(players))
+	for _, player := range players {
+		names = append(names, player.Name)
+	}
+
+	return names
+}
+
+func testPlayersByName(table *Table) map[string]*Player {
+	players := make(map[string]*Player)
+	for _, player := range table.activePlayers.ToPlayerArray() {
+		players[player.Name] = player
+	}
+
+	return players
+}
+
+func TestShowdownOrder(t *testing.T) {
+	const (
+		bet   = playerState.Bet
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	// NOTE: an empty step deals the next street
+	preflop := []bettingStep{
+		{player: "p0", action: bet, amount: 20},
+		{player: "p1", action: call},
+		{player: "p2", action: call},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+		{player: "p0", action: check},
+		{},
+		{player: "p1", action: check},
+		{player: "p2", action: check},
+		{player: "p0", action: check},
+		{},
+	}
+
+	tests := []struct {
+		name  string
+		river []bettingStep
+		want  []string
+	}{
+		{
+			name: "last aggressor shows first",
+			river: []bettingStep{
+				{player: "p1", action: check},
+				{player: "p2", action: bet, amount: 20},
+				{player: "p0", action: call},
+				{player: "p1", action: call},
+			},
+			want: []string{"p2", "p0", "p1"},
+		},
+		{
+			name: "checked river starts left of the button",
+			river: []bettingStep{
+				{player: "p1", action: check},
+				{player: "p2", action: check},
+				{player: "p0", action: check},
+			},
+			want: []string{"p1", "p2", "p0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestGame(t, 1000, 1000, 1000)
+
+			runBettingSteps(t, table, append(append([]bettingStep{}, preflop...), tt.river...))
+
+			if got := playerNames(table.ShowdownOrder()); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("showdown order: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowdownMuck(t *testing.T) {
+	const check = playerState.Check
+
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Bet, amount: 20},
+		{player: "p1", action: playerState.Call},
+		{player: "p2", action: playerState.Call},
+	})
+
+	for street := 0; street < 3; street++ {
+		runBettingSteps(t, table, []bettingStep{
+			{},
+			{player: "p1", action: check},
+			{player: "p2", action: check},
+			{player: "p0", action: check},
+		})
+	}
+
+	players := testPlayersByName(table)
+	for name, hole := range map[string]string{"p0": "2c 3d", "p1": "Ac Ad", "p2": "7c 8d"} {
+		players[name].Hole.Cards = mustCards(t, hole)
+		players[name].Hole.FillHoleInfo()
+	}
+	table.Community = mustCards(t, "Kh Qs 9c 5d 4h")
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	// NOTE: p1 shows first and wins, so everyone else can muck
+	shown, mucked := table.Showdown(func(*Player) bool { return true })
+	if got := playerNames(shown); !reflect.DeepEqual(got, []string{"p1"}) {
+		t.Errorf("shown: got %v, want [p1]", got)
+	}
+	if got := playerNames(mucked); !reflect.DeepEqual(got, []string{"p2", "p0"}) {
+		t.Errorf("mucked: got %v, want [p2 p0]", got)
+	}
+
+	if pub := table.PublicPlayerInfo(*players["p0"]); pub.Hole != nil || pub.Hand != nil {
+		t.Errorf("mucked hand is public")
+	}
+	if pub := table.PublicPlayerInfo(*players["p1"]); pub.Hole == nil {
+		t.Errorf("shown hand isn't public")
+	}
+
+	for _, name := range []string{"p0", "p2"} {
+		if cards := handSummaryCards(players[name]); strings.Contains(table.WinInfo, cards) {
+			t.Errorf("%s's mucked cards are in WinInfo:\n%s", name, table.WinInfo)
+		}
+	}
+	if !strings.Contains(table.WinInfo, handSummaryCards(players["p1"])) {
+		t.Errorf("the winner's cards aren't in WinInfo:\n%s", table.WinInfo)
+	}
+}
+
+func TestShowHandAfterFolds(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	players := testPlayersByName(table)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Fold},
+	})
+
+	if err := table.ShowHand(players["p2"], table.HandID); err == nil {
+		t.Fatalf("showed a hand before the hand was over")
+	}
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p1", action: playerState.Fold},
+	})
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	if shown, mucked := table.Showdown(func(*Player) bool { return false }); shown != nil || mucked != nil {
+		t.Fatalf("showdown after a win by folds: shown %v, mucked %v", playerNames(shown), playerNames(mucked))
+	}
+	if pub := table.PublicPlayerInfo(*players["p2"]); pub.Hole != nil {
+		t.Fatalf("winner by folds has to choose to show")
+	}
+
+	if err := table.ShowHand(players["p0"], table.HandID); err == nil {
+		t.Errorf("a folded player showed their hand")
+	}
+	if err := table.ShowHand(players["p2"], table.HandID-1); err == nil {
+		t.Errorf("showed a hand of an older hand")
+	}
+
+	if err := table.ShowHand(players["p2"], table.HandID); err != nil {
+		t.Fatalf("ShowHand: %v", err)
+	}
+	if pub := table.PublicPlayerInfo(*players["p2"]); pub.Hole == nil {
+		t.Errorf("shown hand isn't public")
+	}
+	if shows := table.HandHistory().Shows; len(shows) != 1 || shows[0].Player != "p2" || len(shows[0].Cards) != 2 {
+		t.Errorf("the history has shows %+v", shows)
+	}
+
+	if err := table.ShowHand(players["p2"], table.HandID); err == nil {
+		t.Errorf("showed a hand twice")
+	}
+}
+
+func TestRabbitHunt(t *testing.T) {
+	table := newTestGame(t, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p0", action: playerState.Fold},
+	})
+
+	if _, err := table.RabbitHunt(table.HandID); err == nil {
+		t.Fatalf("rabbit hunted before the hand was over")
+	}
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p1", action: playerState.Fold},
+	})
+
+	if err := table.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	cardsLeft := table.deck.CardsLeft()
+
+	handID := table.HandID
+
+	cards, err := table.RabbitHunt(handID)
+	if err != nil {
+		t.Fatalf("RabbitHunt: %v", err)
+	}
+
+	if table.deck.CardsLeft() != cardsLeft {
+		t.Fatalf("rabbit hunt took cards from the deck")
+	}
+
+	for _, street := range table.variant.Streets() {
+		table.dealStreet(street)
+	}
+	if got, want := table.CommunityToString(), (&Table{Community: cards}).CommunityToString(); got != want {
+		t.Fatalf("rabbit hunt doesn't match the next cards: got %s, want %s", got, want)
+	}
+
+	// a request that comes in after the next hand was dealt
+	nextHand(t, table)
+	if _, err := table.RabbitHunt(handID); err == nil || table.HandID == handID {
+		t.Errorf("rabbit hunted a hand that was over")
+	}
+}
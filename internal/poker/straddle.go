@@ -0,0 +1,108 @@
+package poker
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SetAllowStraddle changes whether players can post an under the gun
+// straddle. only allowed between games.
+func (table *Table) SetAllowStraddle(allow bool) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if table.State != TableStateNotStarted {
+		return errors.New("straddling can't be changed while a game is in progress")
+	}
+
+	table.AllowStraddle = allow
+
+	return nil
+}
+
+// SetStraddle turns player's straddle on or off. a player with the straddle
+// on posts it whenever they're under the gun, starting with the next hand.
+func (table *Table) SetStraddle(player *Player, straddles bool) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if !table.AllowStraddle {
+		return errors.New("straddling isn't allowed at this table")
+	} else if table.isStud() {
+		return errors.New("stud has no blinds to straddle")
+	} else if player.Straddles == straddles {
+		if straddles {
+			return errors.New("your straddle is already on")
+		}
+
+		return errors.New("your straddle is already off")
+	}
+
+	player.Straddles = straddles
+
+	return nil
+}
+
+// a straddle is twice the big blind
+func (table *Table) straddleSize() Chips {
+	return 2 * table.Blinds.BigBlind
+}
+
+// the player under the gun posts a straddle if they turned it on and there
+// are at least three players in the hand. the straddle is a live raise:
+// everyone has to call it and the straddler acts last preflop with the option
+// to check or raise, see ReorderPlayers(). returns true if a straddle was
+// posted.
+//
+// NOTE: call after the cards are dealt
+func (table *Table) postStraddle() bool {
+	table.Straddler = nil
+
+	if !table.AllowStraddle || table.isStud() ||
+		table.BigBlind == nil || table.activePlayers.Len < 3 {
+		return false
+	}
+
+	straddle := table.straddleSize()
+
+	node := table.curPlayers.GetPlayerNode(table.BigBlind.Next().Player)
+	if node == nil || !node.Player.Straddles {
+		return false
+	} else if node.Player.Action.Amount != 0 || node.Player.ChipCount <= straddle {
+		// NOTE: already posted a missed blind or can't cover the straddle
+		log.Debug().Str("player", node.Player.Name).Msg("can't straddle")
+		return false
+	}
+
+	player := node.Player
+
+	player.Action.Amount = straddle
+	player.ChipCount -= straddle
+	table.MainPot.Total += straddle
+
+	table.history.post(HistoryPostStraddle, player, straddle)
+
+	table.Bet, table.MainPot.Bet = straddle, straddle
+	table.lastRaise = straddle
+	table.fullBet = straddle
+	table.betCount++
+	table.fullRaises++
+	table.better = player
+	table.Straddler = node
+	table.State = TableStatePlayerRaised // everyone else has to call, raise or fold
+
+	log.Debug().
+		Str("player", player.Name).
+		Uint64("amount", uint64(straddle)).
+		Msg("posted straddle")
+
+	return true
+}
+
+// reports whether player is the straddler getting their option preflop:
+// everyone just called the straddle, so they can check.
+func (table *Table) isStraddleOption(player *Player) bool {
+	return table.Straddler != nil && table.Straddler.Player == player &&
+		table.CommState == TableStatePreFlop && player.Action.Amount == table.Bet
+}
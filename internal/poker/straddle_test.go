@@ -0,0 +1,179 @@
+package poker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+// deals a second hand with the straddle turned on for the player that will be
+// under the gun. the second hand has p1 on the button.
+func newStraddleTestGame(t *testing.T, stacks ...Chips) *Table {
+	t.Helper()
+
+	table := newTestGame(t, stacks...)
+	table.AllowStraddle = true
+
+	// NOTE: p4 is under the gun next hand, wrapped around the table
+	utg := fmt.Sprintf("p%d", 4%len(stacks))
+	if err := table.SetStraddle(testPlayersByName(table)[utg], true); err != nil {
+		t.Fatalf("SetStraddle: %v", err)
+	}
+
+	nextHand(t, table)
+
+	return table
+}
+
+func TestStraddleActionOrder(t *testing.T) {
+	for numSeats := 3; numSeats <= 7; numSeats++ {
+		t.Run(fmt.Sprintf("%d seats", numSeats), func(t *testing.T) {
+			table := newStraddleTestGame(t, testStacks(numSeats)...)
+
+			straddler := fmt.Sprintf("p%d", 4%numSeats)
+			if got := nodeName(table.Straddler); got != straddler {
+				t.Fatalf("straddler: got %q, want %s", got, straddler)
+			}
+			if table.Bet != 2*table.Blinds.BigBlind {
+				t.Fatalf("bet is %d after the straddle", table.Bet)
+			}
+
+			// everyone calls starting left of the straddler, then the
+			// straddler gets the option to check
+			var steps []bettingStep
+			for i := 1; i < numSeats; i++ {
+				steps = append(steps, bettingStep{
+					player: fmt.Sprintf("p%d", (4+i)%numSeats),
+					action: playerState.Call,
+				})
+			}
+			steps = append(steps, bettingStep{player: straddler, action: playerState.Check})
+
+			runBettingSteps(t, table, steps)
+
+			if table.State != TableStateDoneBetting {
+				t.Fatalf("betting isn't done after the straddler checked: %s", table.TableStateToString())
+			}
+			if want := Chips(numSeats) * 2 * table.Blinds.BigBlind; table.MainPot.Total != want {
+				t.Fatalf("pot: got %d, want %d", table.MainPot.Total, want)
+			}
+		})
+	}
+}
+
+func TestStraddle(t *testing.T) {
+	const (
+		bet   = playerState.Bet
+		call  = playerState.Call
+		check = playerState.Check
+		fold  = playerState.Fold
+	)
+
+	// NOTE: 4 seats: p1 dealer, p2 small blind, p3 big blind, p0 straddles
+	tests := []struct {
+		name  string
+		steps []bettingStep
+	}{
+		{
+			name: "straddle has to be called",
+			steps: []bettingStep{
+				{player: "p1", action: check, err: "must call"},
+				{player: "p1", action: call},
+				{player: "p2", action: fold},
+				{player: "p3", action: check, err: "must call"},
+				{player: "p3", action: call},
+				{player: "p0", action: check},
+			},
+		},
+		{
+			name: "straddler can raise on their option",
+			steps: []bettingStep{
+				{player: "p1", action: call},
+				{player: "p2", action: call},
+				{player: "p3", action: call},
+				{player: "p0", action: bet, amount: 60},
+				{player: "p1", action: call},
+				{player: "p2", action: fold},
+				{player: "p3", action: call},
+			},
+		},
+		{
+			name: "raise takes away the option",
+			steps: []bettingStep{
+				{player: "p1", action: bet, amount: 30, err: "minimum"},
+				{player: "p1", action: bet, amount: 40},
+				{player: "p2", action: fold},
+				{player: "p3", action: fold},
+				{player: "p0", action: check, err: "must call"},
+				{player: "p0", action: call},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newStraddleTestGame(t, testStacks(4)...)
+
+			runBettingSteps(t, table, tt.steps)
+
+			if table.State != TableStateDoneBetting {
+				t.Fatalf("betting isn't done: %s", table.TableStateToString())
+			}
+		})
+	}
+}
+
+func TestNoStraddle(t *testing.T) {
+	tests := []struct {
+		name   string
+		stacks []Chips
+		setup  func(table *Table)
+	}{
+		{
+			name:   "heads up",
+			stacks: []Chips{1000, 1000},
+		},
+		{
+			name:   "can't cover the straddle",
+			stacks: []Chips{20, 1000, 1000, 1000},
+		},
+		{
+			name:   "straddle turned off",
+			stacks: testStacks(5),
+			setup: func(table *Table) {
+				for _, player := range table.activePlayers.ToPlayerArray() {
+					player.Straddles = false
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTestGame(t, tt.stacks...)
+			table.AllowStraddle = true
+
+			for _, player := range table.activePlayers.ToPlayerArray() {
+				player.Straddles = true
+			}
+			if tt.setup != nil {
+				tt.setup(table)
+			}
+
+			nextHand(t, table)
+
+			if table.Straddler != nil {
+				t.Fatalf("%s straddled", table.Straddler.Player.Name)
+			}
+			if table.Bet != table.Blinds.BigBlind {
+				t.Fatalf("bet is %d, want the big blind", table.Bet)
+			}
+		})
+	}
+
+	table := newTestGame(t, testStacks(3)...)
+	if err := table.SetStraddle(table.curPlayer.Player, true); err == nil {
+		t.Fatalf("turned on a straddle at a table that doesn't allow it")
+	}
+}
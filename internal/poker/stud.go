@@ -0,0 +1,249 @@
+package poker
+
+import (
+	math_rand "math/rand/v2"
+	"slices"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+	"github.com/rs/zerolog/log"
+)
+
+var studStreets = []Street{
+	{State: TableStateFourthStreet, UpCards: 1},
+	{State: TableStateFifthStreet, UpCards: 1},
+	{State: TableStateSixthStreet, UpCards: 1},
+	{State: TableStateSeventhStreet, DownCards: 1},
+}
+
+// seven card stud. players get two down cards and one up card, then three
+// more up cards and a last down card, and make their best five card hand
+// out of all seven. the small blind is used as the bring-in and the big
+// blind as the small bet.
+//
+// NOTE: the first betting round (third street) uses the preflop states.
+type SevenCardStud struct{}
+
+func (SevenCardStud) Name() string {
+	return "seven card stud"
+}
+
+func (SevenCardStud) NewDeck(src math_rand.Source) *Deck {
+	return NewDeck(src)
+}
+
+func (SevenCardStud) NumHoleCards() int {
+	return 2
+}
+
+func (SevenCardStud) NumUpCards() int {
+	return 1
+}
+
+func (SevenCardStud) Streets() []Street {
+	return studStreets
+}
+
+// the best five of the player's seven cards. before fifth street the player
+// has less than five cards and only the pairs, trips & quads they make count.
+//
+// NOTE: a community card dealt when the deck runs short plays for everyone
+func (SevenCardStud) RankHand(table *Table, player *Player) {
+	cards := append(player.allCards(), table.Community...)
+	if len(cards) < 5 {
+		player.Hand = partialHand(cards)
+		return
+	}
+
+	assembleHand(player, cards, standardHandRules)
+}
+
+func (SevenCardStud) CompareHands(hand, otherHand *Hand) int {
+	return hand.Compare(otherHand)
+}
+
+// seven card stud hi-lo (stud eight or better). the pot is split between the
+// best high hand and the best eight or better low out of the player's seven
+// cards.
+type SevenCardStudHiLo struct {
+	SevenCardStud
+}
+
+func (SevenCardStudHiLo) Name() string {
+	return "seven card stud hi-lo"
+}
+
+func (SevenCardStudHiLo) RankLowHand(table *Table, player *Player) {
+	player.LowHand = lowHand(append(player.allCards(), table.Community...))
+}
+
+// ranks a hand of less than five cards, e.g. a stud player's up cards.
+// straights and flushes need five cards so only matching cards count. the
+// cards are ordered like assembleHand's: kickers first, the matched cards last.
+func partialHand(cards Cards) *Hand {
+	sorted := append(Cards{}, cards...)
+	cardsSort(&sorted)
+
+	groups := make([]Cards, 0, len(sorted))
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && sorted[j].NumValue == sorted[i].NumValue {
+			j++
+		}
+
+		groups = append(groups, sorted[i:j])
+		i = j
+	}
+
+	// NOTE: stable sort keeps groups of the same size in ascending order
+	slices.SortStableFunc(groups, func(a, b Cards) int {
+		return len(a) - len(b)
+	})
+
+	hand := &Hand{Rank: RankHighCard, Cards: make(Cards, 0, len(sorted))}
+	for _, group := range groups {
+		hand.Cards = append(hand.Cards, group...)
+	}
+
+	if len(groups) == 0 {
+		return hand
+	}
+
+	switch biggest := len(groups[len(groups)-1]); {
+	case biggest == 4:
+		hand.Rank = RankQuads
+	case biggest == 3:
+		hand.Rank = RankTrips
+	case biggest == 2 && len(groups) > 1 && len(groups[len(groups)-2]) == 2:
+		hand.Rank = RankTwoPair
+	case biggest == 2:
+		hand.Rank = RankPair
+	}
+
+	return hand
+}
+
+// reports whether card is lower than otherCard for the bring-in. aces are
+// high, ties are broken by suit: clubs, diamonds, hearts then spades.
+func bringInLess(card, otherCard *Card) bool {
+	if card.NumValue != otherCard.NumValue {
+		return card.NumValue < otherCard.NumValue
+	}
+
+	return card.Suit < otherCard.Suit
+}
+
+// curPlayers nodes in seat order starting left of the dealer. ties between
+// showing hands go to the player closest to the dealer's left.
+func (table *Table) curPlayersFromDealer() []*PlayerNode {
+	nodes := make([]*PlayerNode, 0, table.curPlayers.Len)
+
+	start := table.activePlayers.Head
+	if table.Dealer != nil {
+		start = table.Dealer.Next()
+	}
+
+	for i, n := 0, start; i < table.activePlayers.Len; i++ {
+		if node := table.curPlayers.GetPlayerNode(n.Player); node != nil {
+			nodes = append(nodes, node)
+		}
+		n = n.Next()
+	}
+
+	return nodes
+}
+
+// stud has no blinds. once the first up cards are dealt the player showing
+// the lowest card posts the bring-in and the action starts on their left.
+// the bring-in doesn't count as a bet, it can be completed to a full bet.
+func (table *Table) postBringIn() {
+	var bringIn *Player
+	for _, node := range table.curPlayersFromDealer() {
+		player := node.Player
+		if player.Action.Action == playerState.AllIn || len(player.UpCards) == 0 {
+			continue // went all in posting the ante
+		}
+
+		if bringIn == nil || bringInLess(player.UpCards[0], bringIn.UpCards[0]) {
+			bringIn = player
+		}
+	}
+
+	if bringIn == nil {
+		log.Debug().Msg("no player can post the bring-in")
+		table.Bet = 0
+		return
+	}
+
+	bringIn.Action.Amount = min(table.Blinds.SmallBlind, bringIn.ChipCount)
+	bringIn.ChipCount -= bringIn.Action.Amount
+	if bringIn.ChipCount == 0 {
+		bringIn.Action.Action = playerState.AllIn
+	} else {
+		bringIn.Action.Action = playerState.Bet
+	}
+
+	table.MainPot.Total += bringIn.Action.Amount
+
+	table.history.post(HistoryBringIn, bringIn, bringIn.Action.Amount)
+
+	table.Bet = table.Blinds.SmallBlind
+	table.better = bringIn
+	table.State = TableStatePlayerRaised // everyone else has to call, complete or fold
+
+	log.Debug().
+		Str("player", bringIn.Name).
+		Str("card", bringIn.UpCards[0].Name).
+		Uint64("amount", uint64(bringIn.Action.Amount)).
+		Msg("posted bring-in")
+}
+
+// reports whether the current bet is a stud bring-in that hasn't been
+// completed yet.
+func (table *Table) isBringIn() bool {
+	return table.isStud() && table.CommState == TableStatePreFlop &&
+		table.Bet < table.Blinds.BigBlind
+}
+
+// resets the curPlayers head for stud: the bring-in on third street with the
+// player to their left acting first, the best showing hand after.
+func (table *Table) reorderStudPlayers() {
+	if table.State == TableStateNewRound {
+		return // NOTE: the cards aren't dealt yet. see NextTableAction()
+	}
+
+	if table.CommState == TableStatePreFlop {
+		if table.better != nil {
+			if node := table.curPlayers.GetPlayerNode(table.better); node != nil {
+				table.curPlayers.SetHead(node)
+				table.curPlayer = node.Next()
+
+				return
+			}
+		}
+
+		table.curPlayer = table.curPlayers.Head
+
+		return
+	}
+
+	var (
+		first     *PlayerNode
+		firstHand *Hand
+	)
+	for _, node := range table.curPlayersFromDealer() {
+		hand := partialHand(node.Player.UpCards)
+		if first == nil || hand.Compare(firstHand) > 0 {
+			first, firstHand = node, hand
+		}
+	}
+
+	if first != nil {
+		log.Debug().
+			Str("player", first.Player.Name).
+			Str("showing", firstHand.RankName()).
+			Msg("best showing hand acts first")
+		table.curPlayers.SetHead(first)
+	}
+
+	table.curPlayer = table.curPlayers.Head
+}
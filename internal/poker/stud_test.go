@@ -0,0 +1,353 @@
+package poker
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+)
+
+var studTestBlinds = Blinds{SmallBlind: 5, BigBlind: 10, Ante: 1}
+
+// stackedDeck returns a deck that deals cards in order, followed by the rest
+// of a standard deck. a burn card has to be listed before every street after
+// the first.
+func stackedDeck(t *testing.T, cards string) *Deck {
+	t.Helper()
+
+	stacked := mustCards(t, cards)
+
+	deck := NewDeck(nil)
+	for _, card := range deck.cards {
+		if !slices.ContainsFunc(stacked, func(c *Card) bool {
+			return c.NumValue == card.NumValue && c.Suit == card.Suit
+		}) {
+			stacked = append(stacked, card)
+		}
+	}
+	deck.cards = stacked
+
+	return deck
+}
+
+// newStudGame deals the first hand of seven card stud to a player for each
+// stack. deal lists the cards in the order they're dealt: two down cards and
+// an up card for each player starting with p0 (the dealer), then the cards
+// for the later streets.
+func newStudGame(t *testing.T, deal string, stacks ...Chips) *Table {
+	t.Helper()
+
+	return newVariantTestGame(t, SevenCardStud{}, stackedDeck(t, deal), studTestBlinds, stacks...)
+}
+
+// checks until the betting round is over
+func checkAround(t *testing.T, table *Table) {
+	t.Helper()
+
+	for i := 0; table.State != TableStateDoneBetting; i++ {
+		if i > table.curPlayers.Len {
+			t.Fatalf("betting didn't finish after everyone checked")
+		}
+
+		if err := table.PlayerAction(table.curPlayer.Player, Action{Action: playerState.Check}); err != nil {
+			t.Fatalf("%s: check: %v", table.curPlayer.Player.Name, err)
+		}
+	}
+}
+
+func TestStudBringIn(t *testing.T) {
+	tests := []struct {
+		name        string
+		deal        string
+		wantBringIn string
+		wantFirst   string
+	}{
+		{
+			name:        "lowest up card brings it in",
+			deal:        "7c 8c Kc  7d 8d 5d  7h 8h 9h  7s 8s Qs",
+			wantBringIn: "p1",
+			wantFirst:   "p2",
+		},
+		{
+			name:        "suit breaks ties",
+			deal:        "7c 8c Kc  7d 8d 2d  7h 8h 2c  7s 8s 9h",
+			wantBringIn: "p2",
+			wantFirst:   "p3",
+		},
+		{
+			name:        "aces are high",
+			deal:        "7c 8c Ad  7d 8d Kh  7h 8h Qc  7s 8s 3s",
+			wantBringIn: "p3",
+			wantFirst:   "p0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newStudGame(t, tt.deal, 1000, 1000, 1000, 1000)
+
+			for _, player := range table.curPlayers.ToPlayerArray() {
+				if len(player.Hole.Cards) != 2 || len(player.UpCards) != 1 {
+					t.Fatalf("%s was dealt %d down and %d up cards, want 2 and 1",
+						player.Name, len(player.Hole.Cards), len(player.UpCards))
+				}
+			}
+
+			bringIn := table.curPlayers.Head.Player
+			if bringIn.Name != tt.wantBringIn {
+				t.Fatalf("bring-in is %s, want %s", bringIn.Name, tt.wantBringIn)
+			}
+			if bringIn.Action.Amount != studTestBlinds.SmallBlind || bringIn.ChipCount != 1000-1-5 {
+				t.Errorf("bring-in bet %d with %d chips left, want 5 with 994", bringIn.Action.Amount, bringIn.ChipCount)
+			}
+			if name := table.curPlayer.Player.Name; name != tt.wantFirst {
+				t.Errorf("%s acts first, want %s", name, tt.wantFirst)
+			}
+
+			if table.Bet != 5 || table.MainPot.Total != 4+5 {
+				t.Errorf("bet %d pot %d, want bet 5 pot 9", table.Bet, table.MainPot.Total)
+			}
+		})
+	}
+}
+
+func TestStudBetting(t *testing.T) {
+	const (
+		bet   = playerState.Bet
+		call  = playerState.Call
+		check = playerState.Check
+	)
+
+	// p1 brings it in
+	const deal = "7c 8c Kc  7d 8d 5d  7h 8h 9h  7s 8s Qs"
+
+	tests := []struct {
+		name         string
+		betting      BettingStructure
+		steps        []bettingStep
+		wantMinBet   Chips
+		wantMaxBet   Chips
+		wantCanRaise bool
+	}{
+		{
+			name:         "bring-in can be completed to the big blind",
+			betting:      BettingNoLimit,
+			wantMinBet:   10,
+			wantMaxBet:   1000 - 1,
+			wantCanRaise: true,
+		},
+		{
+			name:    "raise after completing the bring-in",
+			betting: BettingNoLimit,
+			steps: []bettingStep{
+				{player: "p2", action: check, err: "you must call"},
+				{player: "p2", action: bet, amount: 8, err: "at least the big blind"},
+				{player: "p2", action: bet, amount: 10},
+				{player: "p3", action: bet, amount: 15, err: "at least 20 chips"},
+			},
+			wantMinBet:   20,
+			wantMaxBet:   1000 - 1,
+			wantCanRaise: true,
+		},
+		{
+			name:    "bring-in player can raise after a completion",
+			betting: BettingNoLimit,
+			steps: []bettingStep{
+				{player: "p2", action: call},
+				{player: "p3", action: bet, amount: 10},
+				{player: "p0", action: call},
+			},
+			wantMinBet:   20,
+			wantMaxBet:   1000 - 1,
+			wantCanRaise: true,
+		},
+		{
+			name:    "fixed-limit completes to the small bet",
+			betting: BettingFixedLimit,
+			steps: []bettingStep{
+				{player: "p2", action: bet, amount: 15, err: "you can only raise to 10 chips"},
+				{player: "p2", action: bet, amount: 10},
+			},
+			wantMinBet:   20,
+			wantMaxBet:   20,
+			wantCanRaise: true,
+		},
+		{
+			name:    "fixed-limit big bet from fifth street",
+			betting: BettingFixedLimit,
+			steps: []bettingStep{
+				{player: "p2", action: call},
+				{player: "p3", action: call},
+				{player: "p0", action: call},
+				{}, // fourth street: p0 shows K-6
+				{player: "p0", action: bet, amount: 10},
+				{player: "p1", action: call},
+				{player: "p2", action: call},
+				{player: "p3", action: call},
+				{}, // fifth street
+			},
+			wantMinBet:   20,
+			wantMaxBet:   20,
+			wantCanRaise: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newStudGame(t, deal+" 9s 6c 2d 3h 4s", 1000, 1000, 1000, 1000)
+			table.Betting = tt.betting
+
+			runBettingSteps(t, table, tt.steps)
+
+			limits := table.RaiseLimits(table.curPlayer.Player)
+			if limits.MinBet != tt.wantMinBet || limits.MaxBet != tt.wantMaxBet {
+				t.Errorf("bet limits %d - %d, want %d - %d", limits.MinBet, limits.MaxBet, tt.wantMinBet, tt.wantMaxBet)
+			}
+			if limits.CanRaise != tt.wantCanRaise {
+				t.Errorf("CanRaise = %v, want %v", limits.CanRaise, tt.wantCanRaise)
+			}
+		})
+	}
+}
+
+func TestStudBestShowingHandActsFirst(t *testing.T) {
+	tests := []struct {
+		name      string
+		deal      string // third street, a burn card & fourth street
+		wantFirst string
+	}{
+		{
+			name:      "high card",
+			deal:      "Ac Ad 8c  Ah As 5d  2c 2d 6h  2h 2s 7s  9s  Kc 3c 4h 3d",
+			wantFirst: "p0",
+		},
+		{
+			name:      "pair beats high card",
+			deal:      "Ac Ad 8c  Ah As 5d  2c 2d 6h  2h 2s 7s  9s  Kc 3c 4h 7d",
+			wantFirst: "p3",
+		},
+		{
+			name:      "ties go to the player closest to the dealer's left",
+			deal:      "Ac Ad 8c  Ah As 5d  2c 2d 6h  2h 2s 6s  9s  3c 4c Th Td",
+			wantFirst: "p2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newStudGame(t, tt.deal, 1000, 1000, 1000, 1000)
+
+			runBettingSteps(t, table, []bettingStep{
+				{player: "p2", action: playerState.Call},
+				{player: "p3", action: playerState.Call},
+				{player: "p0", action: playerState.Call},
+				{}, // fourth street
+			})
+
+			if name := table.curPlayer.Player.Name; name != tt.wantFirst {
+				t.Errorf("%s acts first, want %s", name, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestStudPublicPlayerInfo(t *testing.T) {
+	table := newStudGame(t, "7c 8c Kc  7d 8d 5d  7h 8h 9h  7s 8s Qs", 1000, 1000, 1000, 1000)
+
+	player := table.players[0]
+
+	pub := table.PublicPlayerInfo(*player)
+	if pub.Hole != nil || pub.Hand != nil {
+		t.Errorf("down cards are public before the showdown")
+	}
+	if !reflect.DeepEqual(handValues(pub.UpCards), []CardVal{CardKing}) {
+		t.Errorf("up cards are %v, want [K]", handValues(pub.UpCards))
+	}
+
+	pubTable := table.PublicInfo()
+	if pubTable.Dealer.Player.Hole != nil || len(pubTable.Dealer.Player.UpCards) != 1 {
+		t.Errorf("PublicInfo: dealer's down cards are public or up cards are hidden")
+	}
+
+	table.TableHands()
+
+	if pub := table.PublicPlayerInfo(*player); pub.Hole == nil || len(pub.Hole.Cards) != 2 {
+		t.Errorf("down cards aren't shown once the hands are tabled")
+	}
+}
+
+func TestStudShowdown(t *testing.T) {
+	// p0 two pair, p1 nothing, p2 a flush, p3 two pair
+	deal := "2c 3d Kc  4c 5d 7s  Ah 2h 9h  Jc Jd Qs " + // third street
+		"As Kd 8s 4h Qd " + // burn, fourth street
+		"Ks 3c 9s Tc 4s " + // burn, fifth street
+		"Ad 5c Td 7h 6c " + // burn, sixth street
+		"Ac 6d 2s 8d 9c" // burn, seventh street
+
+	table := newStudGame(t, deal, 1000, 1000, 1000, 1000)
+
+	runBettingSteps(t, table, []bettingStep{
+		{player: "p2", action: playerState.Call},
+		{player: "p3", action: playerState.Call},
+		{player: "p0", action: playerState.Call},
+	})
+
+	for street := 0; street < 4; street++ {
+		nextStreet(t, table)
+		checkAround(t, table)
+	}
+
+	table.NextCommunityAction()
+	if table.State != TableStateRoundOver {
+		t.Fatalf("round isn't over after seventh street, table state is %s", table.TableStateToString())
+	}
+
+	for _, player := range table.curPlayers.ToPlayerArray() {
+		if len(player.Hole.Cards) != 3 || len(player.UpCards) != 4 {
+			t.Fatalf("%s has %d down and %d up cards, want 3 and 4",
+				player.Name, len(player.Hole.Cards), len(player.UpCards))
+		}
+	}
+	if len(table.Community) != 0 {
+		t.Errorf("stud dealt %d community cards", len(table.Community))
+	}
+
+	table.FinishRound()
+
+	if got := winnerNames(table.Winners); !reflect.DeepEqual(got, []string{"p2"}) {
+		t.Fatalf("winners %v, want [p2]", got)
+	}
+	if table.players[2].Hand.Rank != RankFlush {
+		t.Errorf("p2 has %s, want a flush", table.players[2].Hand.RankName())
+	}
+	if want := Chips(1000 - 1 - 5 + 4*1 + 4*5); table.players[2].ChipCount != want {
+		t.Errorf("p2 has %d chips, want %d", table.players[2].ChipCount, want)
+	}
+}
+
+func TestPartialHand(t *testing.T) {
+	tests := []struct {
+		cards     string
+		otherCard string
+		want      int // sign of the comparison
+	}{
+		{cards: "Ks", otherCard: "Qs", want: 1},
+		{cards: "Ks 2d", otherCard: "Kh 3c", want: -1},
+		{cards: "2s 2d", otherCard: "Ah Kc", want: 1},
+		{cards: "5s 5d Kc", otherCard: "5h 5c Qd", want: 1},
+		{cards: "3s 3d 2c 2h", otherCard: "Ah Ac Kd Qd", want: 1},
+		{cards: "4s 4d 4c Ah", otherCard: "3h 3c Kd Kc", want: 1},
+		{cards: "Ts Jd Qc Kh", otherCard: "Th Jc Qd Ks", want: 0},
+	}
+
+	for _, tt := range tests {
+		hand, otherHand := partialHand(mustCards(t, tt.cards)), partialHand(mustCards(t, tt.otherCard))
+
+		got := hand.Compare(otherHand)
+		if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+			t.Errorf("%s (%s) vs %s (%s) = %d, want sign %d",
+				tt.cards, hand.RankName(), tt.otherCard, otherHand.RankName(), got, tt.want)
+		}
+	}
+}
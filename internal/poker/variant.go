@@ -0,0 +1,169 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	math_rand "math/rand/v2"
+)
+
+// a betting round after the preflop
+type Street struct {
+	State     TableState // the table's CommState during the street
+	Community int        // number of community cards dealt at the start of the street
+
+	// cards dealt to each player at the start of the street (stud)
+	DownCards int
+	UpCards   int
+}
+
+// Variant is a poker game played at a table. a variant decides how many hole
+// cards are dealt, the streets that follow the preflop and how hands are
+// ranked. betting, blinds and pots are handled by the table the same way for
+// every variant.
+type Variant interface {
+	Name() string
+	NewDeck(src math_rand.Source) *Deck
+	NumHoleCards() int
+	Streets() []Street
+
+	// ranks the best hand player can make into player.Hand. player.Hand is
+	// empty when called.
+	RankHand(table *Table, player *Player)
+
+	// returns a positive number if hand beats otherHand, a negative number
+	// if it loses and 0 on a tie.
+	CompareHands(hand, otherHand *Hand) int
+}
+
+// StudVariant is a variant where players get their own up cards instead of
+// sharing community cards. there are no blinds: after the antes the player
+// showing the lowest card posts the bring-in, and the best showing hand acts
+// first on later streets.
+type StudVariant interface {
+	Variant
+
+	NumUpCards() int // up cards dealt with the hole cards
+}
+
+var holdemStreets = []Street{
+	{State: TableStateFlop, Community: 3},
+	{State: TableStateTurn, Community: 1},
+	{State: TableStateRiver, Community: 1},
+}
+
+// texas hold'em
+type Holdem struct{}
+
+func (Holdem) Name() string {
+	return "hold'em"
+}
+
+func (Holdem) NewDeck(src math_rand.Source) *Deck {
+	return NewDeck(src)
+}
+
+func (Holdem) NumHoleCards() int {
+	return 2
+}
+
+func (Holdem) Streets() []Street {
+	return holdemStreets
+}
+
+// the best five of the hole & community cards
+func (Holdem) RankHand(table *Table, player *Player) {
+	if len(table.Community) < 3 {
+		return
+	}
+
+	cards := append(Cards{}, table.Community...)
+	cards = append(cards, player.Hole.Cards...)
+
+	assembleHand(player, cards, standardHandRules)
+}
+
+func (Holdem) CompareHands(hand, otherHand *Hand) int {
+	return hand.Compare(otherHand)
+}
+
+// ParseVariant returns the variant called name. an empty name is hold'em.
+func ParseVariant(name string) (Variant, error) {
+	switch name {
+	case "", "holdem", "hold'em":
+		return Holdem{}, nil
+	case "omaha":
+		return Omaha{}, nil
+	case "omaha8", "omaha hi-lo", "o8":
+		return OmahaHiLo{}, nil
+	case "shortdeck", "short deck", "6+":
+		return ShortDeck{}, nil
+	case "stud", "7stud", "seven card stud":
+		return SevenCardStud{}, nil
+	case "stud8", "stud hi-lo", "seven card stud hi-lo":
+		return SevenCardStudHiLo{}, nil
+	default:
+		return nil, fmt.Errorf("unknown game '%s'", name)
+	}
+}
+
+func (table *Table) Variant() Variant {
+	if table.variant == nil {
+		return Holdem{} // zero value table
+	}
+
+	return table.variant
+}
+
+func (table *Table) isStud() bool {
+	_, ok := table.Variant().(StudVariant)
+
+	return ok
+}
+
+// number of up cards dealt with the hole cards
+func (table *Table) numUpCards() int {
+	if stud, ok := table.Variant().(StudVariant); ok {
+		return stud.NumUpCards()
+	}
+
+	return 0
+}
+
+// SetVariant changes the game played at the table. only allowed between games.
+func (table *Table) SetVariant(variant Variant) error {
+	table.mtx.Lock()
+	defer table.mtx.Unlock()
+
+	if variant == nil {
+		return errors.New("invalid game")
+	} else if table.State != TableStateNotStarted {
+		return errors.New("the game can't be changed while a game is in progress")
+	}
+
+	table.variant = variant
+	table.Game = variant.Name()
+	// NOTE: keep the source and mode of the old deck
+	deck := variant.NewDeck(table.deck.src)
+	deck.SetCommitReveal(table.CommitReveal)
+	deck.Shuffle()
+	table.deck = deck
+
+	return nil
+}
+
+// GameToString returns the betting structure and game, e.g. "pot-limit omaha"
+func (table *Table) GameToString() string {
+	return table.Betting.String() + " " + table.Game
+}
+
+// index of the current street in the variant's street schedule. -1 means
+// the preflop.
+func (table *Table) streetIndex() int {
+	for i, street := range table.variant.Streets() {
+		if street.State == table.CommState {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,738 @@
+// Package replay deals a recorded hand again on a new table and plays it out
+// action by action, checking that the table does exactly what it did the
+// first time. a hand history from a bug report becomes a regression test by
+// saving it as JSON and replaying it, see poker.HandHistory.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bkazemi/gopoker/internal/playerState"
+	"github.com/bkazemi/gopoker/internal/poker"
+)
+
+// Replay deals history on a new table and plays every recorded action. the
+// replayed hand has to match the record event by event: the same forced
+// bets, cards and actions. every player's stack is checked after each step,
+// the pots once each betting round is over and the showdown, the awards and
+// the final stacks once the hand is done. returns the table the hand was
+// replayed on, or the first difference found.
+//
+// the deck is shuffled with the hand's seed when it has one. otherwise it's
+// stacked with the recorded cards, so the hole cards of every player have to
+// be in the history, see HandHistory.VisibleTo().
+//
+// a table's first hand (HandHistory.Number 0) is dealt like one. any other
+// hand is dealt with Table.NewRound() like it was the first time: the button
+// and blinds are placed so that they move on to the seats the history has
+// them in, dead small blind included, and the players that posted a big
+// blind they missed sitting out owe it again.
+func Replay(history *poker.HandHistory) (*poker.Table, error) {
+	r, err := newReplayer(history)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.run(); err != nil {
+		return r.table, err
+	}
+
+	return r.table, nil
+}
+
+type replayer struct {
+	history *poker.HandHistory
+	table   *poker.Table
+	players map[string]*poker.Player
+
+	checked int // number of recorded events the replay has matched
+
+	// what the record says each player has left, and the chips in the pots
+	stacks     map[string]poker.Chips
+	streetBets map[string]poker.Chips
+	pot        poker.Chips
+}
+
+func newReplayer(history *poker.HandHistory) (*replayer, error) {
+	if len(history.Seats) < 2 {
+		return nil, errors.New("the hand needs at least two players")
+	}
+
+	variant, err := poker.ParseVariant(history.Game)
+	if err != nil {
+		return nil, err
+	}
+
+	deck, err := replayDeck(history, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := poker.NewTable(deck, variant, history.NumSeats, poker.TableLockNone, "", make([]bool, history.NumSeats))
+	if err != nil {
+		return nil, err
+	}
+	if err := table.SetBlinds(history.Blinds); err != nil {
+		return nil, err
+	}
+	if err := table.SetBetting(history.Betting); err != nil {
+		return nil, err
+	}
+
+	r := &replayer{
+		history:    history,
+		table:      table,
+		players:    make(map[string]*poker.Player),
+		stacks:     make(map[string]poker.Chips),
+		streetBets: make(map[string]poker.Chips),
+	}
+
+	if err := r.seatPlayers(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// the cards in the order the hand dealt them
+func replayDeck(history *poker.HandHistory, variant poker.Variant) (*poker.Deck, error) {
+	if history.Seed != "" {
+		order, err := poker.ShuffleOrder(variant, history.Seed)
+		if err != nil {
+			return nil, err
+		}
+
+		return poker.NewStackedDeck(order)
+	}
+
+	// NOTE: burn cards aren't recorded. they're left nil here and filled in
+	//       with cards nobody was dealt below
+	var order poker.Cards
+	for _, event := range history.Events {
+		switch event.Kind {
+		case poker.HistoryDeal:
+			if event.Player != "" && len(event.Cards) == 0 && len(event.UpCards) == 0 {
+				return nil, fmt.Errorf("%s's hole cards aren't in the history and there's no seed to shuffle with", event.Player)
+			}
+			order = append(order, event.Cards...)
+			order = append(order, event.UpCards...)
+		case poker.HistoryStreet:
+			order = append(order, nil)
+			order = append(order, event.Cards...)
+		}
+	}
+
+	dealt := make(map[string]bool)
+	for _, card := range order {
+		if card == nil {
+			continue
+		} else if dealt[card.Code()] {
+			return nil, fmt.Errorf("%s was dealt twice", card.Code())
+		}
+		dealt[card.Code()] = true
+	}
+
+	var unused poker.Cards
+	for _, card := range variant.NewDeck(nil).Remaining() {
+		if !dealt[card.Code()] {
+			unused = append(unused, card)
+		}
+	}
+
+	for i, card := range order {
+		if card == nil {
+			if len(unused) == 0 {
+				return nil, errors.New("the hand dealt more cards than the deck has")
+			}
+			order[i], unused = unused[0], unused[1:]
+		}
+	}
+
+	return poker.NewStackedDeck(append(order, unused...))
+}
+
+// seats the players dealt in with the chips they started the hand with. the
+// table deals in the order the players were added, so they're added in the
+// order the history dealt to them.
+func (r *replayer) seatPlayers() error {
+	table, history := r.table, r.history
+
+	straddles := make(map[string]bool)
+	for _, event := range history.Events {
+		if event.Kind == poker.HistoryPostStraddle {
+			straddles[event.Player] = true
+		}
+	}
+	if len(straddles) > 0 {
+		if err := table.SetAllowStraddle(true); err != nil {
+			return err
+		}
+	}
+
+	seats := make(map[string]poker.HistorySeat)
+	for _, seat := range history.Seats {
+		if _, ok := seats[seat.Name]; ok {
+			return fmt.Errorf("%s has more than one seat", seat.Name)
+		}
+		seats[seat.Name] = seat
+	}
+
+	var order []string
+	for _, event := range history.Events {
+		if event.Kind == poker.HistoryDeal && !slices.Contains(order, event.Player) {
+			order = append(order, event.Player)
+		}
+	}
+	for _, seat := range history.Seats {
+		if !slices.Contains(order, seat.Name) {
+			return fmt.Errorf("%s has a seat but wasn't dealt in", seat.Name)
+		}
+	}
+
+	for _, name := range order {
+		seat, ok := seats[name]
+		if !ok {
+			return fmt.Errorf("%s was dealt in without a seat", name)
+		}
+
+		player := table.GetSeat(uint8(seat.Seat))
+		if player == nil {
+			return fmt.Errorf("%s's seat %d doesn't exist or is taken", name, seat.Seat)
+		}
+		player.Name = name
+		player.ChipCount = seat.Chips
+		player.Action.Action = playerState.FirstAction
+
+		table.CurPlayers().AddPlayer(player)
+		table.ActivePlayers().AddPlayer(player)
+
+		if straddles[name] {
+			if err := table.SetStraddle(player, true); err != nil {
+				return err
+			}
+		}
+
+		r.players[name] = player
+		r.stacks[name] = seat.Chips
+	}
+
+	table.SetCurPlayer(table.CurPlayers().Head)
+
+	if history.Number != 0 {
+		return r.setPositions(order)
+	}
+
+	if history.Stud {
+		// NOTE: stud has no button. the bring-in decides who acts first
+		table.Dealer = table.CurPlayers().Head
+		table.SmallBlind = table.Dealer.Next()
+		table.BigBlind = table.SmallBlind.Next()
+
+		return nil
+	}
+
+	for _, pos := range []struct {
+		node **poker.PlayerNode
+		seat uint
+		name string
+	}{
+		{&table.Dealer, history.Button, "button"},
+		{&table.SmallBlind, history.SmallBlind, "small blind"},
+		{&table.BigBlind, history.BigBlind, "big blind"},
+	} {
+		node := r.seatNode(pos.seat)
+		if node == nil {
+			return fmt.Errorf("nobody in the hand sits in the %s's seat %d", pos.name, pos.seat)
+		}
+		*pos.node = node
+	}
+
+	return nil
+}
+
+// places the button and blinds so that Table.NewRound() moves them to where
+// the history has them. order is the order the players were dealt in.
+func (r *replayer) setPositions(order []string) error {
+	table, history := r.table, r.history
+
+	button, smallBlind, bigBlind := history.Button, history.SmallBlind, history.BigBlind
+	if history.Stud {
+		// NOTE: stud has no button, the first player dealt in gets it like
+		//       on a first hand. the seats are in order, see startHandHistory()
+		seats := history.Seats
+		first := slices.IndexFunc(seats, func(seat poker.HistorySeat) bool {
+			return seat.Name == order[0]
+		})
+
+		button = seats[first].Seat
+		smallBlind = seats[(first+1)%len(seats)].Seat
+		bigBlind = seats[(first+2)%len(seats)].Seat
+		if len(seats) == 2 {
+			smallBlind, bigBlind = button, smallBlind
+		}
+	}
+
+	if err := table.SetNextPositions(button, smallBlind, bigBlind); err != nil {
+		return err
+	}
+
+	for _, event := range history.Events {
+		if event.Kind != poker.HistoryPostBigBlind || seatOf(history, event.Player) == history.BigBlind {
+			continue
+		}
+
+		player, ok := r.players[event.Player]
+		if !ok {
+			return fmt.Errorf("%s posted a missed big blind without being dealt in", event.Player)
+		}
+		table.SetMissedBigBlind(player)
+	}
+
+	return nil
+}
+
+func seatOf(history *poker.HandHistory, player string) uint {
+	for _, seat := range history.Seats {
+		if seat.Name == player {
+			return seat.Seat
+		}
+	}
+
+	return 0
+}
+
+func (r *replayer) seatNode(seat uint) *poker.PlayerNode {
+	for _, player := range r.players {
+		if player.TablePos+1 == seat {
+			return r.table.ActivePlayers().GetPlayerNode(player)
+		}
+	}
+
+	return nil
+}
+
+func (r *replayer) run() error {
+	table := r.table
+
+	if r.history.Number != 0 {
+		table.NewRound()
+	}
+	table.NextTableAction()
+	r.forcedAllIns()
+	if err := r.checkDeal(); err != nil {
+		return err
+	}
+	if err := r.check(); err != nil {
+		return err
+	}
+	if err := r.nextStreets(); err != nil {
+		return err
+	}
+
+	for i, event := range r.history.Events {
+		action, ok := playerAction(event, table)
+		if !ok {
+			continue
+		}
+
+		if r.checked < i {
+			return fmt.Errorf("event %d: the replay is waiting for %s", r.checked, eventString(r.history.Events[r.checked]))
+		} else if table.State == poker.TableStateRoundOver {
+			return fmt.Errorf("event %d: the hand is over before %s", i, eventString(event))
+		}
+
+		player := table.CurPlayer()
+		if player == nil || player.Player.Name != event.Player {
+			return fmt.Errorf("event %d: it's %s's turn, not %s's", i, nodeName(player), event.Player)
+		}
+
+		if err := table.PlayerAction(player.Player, action); err != nil {
+			return fmt.Errorf("event %d: %s: %w", i, eventString(event), err)
+		}
+		if err := r.check(); err != nil {
+			return err
+		}
+
+		if err := r.nextStreets(); err != nil {
+			return err
+		}
+	}
+
+	if table.State != poker.TableStateRoundOver {
+		return fmt.Errorf("the replay is still going at the end of the history, table state is %s", table.TableStateToString())
+	}
+
+	return r.checkResult()
+}
+
+// takes the players that went all in posting the blinds or antes out of the
+// betting, like the server does after the deal
+func (r *replayer) forcedAllIns() {
+	table := r.table
+
+	for _, player := range table.CurPlayers().ToPlayerArray() {
+		if player.Action.Action != playerState.AllIn {
+			continue
+		}
+
+		if table.CurPlayer().Player.Name == player.Name {
+			table.SetNextPlayerTurn()
+		} else {
+			table.CurPlayers().RemovePlayer(player)
+		}
+	}
+}
+
+// the action that makes table record event, false if a player didn't act
+func playerAction(event poker.HistoryEvent, table *poker.Table) (poker.Action, bool) {
+	action := poker.Action{}
+	bet := table.Bet
+
+	switch event.Kind {
+	case poker.HistoryFold:
+		action.Action = playerState.Fold
+	case poker.HistoryCheck:
+		action.Action = playerState.Check
+	case poker.HistoryCall:
+		action.Action = playerState.Call
+	case poker.HistoryBet, poker.HistoryRaise:
+		to := event.Amount
+		if event.Kind == poker.HistoryRaise {
+			to = event.To
+		}
+
+		switch {
+		case event.AllIn:
+			// NOTE: all in bets can be short of a full raise, Bet would
+			//       refuse them
+			action.Action = playerState.AllIn
+		case to <= bet:
+			// a call of a big blind that was posted short
+			action.Action = playerState.Call
+		default:
+			action.Action = playerState.Bet
+			action.Amount = to
+
+			// NOTE: the chip leader's bet is cut down to what the others can
+			//       call, which can be short of a full raise. it's replayed
+			//       as the smallest bet that's cut down the same
+			if player, most, ok := chipLeaderCap(table, event.Player); ok && to == most {
+				action.Amount = max(to, table.RaiseLimits(player).MinBet)
+			}
+		}
+	default:
+		return action, false
+	}
+
+	return action, true
+}
+
+// the most the player named name can bet if they're the chip leader: what
+// the player with the next most chips in the hand can match, see
+// Table.PlayerAction(). false if they aren't the only chip leader.
+func chipLeaderCap(table *poker.Table, name string) (*poker.Player, poker.Chips, bool) {
+	var (
+		leader *poker.Player
+		others poker.Chips
+	)
+
+	for _, player := range table.GetNonFoldedPlayers() {
+		stack := player.ChipCount + player.Action.Amount
+		if player.Name == name {
+			leader = player
+		} else {
+			others = max(others, stack)
+		}
+	}
+
+	if leader == nil || leader.ChipCount+leader.Action.Amount <= others {
+		return nil, 0, false
+	}
+
+	return leader, others, true
+}
+
+// deals the streets once a betting round is over, like the server does. when
+// nobody can bet anymore the rest of the board is run out as many times as
+// the history ran it.
+func (r *replayer) nextStreets() error {
+	table := r.table
+
+	for table.State == poker.TableStateDoneBetting {
+		if err := r.checkPot(); err != nil {
+			return err
+		}
+
+		if table.BettingIsImpossible() {
+			table.TableHands()
+			table.StartRunout(max(len(r.history.Boards), 1))
+
+			for {
+				for table.State != poker.TableStateRoundOver {
+					table.NextCommunityAction()
+					if err := r.check(); err != nil {
+						return err
+					}
+				}
+
+				if !table.NextRun() {
+					break
+				}
+			}
+
+			break
+		}
+
+		table.NextCommunityAction()
+		if err := r.check(); err != nil {
+			return err
+		}
+
+		if table.State != poker.TableStateRoundOver {
+			table.Bet = 0
+			table.SetBetter(nil)
+			for _, player := range table.CurPlayers().ToPlayerArray() {
+				player.Action.Clear()
+			}
+			table.ReorderPlayers()
+		}
+	}
+
+	return nil
+}
+
+// makes sure the replay dealt the hand the history has
+func (r *replayer) checkDeal() error {
+	replayed := r.table.HandHistory()
+	if replayed == nil {
+		return errors.New("the replay didn't deal a hand")
+	}
+
+	if replayed.Button != r.history.Button ||
+		replayed.SmallBlind != r.history.SmallBlind ||
+		replayed.BigBlind != r.history.BigBlind {
+		return fmt.Errorf("the button and blinds are in seats %d/%d/%d, want %d/%d/%d",
+			replayed.Button, replayed.SmallBlind, replayed.BigBlind,
+			r.history.Button, r.history.SmallBlind, r.history.BigBlind)
+	}
+
+	if !slices.Equal(replayed.Seats, r.history.Seats) {
+		return fmt.Errorf("the seats are %v, want %v", replayed.Seats, r.history.Seats)
+	}
+
+	return nil
+}
+
+// matches the events the replay recorded since the last check against the
+// history and checks every player's stack
+func (r *replayer) check() error {
+	if err := r.match(); err != nil {
+		return err
+	}
+
+	return r.checkStacks()
+}
+
+func (r *replayer) match() error {
+	events := r.table.HandHistory().Events
+
+	for ; r.checked < len(events); r.checked++ {
+		got := events[r.checked]
+		if r.checked >= len(r.history.Events) {
+			return fmt.Errorf("event %d: the replay has %s after the end of the history", r.checked, eventString(got))
+		}
+
+		want := r.history.Events[r.checked]
+		if !sameEvent(got, want) {
+			return fmt.Errorf("event %d: the replay has %s, want %s", r.checked, eventString(got), eventString(want))
+		}
+
+		r.apply(want)
+	}
+
+	return nil
+}
+
+func (r *replayer) checkStacks() error {
+	for name, player := range r.players {
+		if player.ChipCount != r.stacks[name] {
+			return fmt.Errorf("event %d: %s has %d chips, want %d", r.checked, name, player.ChipCount, r.stacks[name])
+		}
+	}
+
+	return nil
+}
+
+// checks the chips in the pots. only right once a betting round is over, the
+// side pots are totalled then.
+func (r *replayer) checkPot() error {
+	table := r.table
+
+	pot := table.MainPot.Total
+	for _, sidePot := range table.SidePots().GetAllPots() {
+		pot += sidePot.Total
+	}
+
+	if pot != r.pot {
+		return fmt.Errorf("event %d: the pots have %d chips, want %d", r.checked, pot, r.pot)
+	}
+
+	return nil
+}
+
+// moves the chips of a matched event
+func (r *replayer) apply(event poker.HistoryEvent) {
+	var put poker.Chips
+
+	switch event.Kind {
+	case poker.HistoryStreet:
+		clear(r.streetBets)
+
+		return
+	case poker.HistoryRaise:
+		put = event.To - r.streetBets[event.Player]
+	case poker.HistoryUncalledBet:
+		r.stacks[event.Player] += event.Amount
+		r.pot -= event.Amount
+
+		return
+	default:
+		put = event.Amount
+	}
+
+	if event.Kind != poker.HistoryPostAnte {
+		r.streetBets[event.Player] += put
+	}
+	r.stacks[event.Player] -= put
+	r.pot += put
+}
+
+// finishes the hand like the server does and checks the showdown, the pots
+// won and the chips everyone ends up with
+func (r *replayer) checkResult() error {
+	table, history := r.table, r.history
+
+	if err := r.checkPot(); err != nil {
+		return err
+	}
+
+	showdown := len(table.GetNonFoldedPlayers()) > 1
+
+	if err := table.FinishRound(); err != nil {
+		return err
+	}
+	if showdown {
+		table.Showdown(func(player *poker.Player) bool {
+			return slices.ContainsFunc(history.Shows, func(show poker.HistoryShow) bool {
+				return show.Player == player.Name && show.Mucked
+			})
+		})
+	}
+
+	if err := r.match(); err != nil {
+		return err
+	}
+
+	replayed := table.HandHistory()
+	if r.checked != len(history.Events) {
+		return fmt.Errorf("event %d: the replay ended without %s", r.checked, eventString(history.Events[r.checked]))
+	}
+
+	if !slices.EqualFunc(replayed.Boards, history.Boards, sameCards) {
+		return fmt.Errorf("the boards are %v, want %v", replayed.Boards, history.Boards)
+	}
+
+	if !slices.EqualFunc(replayed.Shows, history.Shows, func(got, want poker.HistoryShow) bool {
+		return got.Player == want.Player && got.Mucked == want.Mucked && sameCards(got.Cards, want.Cards)
+	}) {
+		return fmt.Errorf("the showdown is %s, want %s", showsString(replayed.Shows), showsString(history.Shows))
+	}
+
+	if !slices.Equal(replayed.Awards, history.Awards) {
+		return fmt.Errorf("the awards are %v, want %v", replayed.Awards, history.Awards)
+	}
+
+	for _, award := range history.Awards {
+		r.stacks[award.Player] += award.Amount
+	}
+
+	return r.checkStacks()
+}
+
+// cards that were left out of the record, e.g. the hole cards of a player
+// that didn't show, match any cards
+func sameEvent(got, want poker.HistoryEvent) bool {
+	return got.Kind == want.Kind &&
+		got.Street == want.Street &&
+		got.Run == want.Run &&
+		got.Player == want.Player &&
+		got.Amount == want.Amount &&
+		got.To == want.To &&
+		got.AllIn == want.AllIn &&
+		(want.Cards == nil || sameCards(got.Cards, want.Cards)) &&
+		(want.UpCards == nil || sameCards(got.UpCards, want.UpCards)) &&
+		(want.Board == nil || sameCards(got.Board, want.Board))
+}
+
+func sameCards(a, b poker.Cards) bool {
+	return slices.EqualFunc(a, b, func(a, b *poker.Card) bool {
+		return a.Code() == b.Code()
+	})
+}
+
+func cardsString(cards poker.Cards) string {
+	codes := make([]string, 0, len(cards))
+	for _, card := range cards {
+		codes = append(codes, card.Code())
+	}
+
+	return strings.Join(codes, " ")
+}
+
+func eventString(event poker.HistoryEvent) string {
+	var str strings.Builder
+
+	if event.Player != "" {
+		str.WriteString(event.Player + " ")
+	}
+	str.WriteString(event.Kind.String())
+
+	if event.Amount > 0 {
+		fmt.Fprintf(&str, " %d", event.Amount)
+	}
+	if event.To > 0 {
+		fmt.Fprintf(&str, " to %d", event.To)
+	}
+	if event.AllIn {
+		str.WriteString(" all in")
+	}
+	if cards := append(append(poker.Cards{}, event.Cards...), event.UpCards...); len(cards) > 0 {
+		fmt.Fprintf(&str, " [%s]", cardsString(cards))
+	}
+
+	return str.String()
+}
+
+func showsString(shows []poker.HistoryShow) string {
+	strs := make([]string, 0, len(shows))
+	for _, show := range shows {
+		if show.Mucked {
+			strs = append(strs, show.Player+" mucks")
+		} else {
+			strs = append(strs, fmt.Sprintf("%s shows [%s]", show.Player, cardsString(show.Cards)))
+		}
+	}
+
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+func nodeName(node *poker.PlayerNode) string {
+	if node == nil {
+		return "nobody"
+	}
+
+	return node.Player.Name
+}
@@ -0,0 +1,237 @@
+package replay
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bkazemi/gopoker/internal/poker"
+)
+
+func loadHistory(t *testing.T, name string) *poker.HandHistory {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	var history poker.HandHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	return &history
+}
+
+// p0 and p2 are all in preflop for different amounts, p1 and p3 play the
+// side pot out to the river. each pot goes to a different player and p1
+// mucks.
+func TestReplaySidePots(t *testing.T) {
+	table, err := Replay(loadHistory(t, "side_pots.json"))
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	chips := make(map[string]poker.Chips)
+	for _, player := range table.GetNonFoldedPlayers() {
+		chips[player.Name] = player.ChipCount
+	}
+	for name, want := range map[string]poker.Chips{"p0": 800, "p1": 200, "p2": 900, "p3": 800} {
+		if chips[name] != want {
+			t.Errorf("%s has %d chips, want %d", name, chips[name], want)
+		}
+	}
+}
+
+// hands after a table's first one are dealt with NewRound(), the button and
+// blinds moving on to where the history has them
+func TestReplayLaterHands(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		chips map[string]poker.Chips
+	}{
+		{
+			// p2 left after the big blind, p1 has the button
+			name:  "dead small blind",
+			file:  "dead_small_blind.json",
+			chips: map[string]poker.Chips{"p0": 920, "p1": 1155, "p3": 920},
+		},
+		{
+			// p3 sat out when the big blind passed them and posts it back
+			name:  "missed big blind",
+			file:  "missed_big_blind.json",
+			chips: map[string]poker.Chips{"p0": 925, "p1": 915, "p2": 920, "p3": 1240},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, err := Replay(loadHistory(t, test.file))
+			if err != nil {
+				t.Fatalf("Replay: %v", err)
+			}
+
+			for _, player := range table.GetNonFoldedPlayers() {
+				if want := test.chips[player.Name]; player.ChipCount != want {
+					t.Errorf("%s has %d chips, want %d", player.Name, player.ChipCount, want)
+				}
+			}
+		})
+	}
+}
+
+// p1 has the most chips and raises, which is cut down to the 15 chips p0 has
+// even though that's short of a full raise
+func TestReplayChipLeaderBet(t *testing.T) {
+	table, err := Replay(loadHistory(t, "chip_leader_bet.json"))
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	for _, player := range table.GetNonFoldedPlayers() {
+		if want := map[string]poker.Chips{"p0": 30, "p1": 199970}[player.Name]; player.ChipCount != want {
+			t.Errorf("%s has %d chips, want %d", player.Name, player.ChipCount, want)
+		}
+	}
+
+	// a raise that's short of a full raise without being cut down is an error
+	history := loadHistory(t, "chip_leader_bet.json")
+	for i, event := range history.Events {
+		if event.Kind == poker.HistoryRaise {
+			history.Events[i].Amount, history.Events[i].To = 4, 14
+		}
+	}
+	if _, err := Replay(history); err == nil || !strings.Contains(err.Error(), "raise must be to at least") {
+		t.Errorf("Replay: got error %v, want a short raise", err)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(history *poker.HandHistory)
+		err    string
+	}{
+		{
+			name: "award",
+			tamper: func(history *poker.HandHistory) {
+				history.Awards[1].Amount -= 100
+			},
+			err: "the awards are",
+		},
+		{
+			name: "action",
+			tamper: func(history *poker.HandHistory) {
+				// p3 checks p1's flop bet
+				for i, event := range history.Events {
+					if event.Street == poker.TableStateFlop && event.Player == "p3" {
+						history.Events[i].Kind = poker.HistoryCheck
+						history.Events[i].Amount = 0
+					}
+				}
+			},
+			err: "p3 check:",
+		},
+		{
+			name: "stack",
+			tamper: func(history *poker.HandHistory) {
+				history.Seats[0].Chips = 250
+			},
+			err: "the replay has p0 raise 210 to 250 all in",
+		},
+		{
+			name: "card",
+			tamper: func(history *poker.HandHistory) {
+				// the river is one of p1's hole cards
+				var hole poker.Cards
+				for i, event := range history.Events {
+					if event.Kind == poker.HistoryDeal && event.Player == "p1" {
+						hole = event.Cards
+					} else if event.Kind == poker.HistoryStreet && event.Street == poker.TableStateRiver {
+						history.Events[i].Cards = hole[:1]
+					}
+				}
+			},
+			err: "was dealt twice",
+		},
+		{
+			name: "redacted",
+			tamper: func(history *poker.HandHistory) {
+				// p1 didn't show, the others only see their own hole cards
+				history.Shows = history.Shows[1:]
+				*history = *history.VisibleTo("p0")
+			},
+			err: "p1's hole cards aren't in the history",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			history := loadHistory(t, "side_pots.json")
+			test.tamper(history)
+
+			if _, err := Replay(history); err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Replay: got error %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+// the hole cards of a hand with a seed come from the shuffle
+func TestReplaySeed(t *testing.T) {
+	deck := poker.NewDeck(nil)
+	deck.SetCommitReveal(true)
+	deck.Shuffle()
+
+	order, err := poker.ShuffleOrder(poker.Holdem{}, deck.Seed())
+	if err != nil {
+		t.Fatalf("ShuffleOrder: %v", err)
+	}
+
+	preflop := func(event poker.HistoryEvent) poker.HistoryEvent {
+		event.Street = poker.TableStatePreFlop
+
+		return event
+	}
+
+	// heads up, the button posts the small blind and folds
+	history := &poker.HandHistory{
+		Seed:       deck.Seed(),
+		Game:       poker.Holdem{}.Name(),
+		Betting:    poker.BettingNoLimit,
+		Blinds:     poker.DefaultBlinds,
+		NumSeats:   2,
+		Button:     1,
+		SmallBlind: 1,
+		BigBlind:   2,
+		Seats: []poker.HistorySeat{
+			{Seat: 1, Name: "p0", Chips: 1000},
+			{Seat: 2, Name: "p1", Chips: 1000},
+		},
+		Events: []poker.HistoryEvent{
+			preflop(poker.HistoryEvent{Kind: poker.HistoryPostSmallBlind, Player: "p0", Amount: 5}),
+			preflop(poker.HistoryEvent{Kind: poker.HistoryPostBigBlind, Player: "p1", Amount: 10}),
+			preflop(poker.HistoryEvent{Kind: poker.HistoryDeal, Player: "p0"}),
+			preflop(poker.HistoryEvent{Kind: poker.HistoryDeal, Player: "p1"}),
+			preflop(poker.HistoryEvent{Kind: poker.HistoryFold, Player: "p0"}),
+			preflop(poker.HistoryEvent{Kind: poker.HistoryUncalledBet, Player: "p1", Amount: 5}),
+		},
+		Awards: []poker.HistoryAward{{Player: "p1", Amount: 10}},
+	}
+
+	table, err := Replay(history)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	var dealt poker.Cards
+	for _, event := range table.HandHistory().Events {
+		dealt = append(dealt, event.Cards...)
+	}
+	if !sameCards(dealt, order[:4]) {
+		t.Errorf("dealt %s, want %s", cardsString(dealt), cardsString(order[:4]))
+	}
+}
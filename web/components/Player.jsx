@@ -14,7 +14,7 @@ import styles from '@/styles/Player.module.css';
 const literata = Literata({ subsets: ['latin'], weight: '500' });
 
 const YourPlayerActions = React.memo(({
-  isYourPlayer, isSmallBlind, curPlayer, tableState, client, keyPressed,
+  isYourPlayer, isSmallBlind, isStraddler, curPlayer, tableState, client, keyPressed,
   socket,
 }) => {
   const betInputRef = useRef(null);
@@ -118,6 +118,28 @@ const YourPlayerActions = React.memo(({
     );
   }, [client, socket, raiseAmount]);
 
+  const isSittingOut = !!client.Player?.IsSittingOut;
+
+  const raiseLimits = curPlayer?.ID === client.ID ? curPlayer?.RaiseLimits : null;
+
+  const handleSitOut = useCallback(() => {
+    socket.send(
+      (new NetData(client, isSittingOut ? NETDATA.SIT_IN : NETDATA.SIT_OUT)).toMsgPack()
+    );
+  }, [client, socket, isSittingOut]);
+
+  const isStraddling = !!client.Player?.Straddles;
+
+  const handleStraddle = useCallback(() => {
+    socket.send(
+      (new NetData(client, NETDATA.STRADDLE, isStraddling ? 'no' : 'yes')).toMsgPack()
+    );
+  }, [client, socket, isStraddling]);
+
+  // NOTE: a hand can only be shown once the hand is over
+  const isHandOver = tableState === TABLE_STATE.SHOW_HANDS ||
+    tableState === TABLE_STATE.SPLIT_POT || tableState === TABLE_STATE.ROUND_OVER;
+
   // enable/disable action buttons as appropriate
   useEffect(() => {
     const notStartedOrYourTurn =
@@ -126,13 +148,17 @@ const YourPlayerActions = React.memo(({
       (isSmallBlind && tableState === TABLE_STATE.PREFLOP);
     const playerRaised = tableState === TABLE_STATE.PLAYER_RAISED;
     const isAllIn = client.Player?.Action.Action === PLAYERSTATE.ALLIN;
+    // NOTE: the straddler can check when everyone just called the straddle.
+    //       the server turns the check down otherwise
+    const isStraddleOption = isStraddler && playerRaised;
 
-    setIsCheckDisabled(notStartedOrYourTurn || isAllIn || isSmallBlindPreflop || playerRaised);
+    setIsCheckDisabled(notStartedOrYourTurn || isAllIn || isSmallBlindPreflop ||
+      (playerRaised && !isStraddleOption));
     setIsCallDisabled(notStartedOrYourTurn  || isAllIn || (!isSmallBlindPreflop && !playerRaised));
-    setIsRaiseDisabled(notStartedOrYourTurn || isAllIn)
+    setIsRaiseDisabled(notStartedOrYourTurn || isAllIn || (raiseLimits && !raiseLimits.CanRaise))
     setIsFoldDisabled(notStartedOrYourTurn  || isAllIn)
     setIsAllinDisabled(notStartedOrYourTurn || isAllIn);
-  }, [client, curPlayer, tableState, isSmallBlind]);
+  }, [client, curPlayer, tableState, isSmallBlind, isStraddler, raiseLimits]);
 
   // keyboard shortcuts
   useEffect(() => {
@@ -160,6 +186,9 @@ const YourPlayerActions = React.memo(({
           min={0}
           value={raiseInputValue}
           onInput={handleRaiseInput}
+          placeholder={raiseLimits?.CanRaise
+            ? `${raiseLimits.MinBet.toLocaleString()} - ${raiseLimits.MaxBet.toLocaleString()}`
+            : ''}
           /*max={} TODO: add me */
         />
         <span style={{ display: 'flex', lineHeight: 0 }} data-tooltip-id="betTooltip">
@@ -233,6 +262,26 @@ const YourPlayerActions = React.memo(({
         >
           allin
         </button>
+        <button
+          disabled={tableState === TABLE_STATE.NOT_STARTED}
+          style={{ ...btnCursorStyle(tableState === TABLE_STATE.NOT_STARTED), }}
+          onClick={handleSitOut}
+        >
+          {isSittingOut ? 'sit in' : 'sit out'}
+        </button>
+        <button
+          style={{ ...btnCursorStyle(false), }}
+          onClick={handleStraddle}
+        >
+          {isStraddling ? 'no straddle' : 'straddle'}
+        </button>
+        <button
+          disabled={!isHandOver}
+          style={{ ...btnCursorStyle(!isHandOver), }}
+          onClick={() => socket.send((new NetData(client, NETDATA.SHOW_HAND)).toMsgPack())}
+        >
+          show
+        </button>
       </div>
     </div>
   );
@@ -306,6 +355,7 @@ function Player({
   const [isDealer, setIsDealer] = useState(false);
   const [isSmallBlind, setIsSmallBlind] = useState(false);
   const [isBigBlind, setIsBigBlind] = useState(false);
+  const [isStraddler, setIsStraddler] = useState(false);
 
   const [isReconnecting, setIsReconnecting] = useState(client.Player?.isDisconnected);
 
@@ -313,6 +363,7 @@ function Player({
     dealer:     setIsDealer,
     smallBlind: setIsSmallBlind,
     bigBlind:   setIsBigBlind,
+    straddler:  setIsStraddler,
   }), []);
 
   const [style, setStyle] = useState({gridRow, gridColumn: gridCol});
@@ -480,7 +531,7 @@ function Player({
           alt={'<chipCount img>'}
         />
       </div>
-      <YourPlayerActions {...{isYourPlayer, curPlayer, isSmallBlind, tableState, client, keyPressed, socket}} />
+      <YourPlayerActions {...{isYourPlayer, curPlayer, isSmallBlind, isStraddler, tableState, client, keyPressed, socket}} />
       { !isYourPlayer && isReconnecting && <ReconnectOverlay /> }
     </div>
   );
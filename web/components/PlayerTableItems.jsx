@@ -91,6 +91,35 @@ const Cards = React.memo(({ client, isYourPlayer, side, tableState }) => {
 
 Cards.displayName = 'Cards';
 
+// stud up cards. everyone can see these, so they're shown for every player
+const UpCards = React.memo(({ client, tableState }) => {
+  const upCards = client?.Player?.UpCards;
+
+  if (
+    tableState === TABLE_STATE.NOT_STARTED ||
+    client.Player.Action.Action === PLAYERSTATE.FOLD ||
+    !upCards?.length
+  ) {
+    return;
+  }
+
+  return <div className={styles.upCards}>
+    {
+      upCards.map((c, idx) => {
+        return <Image
+          key={idx}
+          src={cardToImagePath(c)}
+          height={45}
+          width={33}
+          alt={`[${c.Name}]`}
+        />;
+      })
+    }
+  </div>;
+});
+
+UpCards.displayName = 'UpCards';
+
 function PlayerTableItems({
   client, isYourPlayer, curHand, side,
   gridRow, gridCol, tableState
@@ -112,6 +141,9 @@ function PlayerTableItems({
       <Cards
         {...{client, isYourPlayer, side, tableState}}
       />
+      <UpCards
+        {...{client, tableState}}
+      />
     </div>
   );
 }
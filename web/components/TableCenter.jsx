@@ -12,11 +12,28 @@ import styles from '@/styles/TableCenter.module.css';
 const literata = Literata({ subsets: ['latin'], weight: '500' });
 const dmMono = DM_Mono({ subsets: ['latin', 'latin-ext'], weight: '500' });
 
-function TableCenter({ isAdmin, tableState, community, mainPot, yourClient, socket }) {
+function TableCenter({ isAdmin, tableState, community, boards, mainPot, yourClient, socket }) {
   const [numCardsLoaded, setNumCardsLoaded] = useState(0);
 
   return (
     <div>
+      {
+        boards?.map((board, boardIdx) => {
+          return <div key={boardIdx} className={styles.board}>
+            {
+              board.map((c, idx) => {
+                return <Image
+                  key={idx}
+                  src={cardToImagePath(c)}
+                  height={60}
+                  width={40}
+                  alt={c.Name}
+                />
+              })
+            }
+          </div>
+        })
+      }
       {
         community?.length &&
         <div
@@ -65,6 +82,21 @@ function TableCenter({ isAdmin, tableState, community, mainPot, yourClient, sock
           <p className={cx(styles.mainPot, dmMono.className)}>mainpot: { mainPot.Total.toLocaleString() }</p>
         </div>
       }
+      {
+        // NOTE: spectators can rabbit hunt too
+        (tableState === TABLE_STATE.ROUND_OVER || tableState === TABLE_STATE.SHOW_HANDS ||
+         tableState === TABLE_STATE.SPLIT_POT) &&
+          <div className={styles.preGame}>
+            <button
+              className={literata.className}
+              onClick={() => {
+                socket.send((new NetData(yourClient, NETDATA.RABBIT_HUNT)).toMsgPack());
+              }}
+            >
+              rabbit hunt
+            </button>
+          </div>
+      }
       {
         (isAdmin && tableState === TABLE_STATE.NOT_STARTED) &&
           <div className={styles.preGame}>
@@ -101,6 +101,7 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
   const [numConnected, setNumConnected] = useState(netData.Table?.NumConnected || 0);
   const [chatMsgs, setChatMsgs] = useState([]);
   const [community, setCommunity] = useState([]);
+  const [boards, setBoards] = useState([]); // NOTE: runs already dealt when running it twice
 
   const [mainPot, setMainPot] = useState(netData.Table?.MainPot || nullPot);
 
@@ -116,6 +117,7 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
   const [dealer, setDealer] = useState(netData.Table?.Dealer || nullPlayer);
   const [smallBlind, setSmallBlind] = useState(netData.Table?.SmallBlind || nullPlayer);
   const [bigBlind, setBigBlind] = useState(netData.Table?.BigBlind || nullPlayer);
+  const [straddler, setStraddler] = useState(netData.Table?.Straddler || nullPlayer);
 
   const [tablePass, setTablePass] = useState(netData.Table?.Password || "");
   const [tableLock, setTableLock] = useState(netData.Table?.Lock || TABLE_LOCK.NONE);
@@ -234,9 +236,11 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
   const updateTable = useCallback((netData) => {
     setMainPot(netData.Table.MainPot);
     setCommunity(netData.Table.Community);
+    setBoards(netData.Table.Boards || []);
     setDealer(netData.Table.Dealer || nullClient);
     setSmallBlind(netData.Table.SmallBlind || nullClient);
     setBigBlind(netData.Table.BigBlind || nullClient);
+    setStraddler(netData.Table.Straddler || nullClient);
     setTablePass(netData.Table.Password);
     setTableLock(netData.Table.Lock);
     setNumSeats(netData.Table.NumSeats);
@@ -440,7 +444,8 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
       setPlayerHead(netData.Client);
       break;
     case NETDATA.PLAYER_TURN:
-      setCurPlayer(netData.Client);
+      // legal bet sizes for the player's turn
+      setCurPlayer({ ...netData.Client, RaiseLimits: netData.RaiseLimits });
       break;
     case NETDATA.UPDATE_PLAYER:
       updatePlayer(netData.Client)
@@ -448,10 +453,33 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
     case NETDATA.UPDATE_TABLE:
       updateTable(netData);
       break;
+    case NETDATA.BLIND_LEVEL:
+      updateTable(netData);
+      setChatMsgs(msgs => [...msgs, `<server-msg> ${netData.Msg}`]);
+      break;
+    case NETDATA.SIT_OUT:
+    case NETDATA.SIT_IN:
+    case NETDATA.STRADDLE:
+      updatePlayer(netData.Client);
+      setChatMsgs(msgs => [...msgs, netData.Msg]);
+      break;
+    case NETDATA.ACTION_TIMER:
+      if (netData.Client.ID === yourClientRef.current?.ID)
+        setChatMsgs(msgs => [...msgs, `<server-msg> ${netData.Msg}`]);
+      break;
     case NETDATA.CUR_HAND:
       updatePlayer(netData.Client);
-      setCurHand(netData.Msg);
+      // NOTE: hand strength comes as a one player equity list
+      if (netData.Equities?.length === 1)
+        setCurHand(`${netData.Msg} (${Math.round(100 * netData.Equities[0].Equity)}% equity)`);
+      else
+        setCurHand(netData.Msg);
       break;
+    case NETDATA.EQUITY: {
+      const equities = netData.Equities.map(e => `${e.Name} ${(100 * e.Equity).toFixed(1)}%`);
+      setChatMsgs(msgs => [...msgs, `<server-msg> equity: ${equities.join(', ')}`]);
+      break;
+    }
     case NETDATA.SHOW_HAND:
       updatePlayer(netData.Client);
       break;
@@ -490,6 +518,18 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
       setCommunity(netData.Table.Community);
       updateTable(netData);
       break;
+    case NETDATA.STUD_STREET:
+      // NOTE: the new up cards come with the player updates
+      updateTable(netData);
+      break;
+    case NETDATA.RABBIT_HUNT:
+      setChatMsgs(msgs => [...msgs, netData.Msg]);
+      break;
+    case NETDATA.RUN_IT_TWICE:
+      socket.send(
+        (new NetData(yourClientRef.current, NETDATA.RUN_IT_TWICE, window.confirm(netData.Msg) ? 'yes' : 'no')).toMsgPack()
+      );
+      break;
     case NETDATA.BAD_REQUEST:
     case NETDATA.SERVER_MSG:
       if (netData.Msg.startsWith('failed to reconnect')) {
@@ -530,7 +570,7 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
   // My only guess is that it is a useSWRSubscription optimization.
   //
   // updatePlayer, updateRoom, etc. don't actually trigger rerenders.
-  }, [applyYourClient, netData._noShallowCompare, yourClientRef, updatePlayer, updateRoom, updateTable]);
+  }, [applyYourClient, netData._noShallowCompare, yourClientRef, updatePlayer, updateRoom, updateTable, socket]);
   /* eslint-enable react-hooks/set-state-in-effect */
 
   /* eslint-disable react-hooks/set-state-in-effect */
@@ -576,7 +616,7 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
       socket?.send((new NetData(yourClientRef.current, NETDATA.PLAYER_LEFT)).toMsgPack());
   }, [isSpectator, yourClientRef, socket]);
 
-  const dealerAndBlinds = {dealer, smallBlind, bigBlind};
+  const dealerAndBlinds = {dealer, smallBlind, bigBlind, straddler};
   const isCompactRoom = innerWidth !== null && innerWidth <= 1920;
 
   const playerListPlayersProps = {
@@ -679,7 +719,7 @@ export default function Tablenew({ socket, connStatus, netData, setShowGame, roo
         >
           {/* DEAD-CENTER OF TABLE */}
           <TableCenter
-            {...{isAdmin, tableState, community, mainPot, yourClient, socket}}
+            {...{isAdmin, tableState, community, boards, mainPot, yourClient, socket}}
           />
         </div>
         <div
@@ -10,6 +10,7 @@ export const PLAYERSTATE = {
   VACANT_SEAT:       1n << 6n,
   PLAYER_TURN:       1n << 7n,
   MIDROUND_ADDITION: 1n << 8n,
+  SITTING_OUT:       1n << 9n,
 }
 
 export const NETDATA = {
@@ -67,6 +68,15 @@ export const NETDATA = {
   BAD_REQUEST:         1n << 42n,
 
   ROOM_SETTINGS:       1n << 43n,
+  BLIND_LEVEL:         1n << 44n,
+  ACTION_TIMER:        1n << 45n,
+  SIT_OUT:             1n << 46n,
+  SIT_IN:              1n << 47n,
+  STUD_STREET:         1n << 48n,
+  RUN_IT_TWICE:        1n << 49n,
+  RABBIT_HUNT:         1n << 50n,
+  STRADDLE:            1n << 51n,
+  EQUITY:              1n << 52n,
 };
 
 const NetDataPlayerStateMap = new Map([
@@ -111,6 +121,8 @@ export const PlayerStateToString = (action) => {
     return 'waiting for first action';
   case PLAYERSTATE.MIDROUND_ADDITION:
     return 'waiting to add to next round';
+  case PLAYERSTATE.SITTING_OUT:
+    return 'sitting out';
 
   default:
     return 'bad player state';
@@ -118,11 +130,12 @@ export const PlayerStateToString = (action) => {
 }
 
 NETDATA.NEEDS_TABLE_BITMASK = (NETDATA.NEWCONN | NETDATA.CLIENT_EXITED | NETDATA.UPDATE_TABLE
-  | NETDATA.DEAL);
+  | NETDATA.DEAL | NETDATA.BLIND_LEVEL);
 
 NETDATA.NEEDS_PLAYER_BITMASK = (NETDATA.YOUR_PLAYER | NETDATA.NEW_PLAYER | NETDATA.CUR_PLAYERS
   | NETDATA.PLAYER_LEFT | NETDATA.PLAYER_ACTION | NETDATA.PLAYER_TURN | NETDATA.UPDATE_PLAYER
-  | NETDATA.CUR_HAND | NETDATA.SHOW_HAND | NETDATA.DEAL);
+  | NETDATA.CUR_HAND | NETDATA.SHOW_HAND | NETDATA.DEAL | NETDATA.ACTION_TIMER
+  | NETDATA.SIT_OUT | NETDATA.SIT_IN | NETDATA.STRADDLE);
 
 NETDATA.NEEDS_ACTION_BITMASK = (NETDATA.ALLIN | NETDATA.BET | NETDATA.CALL | NETDATA.CHECK
  | NETDATA.FOLD | NETDATA.RAISE);
@@ -175,6 +188,11 @@ export const TABLE_STATE = {
   NEW_ROUND:  11,
   GAME_OVER:  12,
   RESET:      13,
+
+  FOURTH_STREET:  14,
+  FIFTH_STREET:   15,
+  SIXTH_STREET:   16,
+  SEVENTH_STREET: 17,
 };
 
 const TABLE_STATE_NAME = [
@@ -188,6 +206,8 @@ const TABLE_STATE_NAME = [
   "SPLIT_POT",
   "ROUND_OVER", "NEW_ROUND", "GAME_OVER",
   "RESET",
+
+  "FOURTH_STREET", "FIFTH_STREET", "SIXTH_STREET", "SEVENTH_STREET",
 ];
 
 TABLE_STATE.toString = (state) => {
@@ -195,7 +215,7 @@ TABLE_STATE.toString = (state) => {
 };
 
 export function NewClient(settings) {
-  const { IsSpectator, Name, Password, SeatPos } = settings;
+  const { IsSpectator, Name, Password, SeatPos, MuckLosingHands } = settings;
 
   return {
     Settings: {
@@ -203,6 +223,7 @@ export function NewClient(settings) {
       Name,
       Password,
       SeatPos,
+      MuckLosingHands: !!MuckLosingHands,
     },
   };
 }